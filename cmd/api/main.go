@@ -4,6 +4,9 @@ import (
 	"context"
 	"time"
 
+	"github.com/RuLap/sportmates-api/internal/app/account"
+	"github.com/RuLap/sportmates-api/internal/app/chat"
+	"github.com/RuLap/sportmates-api/internal/app/event"
 	mail_services "github.com/RuLap/sportmates-api/internal/app/mail/services"
 	"github.com/RuLap/sportmates-api/internal/app/profile"
 	"github.com/RuLap/sportmates-api/internal/app/refdata"
@@ -16,6 +19,7 @@ import (
 	"github.com/RuLap/sportmates-api/internal/pkg/rabbitmq"
 	"github.com/RuLap/sportmates-api/internal/pkg/redis"
 	"github.com/RuLap/sportmates-api/internal/pkg/server"
+	"github.com/RuLap/sportmates-api/internal/pkg/sms"
 	postgres "github.com/RuLap/sportmates-api/internal/pkg/storage"
 	"github.com/RuLap/sportmates-api/internal/pkg/storage/minio"
 	validation "github.com/RuLap/sportmates-api/internal/pkg/validator"
@@ -64,7 +68,7 @@ func main() {
 		return
 	}
 
-	jwtHelper, err := jwthelper.NewJwtHelper(cfg.JWT.Secret)
+	jwtHelper, err := jwthelper.NewFromConfig(&cfg.JWT)
 	if err != nil {
 		logger.Error("failed to create JWT helper", "error", err)
 		return
@@ -72,16 +76,78 @@ func main() {
 
 	minioClient, err := minio.New(&cfg.MinioConfig)
 	if err != nil {
-		logger.Error("failed to init MinIO: %w", err)
+		logger.Error("failed to init MinIO", "error", err)
 	}
 
 	minioService := minio.NewService(minioClient)
 
 	//Modules----------------------------------------------------------------------------------------------------------
 
-	authModule := user.NewModule(logger, storage.Database(), jwtHelper, redisService, mqService)
+	googleAuthenticator, err := user.NewGoogleAuthenticator(context.Background(), user.GoogleOAuthConfig{
+		ClientID:     cfg.GoogleOAuth.ClientID,
+		ClientSecret: cfg.GoogleOAuth.ClientSecret,
+		RedirectURL:  cfg.GoogleOAuth.RedirectURL,
+		IssuerURL:    cfg.GoogleOAuth.IssuerURL,
+	})
+	if err != nil {
+		logger.Warn("google oauth disabled", "error", err)
+	}
+
+	smsSender, err := sms.New(&cfg.SMS, cfg.Env, logger)
+	if err != nil {
+		logger.Warn("phone sign-in disabled", "error", err)
+	}
+
+	passwordPolicy := user.PasswordPolicy{
+		MinLength:      cfg.PasswordPolicy.MinLength,
+		MaxLength:      cfg.PasswordPolicy.MaxLength,
+		RequireUpper:   cfg.PasswordPolicy.RequireUpper,
+		RequireLower:   cfg.PasswordPolicy.RequireLower,
+		RequireDigit:   cfg.PasswordPolicy.RequireDigit,
+		RequireSpecial: cfg.PasswordPolicy.RequireSpecial,
+	}
+
+	authModule := user.NewModule(
+		logger,
+		storage.Database(),
+		jwtHelper,
+		redisService,
+		mqService,
+		googleAuthenticator,
+		smsSender,
+		passwordPolicy,
+		cfg.PublicURL,
+	)
 	refdataModule := refdata.NewModule(logger, storage.Database())
 	profileModule := profile.NewModule(logger, storage.Database(), minioService, refdataModule.Service)
+	chatModule := chat.NewModule(logger, storage.Database(), redisService, cfg.Chat.AllowedOrigins)
+	eventModule := event.NewModule(
+		logger,
+		storage.Database(),
+		mqService,
+		minioService,
+		refdataModule.Service,
+		profileModule.Service,
+		chatModule.Service,
+		cfg.PublicURL,
+	)
+	accountModule := account.NewModule(
+		logger,
+		storage.Database(),
+		redisService,
+		mqService,
+		minioService,
+		authModule.Service,
+		eventModule.Service,
+		cfg.PublicURL,
+	)
+
+	go func() {
+		logger.Info("starting chat hub")
+		if err := chatModule.Hub.Run(context.Background()); err != nil {
+			logger.Error("chat hub stopped", "error", err)
+		}
+	}()
 
 	var mailService *mail_services.MailService
 	if mqService != nil {
@@ -102,40 +168,160 @@ func main() {
 	}
 	logger.Info("Init mail service successfully")
 
+	go func() {
+		if err := accountModule.Worker.Run(context.Background()); err != nil {
+			logger.Error("account jobs worker stopped", "error", err)
+		}
+	}()
+
 	//Router-----------------------------------------------------------------------------------------------------------
 
+	realIP, err := http.RealIP(cfg.HTTPServer.TrustedProxies)
+	if err != nil {
+		logger.Error("invalid trusted proxies", "error", err)
+		return
+	}
+
 	router := chi.NewRouter()
 
 	router.Use(chi_middleware.RequestID)
-	router.Use(chi_middleware.RealIP)
+	router.Use(realIP)
 	router.Use(http.RequestLogger(logger))
 	router.Use(http.Recover(logger))
 	router.Use(chi_middleware.Timeout(60 * time.Second))
 
+	router.Get("/.well-known/jwks.json", jwtHelper.JWKSHandler)
+
 	router.Route("/users", func(r chi.Router) {
 		r.Post("/register", authModule.Handler.Register)
 		r.Post("/login", authModule.Handler.Login)
+		r.Post("/login/2fa", authModule.Handler.LoginTwoFactor)
+		r.Post("/login/magic-link", authModule.Handler.SendMagicLink)
+		r.Post("/login/magic-link/verify", authModule.Handler.VerifyMagicLink)
 		r.Post("/refresh", authModule.Handler.RefreshTokens)
 
 		r.Route("/email", func(r chi.Router) {
 			r.Post("/confirm", authModule.Handler.ConfirmEmail)
-			r.With(middleware.AuthMiddleware(jwtHelper)).
+			r.With(middleware.AuthMiddleware(jwtHelper, redisService)).
 				Post("/send-confirmation", authModule.Handler.SendConfirmationLink)
-			r.With(middleware.AuthMiddleware(jwtHelper)).
+			r.With(middleware.AuthMiddleware(jwtHelper, redisService)).
 				Get("/confirmed", authModule.Handler.CheckEmailConfirmed)
+			r.With(middleware.AuthMiddleware(jwtHelper, redisService)).
+				Post("/change", authModule.Handler.ChangeEmail)
+			r.Post("/revert", authModule.Handler.RevertEmailChange)
+		})
+
+		r.Route("/phone", func(r chi.Router) {
+			r.Post("/send-code", authModule.Handler.SendPhoneCode)
+			r.Post("/verify", authModule.Handler.VerifyPhoneCode)
+		})
+
+		r.Route("/password", func(r chi.Router) {
+			r.Post("/forgot", authModule.Handler.ForgotPassword)
+			r.Post("/reset", authModule.Handler.ResetPassword)
+			r.With(middleware.AuthMiddleware(jwtHelper, redisService)).
+				Post("/change", authModule.Handler.ChangePassword)
+		})
+
+		r.Route("/oauth/google", func(r chi.Router) {
+			r.Get("/start", authModule.Handler.GoogleStart)
+			r.Get("/callback", authModule.Handler.GoogleCallback)
 		})
 
-		r.With(middleware.AuthMiddleware(jwtHelper)).Post("/logout", authModule.Handler.Logout)
+		r.With(middleware.AuthMiddleware(jwtHelper, redisService)).Post("/logout", authModule.Handler.Logout)
+
+		r.Route("/2fa", func(r chi.Router) {
+			r.Use(middleware.AuthMiddleware(jwtHelper, redisService))
+
+			r.Post("/enroll", authModule.Handler.EnrollTwoFactor)
+			r.Post("/verify", authModule.Handler.VerifyTwoFactor)
+			r.Post("/disable", authModule.Handler.DisableTwoFactor)
+		})
+
+		r.Route("/me", func(r chi.Router) {
+			r.Use(middleware.AuthMiddleware(jwtHelper, redisService))
+
+			r.Get("/", accountModule.Handler.GetCurrentUser)
+			r.Delete("/", accountModule.Handler.DeleteAccount)
+			r.Get("/settings", accountModule.Handler.GetSettings)
+			r.Patch("/settings", accountModule.Handler.UpdateSettings)
+			r.Get("/export", accountModule.Handler.GetExport)
+			r.Post("/export", accountModule.Handler.RequestExport)
+		})
+
+		r.Route("/sessions", func(r chi.Router) {
+			r.Use(middleware.AuthMiddleware(jwtHelper, redisService))
+
+			r.Get("/", authModule.Handler.GetSessions)
+			r.Delete("/", authModule.Handler.RevokeSessions)
+			r.Delete("/{id}", authModule.Handler.RevokeSession)
+		})
 	})
 
 	router.Route("/profiles", func(r chi.Router) {
-		r.Use(middleware.AuthMiddleware(jwtHelper))
+		r.Use(middleware.AuthMiddleware(jwtHelper, redisService))
 
 		r.Get("/{id}", profileModule.Handler.GetUserByID)
 		r.Get("/avatar/upload-url", profileModule.Handler.GetAvatarUploadURL)
 		r.Post("/avatar", profileModule.Handler.ConfirmAvatarUpload)
 	})
 
+	router.Route("/events", func(r chi.Router) {
+		r.With(middleware.WebSocketAuthMiddleware(jwtHelper, redisService)).Get("/{id}/messages/ws", chatModule.Handler.Connect)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.AuthMiddleware(jwtHelper, redisService))
+
+			r.Get("/", eventModule.Handler.Search)
+			r.Post("/", eventModule.Handler.Create)
+			r.Get("/recommended", eventModule.Handler.GetRecommended)
+			r.Get("/nearby", eventModule.Handler.GetNearby)
+			r.Get("/{id}", eventModule.Handler.GetByID)
+			r.Patch("/{id}", eventModule.Handler.Update)
+			r.Delete("/{id}", eventModule.Handler.Cancel)
+			r.Post("/{id}/join", eventModule.Handler.Join)
+			r.Delete("/{id}/join", eventModule.Handler.Leave)
+			r.Get("/{id}/calendar.ics", eventModule.Handler.GetCalendar)
+			r.Get("/{id}/photo/upload-url", eventModule.Handler.GetCoverUploadURL)
+			r.Post("/{id}/photo", eventModule.Handler.ConfirmCoverUpload)
+			r.Delete("/{id}/photo", eventModule.Handler.DeleteCover)
+			r.Get("/{id}/photos", eventModule.Handler.GetPhotos)
+			r.Get("/{id}/photos/upload-url", eventModule.Handler.GetGalleryUploadURL)
+			r.Post("/{id}/photos", eventModule.Handler.ConfirmGalleryUpload)
+			r.Delete("/{id}/photos/{photoID}", eventModule.Handler.DeletePhoto)
+			r.Post("/{id}/check-in-code", eventModule.Handler.CreateCheckInCode)
+			r.Post("/{id}/check-in", eventModule.Handler.CheckIn)
+			r.Get("/{id}/attendance", eventModule.Handler.GetAttendance)
+			r.Put("/{id}/attendance", eventModule.Handler.SetAttendance)
+			r.Get("/{id}/messages", chatModule.Handler.GetMessages)
+			r.Post("/{id}/messages", chatModule.Handler.SendMessage)
+		})
+	})
+
+	router.Route("/calendar", func(r chi.Router) {
+		r.Get("/{token}.ics", eventModule.Handler.GetCalendarFeed)
+		r.With(middleware.AuthMiddleware(jwtHelper, redisService)).Get("/feed", eventModule.Handler.GetCalendarFeedURL)
+		r.With(middleware.AuthMiddleware(jwtHelper, redisService)).Post("/feed", eventModule.Handler.RotateCalendarFeedURL)
+	})
+
+	router.Route("/admin", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware(jwtHelper, redisService))
+		r.Use(middleware.RequireRole(user.AdminRole, user.ModeratorRole))
+
+		r.Route("/users/{id}", func(r chi.Router) {
+			r.Get("/", authModule.Handler.GetUserForAdmin)
+			r.Post("/ban", authModule.Handler.BanUser)
+			r.Delete("/ban", authModule.Handler.UnbanUser)
+			r.With(middleware.RequireRole(user.AdminRole)).Put("/roles", authModule.Handler.SetRoles)
+		})
+
+		r.Delete("/events/{id}", eventModule.Handler.ModerateCancel)
+
+		r.Post("/sports", refdataModule.Handler.CreateSport)
+		r.Patch("/sports/{id}", refdataModule.Handler.UpdateSport)
+		r.Post("/cities", refdataModule.Handler.CreateCity)
+	})
+
 	//Server-----------------------------------------------------------------------------------------------------------
 
 	srv := server.New(router, cfg.HTTPServer)
@@ -0,0 +1,84 @@
+package account
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/RuLap/sportmates-api/internal/app/event"
+	"github.com/RuLap/sportmates-api/internal/app/profile"
+	"github.com/google/uuid"
+)
+
+// delete removes the account across all modules. Places in upcoming events
+// of other users are released first through the event service, so that
+// waitlisted participants get promoted as if the user had left. Upcoming
+// events of the user are canceled the same way, notifying their participants.
+func (s *service) delete(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.repo.GetUser(ctx, userID)
+	if stderrors.Is(err, ErrUserNotFound) {
+		s.log.Info("account already deleted", "user_id", userID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	eventIDs, err := s.repo.GetUpcomingJoinedEventIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, eventID := range eventIDs {
+		if err := s.eventService.Leave(ctx, eventID, userID); err != nil {
+			s.log.Warn("failed to leave event before account deletion", "event_id", eventID, "user_id", userID, "error", err)
+		}
+	}
+
+	createdIDs, err := s.repo.GetUpcomingCreatedEventIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, eventID := range createdIDs {
+		if err := s.eventService.Cancel(ctx, eventID, userID, event.ThisScope); err != nil {
+			s.log.Warn("failed to cancel event before account deletion", "event_id", eventID, "user_id", userID, "error", err)
+		}
+	}
+
+	photoKeys, err := s.repo.GetPhotoObjectKeys(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteUser(ctx, userID); err != nil {
+		return err
+	}
+
+	// Sessions were revoked when the deletion was requested; this catches any
+	// sign-in that happened before the job ran.
+	if err := s.userService.RevokeSessions(ctx, userID.String()); err != nil {
+		s.log.Error("failed to revoke sessions of deleted account", "user_id", userID, "error", err)
+	}
+
+	// Objects are removed after the rows, so a failed transaction never leaves
+	// records pointing at missing files. Leftover objects are only logged.
+	if err := s.minio.DeleteFile(ctx, profile.AvatarsBucketName, userID.String()); err != nil {
+		s.log.Error("failed to delete avatar", "user_id", userID, "error", err)
+	}
+
+	for _, key := range photoKeys {
+		if err := s.minio.DeleteFile(ctx, event.PhotosBucketName, key); err != nil {
+			s.log.Error("failed to delete event photo", "user_id", userID, "object", key, "error", err)
+		}
+	}
+
+	s.removeExports(ctx, userID, "")
+
+	s.sendEmail(user.Email, "account_deleted", "Ваш аккаунт удален", map[string]interface{}{
+		"user_email": user.Email,
+	})
+
+	s.log.Info("account deleted", "user_id", userID, "photos", len(photoKeys))
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package account
+
+import "time"
+
+type JobResponse struct {
+	ID          string  `json:"id"`
+	Kind        string  `json:"kind"`
+	Status      string  `json:"status"`
+	CreatedAt   string  `json:"created_at"`
+	FinishedAt  *string `json:"finished_at,omitempty"`
+	DownloadURL string  `json:"download_url,omitempty"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"max=128"`
+}
+
+type CurrentUserResponse struct {
+	ID             string                       `json:"id"`
+	Email          string                       `json:"email"`
+	EmailConfirmed bool                         `json:"email_confirmed"`
+	Phone          *string                      `json:"phone,omitempty"`
+	Provider       string                       `json:"provider"`
+	Roles          []string                     `json:"roles"`
+	HasProfile     bool                         `json:"has_profile"`
+	Notifications  NotificationSettingsResponse `json:"notifications"`
+}
+
+type SettingsResponse struct {
+	Language      string                       `json:"language"`
+	Timezone      string                       `json:"timezone"`
+	Privacy       PrivacySettingsResponse      `json:"privacy"`
+	Notifications NotificationSettingsResponse `json:"notifications"`
+}
+
+type PrivacySettingsResponse struct {
+	ProfileVisibility string `json:"profile_visibility"`
+	ShowBirthDate     bool   `json:"show_birth_date"`
+}
+
+type NotificationSettingsResponse struct {
+	EventUpdates   bool `json:"event_updates"`
+	EventReminders bool `json:"event_reminders"`
+	ChatMessages   bool `json:"chat_messages"`
+}
+
+// UpdateSettingsRequest changes only the fields that are present.
+type UpdateSettingsRequest struct {
+	Language      *string                            `json:"language" validate:"omitempty,oneof=ru en"`
+	Timezone      *string                            `json:"timezone" validate:"omitempty,max=64"`
+	Privacy       *UpdatePrivacySettingsRequest      `json:"privacy"`
+	Notifications *UpdateNotificationSettingsRequest `json:"notifications"`
+}
+
+type UpdatePrivacySettingsRequest struct {
+	ProfileVisibility *string `json:"profile_visibility" validate:"omitempty,oneof=everyone participants nobody"`
+	ShowBirthDate     *bool   `json:"show_birth_date"`
+}
+
+type UpdateNotificationSettingsRequest struct {
+	EventUpdates   *bool `json:"event_updates"`
+	EventReminders *bool `json:"event_reminders"`
+	ChatMessages   *bool `json:"chat_messages"`
+}
+
+// Export is written as data.json into the archive handed out to the user.
+type Export struct {
+	ExportedAt    time.Time             `json:"exported_at"`
+	User          ExportUser            `json:"user"`
+	Profile       *ExportProfile        `json:"profile"`
+	Settings      SettingsResponse      `json:"settings"`
+	Sports        []ExportSport         `json:"sports"`
+	CreatedEvents []ExportEvent         `json:"created_events"`
+	JoinedEvents  []ExportParticipation `json:"joined_events"`
+	Messages      []ExportMessage       `json:"messages"`
+	Photos        []ExportPhoto         `json:"photos"`
+}
+
+type ExportUser struct {
+	ID             string    `json:"id"`
+	Email          string    `json:"email"`
+	EmailConfirmed bool      `json:"email_confirmed"`
+	Phone          *string   `json:"phone"`
+	Provider       string    `json:"provider"`
+	Roles          []string  `json:"roles"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ExportProfile struct {
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	Gender      string    `json:"gender"`
+	BirthDate   string    `json:"birth_date"`
+	CityID      int       `json:"city_id"`
+	Description *string   `json:"description"`
+	Avatar      string    `json:"avatar,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ExportSport struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ExportEvent struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description *string    `json:"description"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     *time.Time `json:"end_date"`
+	CityID      int        `json:"city_id"`
+	Place       string     `json:"place"`
+	SportID     string     `json:"sport_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CanceledAt  *time.Time `json:"canceled_at"`
+}
+
+type ExportParticipation struct {
+	EventID     string     `json:"event_id"`
+	Title       string     `json:"title"`
+	StartDate   time.Time  `json:"start_date"`
+	Status      string     `json:"status"`
+	JoinedAt    time.Time  `json:"joined_at"`
+	Attended    *bool      `json:"attended"`
+	CheckedInAt *time.Time `json:"checked_in_at"`
+}
+
+type ExportMessage struct {
+	ID        string    `json:"id"`
+	EventID   string    `json:"event_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportPhoto struct {
+	ID          string    `json:"id"`
+	EventID     string    `json:"event_id"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
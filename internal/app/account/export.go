@@ -0,0 +1,173 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/app/profile"
+	"github.com/google/uuid"
+)
+
+const (
+	exportDataFile   = "data.json"
+	exportAvatarFile = "avatar"
+)
+
+// export builds a ZIP archive with data.json and the avatar, uploads it and
+// returns its object key. Older archives of the user are removed.
+func (s *service) export(ctx context.Context, userID uuid.UUID, jobID string) (string, error) {
+	data, err := s.collectExport(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	hasAvatar, err := s.minio.FileExists(ctx, profile.AvatarsBucketName, userID.String())
+	if err != nil {
+		return "", err
+	}
+	if hasAvatar && data.Profile != nil {
+		data.Profile.Avatar = exportAvatarFile
+	}
+
+	var archive bytes.Buffer
+	zipWriter := zip.NewWriter(&archive)
+
+	dataFile, err := zipWriter.Create(exportDataFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to add data file: %w", err)
+	}
+
+	encoder := json.NewEncoder(dataFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return "", fmt.Errorf("failed to encode export data: %w", err)
+	}
+
+	if hasAvatar {
+		if err := s.addAvatar(ctx, zipWriter, userID); err != nil {
+			return "", err
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return "", fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	if err := s.minio.EnsureBucket(ctx, exportsBucketName); err != nil {
+		return "", err
+	}
+
+	objectKey := fmt.Sprintf("%s/%s.zip", userID, jobID)
+	if err := s.minio.UploadFile(ctx, exportsBucketName, objectKey, &archive, int64(archive.Len())); err != nil {
+		return "", err
+	}
+
+	s.removeExports(ctx, userID, objectKey)
+
+	s.sendEmail(data.User.Email, "data_export_ready", "Ваши данные готовы к скачиванию", map[string]interface{}{
+		"user_email":   data.User.Email,
+		"download_url": s.publicURL + exportSettingsPath,
+	})
+
+	return objectKey, nil
+}
+
+func (s *service) collectExport(ctx context.Context, userID uuid.UUID) (*Export, error) {
+	user, err := s.repo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	userProfile, err := s.repo.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.repo.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = DefaultSettings()
+	}
+
+	sports, err := s.repo.GetSports(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	createdEvents, err := s.repo.GetCreatedEvents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	participations, err := s.repo.GetParticipations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.repo.GetMessages(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	photos, err := s.repo.GetPhotos(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Export{
+		ExportedAt:    time.Now().UTC(),
+		User:          UserToExport(user),
+		Profile:       ProfileToExport(userProfile),
+		Settings:      *SettingsToResponse(settings),
+		Sports:        SportsToExport(sports),
+		CreatedEvents: EventsToExport(createdEvents),
+		JoinedEvents:  ParticipationsToExport(participations),
+		Messages:      MessagesToExport(messages),
+		Photos:        PhotosToExport(photos),
+	}, nil
+}
+
+func (s *service) addAvatar(ctx context.Context, zipWriter *zip.Writer, userID uuid.UUID) error {
+	avatar, err := s.minio.DownloadFile(ctx, profile.AvatarsBucketName, userID.String())
+	if err != nil {
+		return err
+	}
+	defer avatar.Close()
+
+	avatarFile, err := zipWriter.Create(exportAvatarFile)
+	if err != nil {
+		return fmt.Errorf("failed to add avatar: %w", err)
+	}
+
+	if _, err := io.Copy(avatarFile, avatar); err != nil {
+		return fmt.Errorf("failed to copy avatar: %w", err)
+	}
+
+	return nil
+}
+
+// removeExports deletes the user's archives except the one to keep, which
+// may be empty to remove them all.
+func (s *service) removeExports(ctx context.Context, userID uuid.UUID, keep string) {
+	objects, err := s.minio.ListObjects(ctx, exportsBucketName, userID.String()+"/")
+	if err != nil {
+		s.log.Error("failed to list exports", "user_id", userID, "error", err)
+		return
+	}
+
+	for _, object := range objects {
+		if object.Key == keep {
+			continue
+		}
+		if err := s.minio.DeleteFile(ctx, exportsBucketName, object.Key); err != nil {
+			s.log.Error("failed to delete export", "user_id", userID, "object", object.Key, "error", err)
+		}
+	}
+}
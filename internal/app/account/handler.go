@@ -0,0 +1,185 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/RuLap/sportmates-api/internal/app/user"
+	app_errors "github.com/RuLap/sportmates-api/internal/pkg/errors"
+	validation "github.com/RuLap/sportmates-api/internal/pkg/validator"
+	"github.com/darahayes/go-boom"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	log     *slog.Logger
+	service Service
+}
+
+func NewHandler(log *slog.Logger, service Service) *Handler {
+	return &Handler{log: log, service: service}
+}
+
+func (h *Handler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	job, err := h.service.RequestExport(r.Context(), *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, job, http.StatusAccepted)
+}
+
+func (h *Handler) GetExport(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	job, err := h.service.GetExport(r.Context(), *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, job, http.StatusOK)
+}
+
+// DeleteAccount accepts an empty body for accounts without a password; they
+// have to sign in again shortly before instead.
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	var req DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	sessionID, _ := r.Context().Value("session_id").(string)
+
+	job, err := h.service.RequestDeletion(r.Context(), *userID, sessionID, &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, job, http.StatusAccepted)
+}
+
+func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	user, err := h.service.GetCurrentUser(r.Context(), *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, user, http.StatusOK)
+}
+
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	settings, err := h.service.GetSettings(r.Context(), *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, settings, http.StatusOK)
+}
+
+func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	var req UpdateSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(r.Context(), *userID, &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, settings, http.StatusOK)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrExportNotFound), errors.Is(err, ErrAccountNotFound):
+		boom.NotFound(w, err)
+	case errors.Is(err, ErrInvalidTimezone):
+		boom.BadRequest(w, err)
+	case errors.Is(err, user.ErrInvalidPassword), errors.Is(err, user.ErrReauthRequired):
+		boom.Forbidden(w, err)
+	case errors.Is(err, ErrJobsUnavailable):
+		boom.ServerUnavailable(w, err)
+	default:
+		boom.Internal(w, err)
+	}
+}
+
+func (h *Handler) getUserIDFromContext(ctx context.Context) (*uuid.UUID, error) {
+	userIDStr, ok := ctx.Value("user_id").(string)
+	if !ok {
+		h.log.Error("Incorrect ID in context", "userID", userIDStr)
+		return nil, fmt.Errorf(app_errors.ErrCommon)
+	}
+
+	id, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.log.Error("failed to parse userID from context", "userID", userIDStr, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrCommon)
+	}
+
+	return &id, nil
+}
+
+func (h *Handler) sendJSON(w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,155 @@
+package account
+
+import "time"
+
+func JobToResponse(job *Job, downloadURL string) *JobResponse {
+	result := &JobResponse{
+		ID:          job.ID,
+		Kind:        job.Kind,
+		Status:      string(job.Status),
+		CreatedAt:   job.CreatedAt.Format(time.RFC3339),
+		DownloadURL: downloadURL,
+	}
+
+	if job.FinishedAt != nil {
+		finishedAt := job.FinishedAt.Format(time.RFC3339)
+		result.FinishedAt = &finishedAt
+	}
+
+	return result
+}
+
+func UserToCurrentResponse(user *User, hasProfile bool, settings *Settings) *CurrentUserResponse {
+	return &CurrentUserResponse{
+		ID:             user.ID.String(),
+		Email:          user.Email,
+		EmailConfirmed: user.EmailConfirmed,
+		Phone:          user.Phone,
+		Provider:       user.Provider,
+		Roles:          user.Roles,
+		HasProfile:     hasProfile,
+		Notifications:  NotificationsToResponse(settings),
+	}
+}
+
+func SettingsToResponse(settings *Settings) *SettingsResponse {
+	return &SettingsResponse{
+		Language: settings.Language,
+		Timezone: settings.Timezone,
+		Privacy: PrivacySettingsResponse{
+			ProfileVisibility: settings.ProfileVisibility,
+			ShowBirthDate:     settings.ShowBirthDate,
+		},
+		Notifications: NotificationsToResponse(settings),
+	}
+}
+
+func NotificationsToResponse(settings *Settings) NotificationSettingsResponse {
+	return NotificationSettingsResponse{
+		EventUpdates:   settings.NotifyEventUpdates,
+		EventReminders: settings.NotifyEventReminders,
+		ChatMessages:   settings.NotifyChatMessages,
+	}
+}
+
+func UserToExport(user *User) ExportUser {
+	return ExportUser{
+		ID:             user.ID.String(),
+		Email:          user.Email,
+		EmailConfirmed: user.EmailConfirmed,
+		Phone:          user.Phone,
+		Provider:       user.Provider,
+		Roles:          user.Roles,
+		CreatedAt:      user.CreatedAt,
+	}
+}
+
+func ProfileToExport(profile *Profile) *ExportProfile {
+	if profile == nil {
+		return nil
+	}
+
+	return &ExportProfile{
+		FirstName:   profile.FirstName,
+		LastName:    profile.LastName,
+		Gender:      profile.Gender,
+		BirthDate:   profile.BirthDate.Format(time.DateOnly),
+		CityID:      profile.CityID,
+		Description: profile.Description,
+		CreatedAt:   profile.CreatedAt,
+		UpdatedAt:   profile.UpdatedAt,
+	}
+}
+
+func SportsToExport(sports []*Sport) []ExportSport {
+	result := make([]ExportSport, len(sports))
+	for i, sport := range sports {
+		result[i] = ExportSport{
+			ID:   sport.ID.String(),
+			Name: sport.Name,
+		}
+	}
+	return result
+}
+
+func EventsToExport(events []*Event) []ExportEvent {
+	result := make([]ExportEvent, len(events))
+	for i, event := range events {
+		result[i] = ExportEvent{
+			ID:          event.ID.String(),
+			Title:       event.Title,
+			Description: event.Description,
+			StartDate:   event.StartDate,
+			EndDate:     event.EndDate,
+			CityID:      event.CityID,
+			Place:       event.Place,
+			SportID:     event.SportID.String(),
+			CreatedAt:   event.CreatedAt,
+			CanceledAt:  event.CanceledAt,
+		}
+	}
+	return result
+}
+
+func ParticipationsToExport(participations []*Participation) []ExportParticipation {
+	result := make([]ExportParticipation, len(participations))
+	for i, participation := range participations {
+		result[i] = ExportParticipation{
+			EventID:     participation.EventID.String(),
+			Title:       participation.Title,
+			StartDate:   participation.StartDate,
+			Status:      participation.Status,
+			JoinedAt:    participation.JoinedAt,
+			Attended:    participation.Attended,
+			CheckedInAt: participation.CheckedInAt,
+		}
+	}
+	return result
+}
+
+func MessagesToExport(messages []*Message) []ExportMessage {
+	result := make([]ExportMessage, len(messages))
+	for i, message := range messages {
+		result[i] = ExportMessage{
+			ID:        message.ID.String(),
+			EventID:   message.EventID.String(),
+			Body:      message.Body,
+			CreatedAt: message.CreatedAt,
+		}
+	}
+	return result
+}
+
+func PhotosToExport(photos []*Photo) []ExportPhoto {
+	result := make([]ExportPhoto, len(photos))
+	for i, photo := range photos {
+		result[i] = ExportPhoto{
+			ID:          photo.ID.String(),
+			EventID:     photo.EventID.String(),
+			ContentType: photo.ContentType,
+			Size:        photo.Size,
+			CreatedAt:   photo.CreatedAt,
+		}
+	}
+	return result
+}
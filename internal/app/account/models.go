@@ -0,0 +1,131 @@
+package account
+
+import (
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/app/profile"
+	"github.com/google/uuid"
+)
+
+type JobStatus string
+
+const (
+	PendingStatus JobStatus = "pending"
+	RunningStatus JobStatus = "running"
+	DoneStatus    JobStatus = "done"
+	FailedStatus  JobStatus = "failed"
+)
+
+type Job struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	Status     JobStatus  `json:"status"`
+	ObjectKey  string     `json:"object_key,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// IsActive reports whether the job is still queued or running. Jobs that got
+// stuck, e.g. because the worker crashed, stop counting after a while so the
+// user can request a new one.
+func (j *Job) IsActive(now time.Time) bool {
+	if j.Status != PendingStatus && j.Status != RunningStatus {
+		return false
+	}
+	return now.Sub(j.CreatedAt) < staleJobAge
+}
+
+const (
+	EveryoneVisibility     = profile.EveryoneVisibility
+	ParticipantsVisibility = profile.ParticipantsVisibility
+	NobodyVisibility       = profile.NobodyVisibility
+)
+
+// Settings are stored once the user changes them for the first time; until
+// then DefaultSettings apply.
+type Settings struct {
+	Language             string    `db:"language"`
+	Timezone             string    `db:"timezone"`
+	ProfileVisibility    string    `db:"profile_visibility"`
+	ShowBirthDate        bool      `db:"show_birth_date"`
+	NotifyEventUpdates   bool      `db:"notify_event_updates"`
+	NotifyEventReminders bool      `db:"notify_event_reminders"`
+	NotifyChatMessages   bool      `db:"notify_chat_messages"`
+	UpdatedAt            time.Time `db:"updated_at"`
+}
+
+func DefaultSettings() *Settings {
+	return &Settings{
+		Language:             "ru",
+		Timezone:             "Europe/Moscow",
+		ProfileVisibility:    EveryoneVisibility,
+		ShowBirthDate:        true,
+		NotifyEventUpdates:   true,
+		NotifyEventReminders: true,
+		NotifyChatMessages:   true,
+	}
+}
+
+type User struct {
+	ID             uuid.UUID `db:"id"`
+	Email          string    `db:"email"`
+	EmailConfirmed bool      `db:"email_confirmed"`
+	Phone          *string   `db:"phone"`
+	Provider       string    `db:"provider"`
+	Roles          []string  `db:"roles"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+type Profile struct {
+	FirstName   string    `db:"first_name"`
+	LastName    string    `db:"last_name"`
+	Gender      string    `db:"gender"`
+	BirthDate   time.Time `db:"birth_date"`
+	CityID      int       `db:"city_id"`
+	Description *string   `db:"description"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type Sport struct {
+	ID   uuid.UUID `db:"id"`
+	Name string    `db:"name"`
+}
+
+type Event struct {
+	ID          uuid.UUID  `db:"id"`
+	Title       string     `db:"title"`
+	Description *string    `db:"description"`
+	StartDate   time.Time  `db:"start_date"`
+	EndDate     *time.Time `db:"end_date"`
+	CityID      int        `db:"city_id"`
+	Place       string     `db:"place"`
+	SportID     uuid.UUID  `db:"sport_id"`
+	CreatedAt   time.Time  `db:"created_at"`
+	CanceledAt  *time.Time `db:"canceled_at"`
+}
+
+type Participation struct {
+	EventID     uuid.UUID  `db:"event_id"`
+	Title       string     `db:"title"`
+	StartDate   time.Time  `db:"start_date"`
+	Status      string     `db:"status"`
+	JoinedAt    time.Time  `db:"joined_at"`
+	Attended    *bool      `db:"attended"`
+	CheckedInAt *time.Time `db:"checked_in_at"`
+}
+
+type Message struct {
+	ID        uuid.UUID `db:"id"`
+	EventID   uuid.UUID `db:"event_id"`
+	Body      string    `db:"body"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type Photo struct {
+	ID          uuid.UUID `db:"id"`
+	EventID     uuid.UUID `db:"event_id"`
+	ContentType string    `db:"content_type"`
+	Size        int64     `db:"size"`
+	CreatedAt   time.Time `db:"created_at"`
+}
@@ -0,0 +1,45 @@
+package account
+
+import (
+	"log/slog"
+
+	"github.com/RuLap/sportmates-api/internal/app/event"
+	"github.com/RuLap/sportmates-api/internal/app/user"
+	"github.com/RuLap/sportmates-api/internal/pkg/rabbitmq"
+	"github.com/RuLap/sportmates-api/internal/pkg/redis"
+	"github.com/RuLap/sportmates-api/internal/pkg/storage/minio"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Module struct {
+	repo    Repository
+	Worker  *Worker
+	Service Service
+	Handler Handler
+}
+
+func NewModule(
+	log *slog.Logger,
+	pool *pgxpool.Pool,
+	redis *redis.Service,
+	rabbitmq *rabbitmq.Service,
+	minio *minio.Service,
+	userService user.Service,
+	eventService event.Service,
+	publicURL string,
+) *Module {
+	repo := NewRepository(pool)
+
+	service := NewService(log, redis, rabbitmq, minio, repo, userService, eventService, publicURL)
+
+	worker := NewWorker(log, rabbitmq, service)
+
+	handler := NewHandler(log, service)
+
+	return &Module{
+		repo:    repo,
+		Worker:  worker,
+		Service: service,
+		Handler: *handler,
+	}
+}
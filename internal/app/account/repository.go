@@ -0,0 +1,455 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrUserNotFound = errors.New("пользователь не найден")
+)
+
+// Repository reads and removes a user's data across the tables of all
+// modules, so that export and deletion see a consistent picture.
+type Repository interface {
+	GetUser(ctx context.Context, userID uuid.UUID) (*User, error)
+	GetProfile(ctx context.Context, userID uuid.UUID) (*Profile, error)
+	HasProfile(ctx context.Context, userID uuid.UUID) (bool, error)
+	GetSettings(ctx context.Context, userID uuid.UUID) (*Settings, error)
+	SaveSettings(ctx context.Context, userID uuid.UUID, settings *Settings) error
+	GetSports(ctx context.Context, userID uuid.UUID) ([]*Sport, error)
+	GetCreatedEvents(ctx context.Context, userID uuid.UUID) ([]*Event, error)
+	GetParticipations(ctx context.Context, userID uuid.UUID) ([]*Participation, error)
+	GetMessages(ctx context.Context, userID uuid.UUID) ([]*Message, error)
+	GetPhotos(ctx context.Context, userID uuid.UUID) ([]*Photo, error)
+
+	GetUpcomingJoinedEventIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	GetUpcomingCreatedEventIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	GetPhotoObjectKeys(ctx context.Context, userID uuid.UUID) ([]string, error)
+	DeleteUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) GetUser(ctx context.Context, userID uuid.UUID) (*User, error) {
+	query := `
+		SELECT u.id, COALESCE(u.email, ''), u.email_confirmed, u.phone, u.provider, u.created_at,
+			COALESCE(array_agg(ur.role ORDER BY ur.role) FILTER (WHERE ur.role IS NOT NULL), '{}')
+		FROM users u
+		LEFT JOIN user_roles ur ON ur.user_id = u.id
+		WHERE u.id = $1
+		GROUP BY u.id
+	`
+
+	var user User
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&user.ID,
+		&user.Email,
+		&user.EmailConfirmed,
+		&user.Phone,
+		&user.Provider,
+		&user.CreatedAt,
+		&user.Roles,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("не удалось получить пользователя: %w", err)
+	}
+
+	return &user, nil
+}
+
+// GetProfile returns nil if the user has not filled in a profile yet.
+func (r *repository) GetProfile(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	query := `
+		SELECT first_name, last_name, gender, birth_date, city_id, description, created_at, updated_at
+		FROM profiles
+		WHERE id = $1
+	`
+
+	var profile Profile
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&profile.FirstName,
+		&profile.LastName,
+		&profile.Gender,
+		&profile.BirthDate,
+		&profile.CityID,
+		&profile.Description,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("не удалось получить профиль: %w", err)
+	}
+
+	return &profile, nil
+}
+
+func (r *repository) HasProfile(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM profiles WHERE id = $1)`
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("не удалось проверить наличие профиля: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetSettings returns nil if the user has never changed the settings.
+func (r *repository) GetSettings(ctx context.Context, userID uuid.UUID) (*Settings, error) {
+	query := `
+		SELECT language, timezone, profile_visibility, show_birth_date,
+			notify_event_updates, notify_event_reminders, notify_chat_messages, updated_at
+		FROM user_settings
+		WHERE user_id = $1
+	`
+
+	var settings Settings
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&settings.Language,
+		&settings.Timezone,
+		&settings.ProfileVisibility,
+		&settings.ShowBirthDate,
+		&settings.NotifyEventUpdates,
+		&settings.NotifyEventReminders,
+		&settings.NotifyChatMessages,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("не удалось получить настройки: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (r *repository) SaveSettings(ctx context.Context, userID uuid.UUID, settings *Settings) error {
+	query := `
+		INSERT INTO user_settings (
+			user_id, language, timezone, profile_visibility, show_birth_date,
+			notify_event_updates, notify_event_reminders, notify_chat_messages
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			language = EXCLUDED.language,
+			timezone = EXCLUDED.timezone,
+			profile_visibility = EXCLUDED.profile_visibility,
+			show_birth_date = EXCLUDED.show_birth_date,
+			notify_event_updates = EXCLUDED.notify_event_updates,
+			notify_event_reminders = EXCLUDED.notify_event_reminders,
+			notify_chat_messages = EXCLUDED.notify_chat_messages,
+			updated_at = now()
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		userID,
+		settings.Language,
+		settings.Timezone,
+		settings.ProfileVisibility,
+		settings.ShowBirthDate,
+		settings.NotifyEventUpdates,
+		settings.NotifyEventReminders,
+		settings.NotifyChatMessages,
+	)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить настройки: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) GetSports(ctx context.Context, userID uuid.UUID) ([]*Sport, error) {
+	query := `
+		SELECT s.id, s.name
+		FROM user_sports us
+		JOIN sports s ON s.id = us.sport_id
+		WHERE us.user_id = $1
+		ORDER BY s.name
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить виды спорта пользователя: %w", err)
+	}
+	defer rows.Close()
+
+	sports := make([]*Sport, 0)
+	for rows.Next() {
+		var sport Sport
+		if err := rows.Scan(&sport.ID, &sport.Name); err != nil {
+			return nil, fmt.Errorf("не удалось получить виды спорта пользователя: %w", err)
+		}
+		sports = append(sports, &sport)
+	}
+
+	return sports, rows.Err()
+}
+
+func (r *repository) GetCreatedEvents(ctx context.Context, userID uuid.UUID) ([]*Event, error) {
+	query := `
+		SELECT id, title, description, start_date, end_date, city_id, place, sport_id, created_at, canceled_at
+		FROM events
+		WHERE creator_id = $1
+		ORDER BY start_date
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить созданные события: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*Event, 0)
+	for rows.Next() {
+		var event Event
+		err := rows.Scan(
+			&event.ID,
+			&event.Title,
+			&event.Description,
+			&event.StartDate,
+			&event.EndDate,
+			&event.CityID,
+			&event.Place,
+			&event.SportID,
+			&event.CreatedAt,
+			&event.CanceledAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось получить созданные события: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *repository) GetParticipations(ctx context.Context, userID uuid.UUID) ([]*Participation, error) {
+	query := `
+		SELECT ep.event_id, e.title, e.start_date, ep.status, ep.joined_at, ep.attended, ep.checked_in_at
+		FROM event_participants ep
+		JOIN events e ON e.id = ep.event_id
+		WHERE ep.user_id = $1
+		ORDER BY e.start_date
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить участия в событиях: %w", err)
+	}
+	defer rows.Close()
+
+	participations := make([]*Participation, 0)
+	for rows.Next() {
+		var participation Participation
+		err := rows.Scan(
+			&participation.EventID,
+			&participation.Title,
+			&participation.StartDate,
+			&participation.Status,
+			&participation.JoinedAt,
+			&participation.Attended,
+			&participation.CheckedInAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось получить участия в событиях: %w", err)
+		}
+		participations = append(participations, &participation)
+	}
+
+	return participations, rows.Err()
+}
+
+func (r *repository) GetMessages(ctx context.Context, userID uuid.UUID) ([]*Message, error) {
+	query := `
+		SELECT id, event_id, body, created_at
+		FROM event_messages
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить сообщения: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]*Message, 0)
+	for rows.Next() {
+		var message Message
+		if err := rows.Scan(&message.ID, &message.EventID, &message.Body, &message.CreatedAt); err != nil {
+			return nil, fmt.Errorf("не удалось получить сообщения: %w", err)
+		}
+		messages = append(messages, &message)
+	}
+
+	return messages, rows.Err()
+}
+
+func (r *repository) GetPhotos(ctx context.Context, userID uuid.UUID) ([]*Photo, error) {
+	query := `
+		SELECT id, event_id, content_type, size, created_at
+		FROM event_photos
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить фотографии: %w", err)
+	}
+	defer rows.Close()
+
+	photos := make([]*Photo, 0)
+	for rows.Next() {
+		var photo Photo
+		if err := rows.Scan(&photo.ID, &photo.EventID, &photo.ContentType, &photo.Size, &photo.CreatedAt); err != nil {
+			return nil, fmt.Errorf("не удалось получить фотографии: %w", err)
+		}
+		photos = append(photos, &photo)
+	}
+
+	return photos, rows.Err()
+}
+
+// GetUpcomingJoinedEventIDs returns events of other users the user is still
+// going to, so their places can be released before the account is removed.
+func (r *repository) GetUpcomingJoinedEventIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT e.id
+		FROM event_participants ep
+		JOIN events e ON e.id = ep.event_id
+		WHERE ep.user_id = $1
+			AND e.creator_id <> $1
+			AND e.canceled_at IS NULL
+			AND e.start_date > now()
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить предстоящие события: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("не удалось получить предстоящие события: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetUpcomingCreatedEventIDs returns upcoming events organised by the user,
+// so they can be canceled before the account is removed.
+func (r *repository) GetUpcomingCreatedEventIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT id
+		FROM events
+		WHERE creator_id = $1
+			AND canceled_at IS NULL
+			AND start_date > now()
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить созданные события: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("не удалось получить созданные события: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetPhotoObjectKeys returns the storage keys of the event photos uploaded by
+// the user. Covers and photos of other users stay with the events.
+func (r *repository) GetPhotoObjectKeys(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT object_key
+		FROM event_photos
+		WHERE user_id = $1
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить ключи фотографий: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("не удалось получить ключи фотографий: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// DeleteUser removes the user's own rows. Events organised by the user are
+// kept, with the participants, messages, photos and attendance of other
+// users; upcoming ones are canceled beforehand. Their series are removed,
+// which detaches the events. Deleting a user that no longer exists is not an
+// error, so a redelivered job completes.
+func (r *repository) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	queries := []string{
+		`DELETE FROM event_photos WHERE user_id = $1`,
+		`DELETE FROM event_messages WHERE user_id = $1`,
+		`DELETE FROM event_participants WHERE user_id = $1`,
+		`DELETE FROM event_series WHERE creator_id = $1`,
+		`DELETE FROM calendar_tokens WHERE user_id = $1`,
+		`DELETE FROM user_sports WHERE user_id = $1`,
+		`DELETE FROM user_settings WHERE user_id = $1`,
+		`DELETE FROM profiles WHERE id = $1`,
+		`DELETE FROM users WHERE id = $1`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.Exec(ctx, query, userID); err != nil {
+			return fmt.Errorf("не удалось удалить данные пользователя: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("не удалось удалить данные пользователя: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package account
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/app/event"
+	"github.com/RuLap/sportmates-api/internal/app/user"
+	"github.com/RuLap/sportmates-api/internal/pkg/errors"
+	"github.com/RuLap/sportmates-api/internal/pkg/events"
+	"github.com/RuLap/sportmates-api/internal/pkg/rabbitmq"
+	"github.com/RuLap/sportmates-api/internal/pkg/redis"
+	"github.com/RuLap/sportmates-api/internal/pkg/storage/minio"
+	"github.com/google/uuid"
+)
+
+const (
+	exportsBucketName  = "sportmates-exports"
+	jobTTL             = 7 * 24 * time.Hour
+	staleJobAge        = time.Hour
+	exportSettingsPath = "/settings/privacy"
+)
+
+var (
+	ErrExportNotFound  = stderrors.New("выгрузка данных не запрашивалась")
+	ErrJobsUnavailable = stderrors.New("сервис временно недоступен, попробуйте позже")
+)
+
+type Service interface {
+	RequestExport(ctx context.Context, userID uuid.UUID) (*JobResponse, error)
+	GetExport(ctx context.Context, userID uuid.UUID) (*JobResponse, error)
+	RequestDeletion(ctx context.Context, userID uuid.UUID, sessionID string, req *DeleteAccountRequest) (*JobResponse, error)
+
+	GetCurrentUser(ctx context.Context, userID uuid.UUID) (*CurrentUserResponse, error)
+	GetSettings(ctx context.Context, userID uuid.UUID) (*SettingsResponse, error)
+	UpdateSettings(ctx context.Context, userID uuid.UUID, req *UpdateSettingsRequest) (*SettingsResponse, error)
+
+	HandleJob(ctx context.Context, message events.AccountJobEvent) error
+}
+
+type service struct {
+	log          *slog.Logger
+	redis        *redis.Service
+	rabbitmq     *rabbitmq.Service
+	minio        *minio.Service
+	repo         Repository
+	userService  user.Service
+	eventService event.Service
+	publicURL    string
+}
+
+func NewService(
+	log *slog.Logger,
+	redis *redis.Service,
+	rabbitmq *rabbitmq.Service,
+	minio *minio.Service,
+	repo Repository,
+	userService user.Service,
+	eventService event.Service,
+	publicURL string,
+) Service {
+	return &service{
+		log:          log,
+		redis:        redis,
+		rabbitmq:     rabbitmq,
+		minio:        minio,
+		repo:         repo,
+		userService:  userService,
+		eventService: eventService,
+		publicURL:    publicURL,
+	}
+}
+
+// RequestExport queues a data export. While an export is in progress the
+// same job is returned instead of queueing another one.
+func (s *service) RequestExport(ctx context.Context, userID uuid.UUID) (*JobResponse, error) {
+	var current Job
+	err := s.redis.GetAccountJob(ctx, userID.String(), events.AccountExportJob, &current)
+	if err != nil && !stderrors.Is(err, redis.ErrNil) {
+		s.log.Error("failed to get export job", "user_id", userID, "error", err)
+		return nil, fmt.Errorf(errors.ErrCommon)
+	}
+	if err == nil && current.IsActive(time.Now()) {
+		return JobToResponse(&current, ""), nil
+	}
+
+	job, err := s.enqueueJob(ctx, userID, events.AccountExportJob)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("data export requested", "user_id", userID, "job_id", job.ID)
+
+	return JobToResponse(job, ""), nil
+}
+
+// GetExport returns the state of the latest export and, once it is ready, a
+// short-lived link to download the archive.
+func (s *service) GetExport(ctx context.Context, userID uuid.UUID) (*JobResponse, error) {
+	var job Job
+	err := s.redis.GetAccountJob(ctx, userID.String(), events.AccountExportJob, &job)
+	if stderrors.Is(err, redis.ErrNil) {
+		return nil, ErrExportNotFound
+	}
+	if err != nil {
+		s.log.Error("failed to get export job", "user_id", userID, "error", err)
+		return nil, fmt.Errorf(errors.ErrFailedToLoadData)
+	}
+
+	var downloadURL string
+	if job.Status == DoneStatus {
+		downloadURL, err = s.minio.GenerateDownloadURL(ctx, exportsBucketName, job.ObjectKey, "sportmates-export.zip")
+		if err != nil {
+			s.log.Error("failed to generate export download url", "user_id", userID, "error", err)
+			return nil, fmt.Errorf(errors.ErrFailedToLoadData)
+		}
+	}
+
+	return JobToResponse(&job, downloadURL), nil
+}
+
+// RequestDeletion confirms the request with the password, or a fresh sign-in
+// for accounts without one, and signs the user out everywhere right away; the
+// data itself is removed by the job.
+func (s *service) RequestDeletion(ctx context.Context, userID uuid.UUID, sessionID string, req *DeleteAccountRequest) (*JobResponse, error) {
+	if err := s.userService.Reauthenticate(ctx, userID.String(), sessionID, req.Password); err != nil {
+		return nil, err
+	}
+
+	job, err := s.enqueueJob(ctx, userID, events.AccountDeletionJob)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userService.RevokeSessions(ctx, userID.String()); err != nil {
+		s.log.Error("failed to revoke sessions of deleted account", "user_id", userID, "error", err)
+	}
+
+	s.log.Info("account deletion requested", "user_id", userID, "job_id", job.ID)
+
+	return JobToResponse(job, ""), nil
+}
+
+func (s *service) enqueueJob(ctx context.Context, userID uuid.UUID, kind string) (*Job, error) {
+	if s.rabbitmq == nil {
+		s.log.Error("event service not available - account job not queued", "user_id", userID, "kind", kind)
+		return nil, ErrJobsUnavailable
+	}
+
+	job := &Job{
+		ID:        uuid.NewString(),
+		Kind:      kind,
+		Status:    PendingStatus,
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.redis.StoreAccountJob(ctx, userID.String(), kind, job, jobTTL); err != nil {
+		s.log.Error("failed to store account job", "user_id", userID, "kind", kind, "error", err)
+		return nil, fmt.Errorf(errors.ErrCommon)
+	}
+
+	message := events.AccountJobEvent{
+		JobID:       job.ID,
+		Kind:        kind,
+		UserID:      userID.String(),
+		RequestedAt: job.CreatedAt,
+	}
+
+	if err := s.rabbitmq.PublishAccountJob(message); err != nil {
+		s.log.Error("failed to publish account job", "user_id", userID, "kind", kind, "error", err)
+		return nil, ErrJobsUnavailable
+	}
+
+	return job, nil
+}
+
+// HandleJob runs a queued job. Failures are recorded on the job rather than
+// returned, so a broken job is not redelivered forever; the user can simply
+// request it again.
+func (s *service) HandleJob(ctx context.Context, message events.AccountJobEvent) error {
+	userID, err := uuid.Parse(message.UserID)
+	if err != nil {
+		s.log.Error("invalid user id in account job", "job_id", message.JobID, "user_id", message.UserID)
+		return nil
+	}
+
+	job := &Job{
+		ID:        message.JobID,
+		Kind:      message.Kind,
+		Status:    RunningStatus,
+		CreatedAt: message.RequestedAt,
+	}
+	s.saveJob(ctx, userID, job)
+
+	switch message.Kind {
+	case events.AccountExportJob:
+		job.ObjectKey, err = s.export(ctx, userID, job.ID)
+	case events.AccountDeletionJob:
+		err = s.delete(ctx, userID)
+	default:
+		err = fmt.Errorf("unknown account job kind: %s", message.Kind)
+	}
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	job.Status = DoneStatus
+	if err != nil {
+		s.log.Error("account job failed", "job_id", job.ID, "kind", job.Kind, "user_id", userID, "error", err)
+		job.Status = FailedStatus
+	} else {
+		s.log.Info("account job completed", "job_id", job.ID, "kind", job.Kind, "user_id", userID)
+	}
+	s.saveJob(ctx, userID, job)
+
+	return nil
+}
+
+func (s *service) saveJob(ctx context.Context, userID uuid.UUID, job *Job) {
+	if err := s.redis.StoreAccountJob(ctx, userID.String(), job.Kind, job, jobTTL); err != nil {
+		s.log.Error("failed to store account job", "job_id", job.ID, "user_id", userID, "error", err)
+	}
+}
+
+// sendEmail skips accounts signed up by phone, which have no email.
+func (s *service) sendEmail(to, template, subject string, data map[string]interface{}) {
+	if to == "" {
+		return
+	}
+
+	if s.rabbitmq == nil {
+		s.log.Warn("event service not available - email not sent")
+		return
+	}
+
+	message := events.EmailEvent{
+		To:       to,
+		Template: template,
+		Subject:  subject,
+		Data:     data,
+	}
+
+	if err := s.rabbitmq.PublishEmail(message); err != nil {
+		s.log.Error("failed to publish email event", "error", err)
+	}
+}
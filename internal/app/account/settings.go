@@ -0,0 +1,124 @@
+package account
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	// The runtime image ships without a zoneinfo database.
+	_ "time/tzdata"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/errors"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAccountNotFound = stderrors.New("аккаунт не найден")
+	ErrInvalidTimezone = stderrors.New("неизвестный часовой пояс")
+)
+
+// GetCurrentUser tells the client who it is signed in as, so it does not
+// have to decode the access token.
+func (s *service) GetCurrentUser(ctx context.Context, userID uuid.UUID) (*CurrentUserResponse, error) {
+	user, err := s.repo.GetUser(ctx, userID)
+	if stderrors.Is(err, ErrUserNotFound) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		s.log.Error("failed to get user", "user_id", userID, "error", err)
+		return nil, fmt.Errorf(errors.ErrFailedToLoadData)
+	}
+
+	hasProfile, err := s.repo.HasProfile(ctx, userID)
+	if err != nil {
+		s.log.Error("failed to check profile", "user_id", userID, "error", err)
+		return nil, fmt.Errorf(errors.ErrFailedToLoadData)
+	}
+
+	settings, err := s.loadSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return UserToCurrentResponse(user, hasProfile, settings), nil
+}
+
+func (s *service) GetSettings(ctx context.Context, userID uuid.UUID) (*SettingsResponse, error) {
+	settings, err := s.loadSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return SettingsToResponse(settings), nil
+}
+
+func (s *service) UpdateSettings(ctx context.Context, userID uuid.UUID, req *UpdateSettingsRequest) (*SettingsResponse, error) {
+	settings, err := s.loadSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Language != nil {
+		settings.Language = *req.Language
+	}
+	if req.Timezone != nil {
+		if !isValidTimezone(*req.Timezone) {
+			return nil, ErrInvalidTimezone
+		}
+		settings.Timezone = *req.Timezone
+	}
+	if privacy := req.Privacy; privacy != nil {
+		if privacy.ProfileVisibility != nil {
+			settings.ProfileVisibility = *privacy.ProfileVisibility
+		}
+		if privacy.ShowBirthDate != nil {
+			settings.ShowBirthDate = *privacy.ShowBirthDate
+		}
+	}
+	if notifications := req.Notifications; notifications != nil {
+		if notifications.EventUpdates != nil {
+			settings.NotifyEventUpdates = *notifications.EventUpdates
+		}
+		if notifications.EventReminders != nil {
+			settings.NotifyEventReminders = *notifications.EventReminders
+		}
+		if notifications.ChatMessages != nil {
+			settings.NotifyChatMessages = *notifications.ChatMessages
+		}
+	}
+
+	if err := s.repo.SaveSettings(ctx, userID, settings); err != nil {
+		s.log.Error("failed to save settings", "user_id", userID, "error", err)
+		return nil, fmt.Errorf(errors.ErrFailedToSaveData)
+	}
+
+	s.log.Info("settings updated", "user_id", userID)
+
+	return SettingsToResponse(settings), nil
+}
+
+func (s *service) loadSettings(ctx context.Context, userID uuid.UUID) (*Settings, error) {
+	settings, err := s.repo.GetSettings(ctx, userID)
+	if err != nil {
+		s.log.Error("failed to get settings", "user_id", userID, "error", err)
+		return nil, fmt.Errorf(errors.ErrFailedToLoadData)
+	}
+
+	if settings == nil {
+		return DefaultSettings(), nil
+	}
+
+	return settings, nil
+}
+
+// isValidTimezone accepts IANA zone names. LoadLocation also resolves "" and
+// "Local" to the server zone, which means nothing to the client.
+func isValidTimezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
@@ -0,0 +1,37 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/events"
+	"github.com/RuLap/sportmates-api/internal/pkg/rabbitmq"
+)
+
+// Worker runs export and deletion jobs from the account jobs queue.
+type Worker struct {
+	log      *slog.Logger
+	rabbitmq *rabbitmq.Service
+	service  Service
+}
+
+func NewWorker(log *slog.Logger, rabbitmq *rabbitmq.Service, service Service) *Worker {
+	return &Worker{
+		log:      log,
+		rabbitmq: rabbitmq,
+		service:  service,
+	}
+}
+
+func (w *Worker) Run(ctx context.Context) error {
+	if w.rabbitmq == nil {
+		return fmt.Errorf("rabbitmq client is not initialized")
+	}
+
+	w.log.Info("starting account jobs worker")
+
+	return w.rabbitmq.ConsumeAccountJobs(ctx, func(job events.AccountJobEvent) error {
+		return w.service.HandleJob(ctx, job)
+	})
+}
@@ -0,0 +1,95 @@
+package chat
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	maxMessageSize = 8 * 1024
+	sendBufferSize = 32
+)
+
+type client struct {
+	conn   *websocket.Conn
+	userID uuid.UUID
+	send   chan []byte
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newClient(conn *websocket.Conn, userID uuid.UUID) *client {
+	return &client{
+		conn:   conn,
+		userID: userID,
+		send:   make(chan []byte, sendBufferSize),
+		done:   make(chan struct{}),
+	}
+}
+
+func (c *client) enqueue(payload []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	case c.send <- payload:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.flush()
+			c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
+		case payload := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+func (c *client) flush() {
+	for {
+		select {
+		case payload := <-c.send:
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
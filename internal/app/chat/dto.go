@@ -0,0 +1,27 @@
+package chat
+
+type GetMessageResponse struct {
+	ID        string `json:"id"`
+	EventID   string `json:"event_id"`
+	UserID    string `json:"user_id"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+}
+
+type SendMessageRequest struct {
+	Body string `json:"body" validate:"required,max=2000"`
+}
+
+type GetMessagesRequest struct {
+	Cursor string `validate:"omitempty,max=200"`
+	Limit  int    `validate:"omitempty,min=1,max=100"`
+}
+
+type GetMessagesResponse struct {
+	Items      []*GetMessageResponse `json:"items"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+type ErrorFrame struct {
+	Error interface{} `json:"error"`
+}
@@ -0,0 +1,282 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	app_errors "github.com/RuLap/sportmates-api/internal/pkg/errors"
+	validation "github.com/RuLap/sportmates-api/internal/pkg/validator"
+	"github.com/darahayes/go-boom"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+type Handler struct {
+	log      *slog.Logger
+	service  Service
+	hub      *Hub
+	upgrader websocket.Upgrader
+}
+
+func NewHandler(log *slog.Logger, service Service, hub *Hub, allowedOrigins []string) *Handler {
+	return &Handler{
+		log:     log,
+		service: service,
+		hub:     hub,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     checkOrigin(allowedOrigins),
+		},
+	}
+}
+
+// checkOrigin accepts browsers from the configured origins and clients that
+// send no Origin header at all, such as mobile apps. Without configured
+// origins only same-host requests pass.
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	allowed := make(map[string]struct{}, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		if origin != "" {
+			allowed[strings.ToLower(origin)] = struct{}{}
+		}
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+
+		if _, ok := allowed[strings.ToLower(origin)]; ok {
+			return true
+		}
+
+		parsed, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(parsed.Host, r.Host)
+	}
+}
+
+func (h *Handler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	req := GetMessagesRequest{Cursor: query.Get("cursor")}
+	if limit := query.Get("limit"); limit != "" {
+		if req.Limit, err = strconv.Atoi(limit); err != nil {
+			boom.BadRequest(w, "неверный формат параметра limit")
+			return
+		}
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	response, err := h.service.GetMessages(r.Context(), *id, *userID, &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	var req SendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	response, err := h.service.SendMessage(r.Context(), *id, *userID, &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusCreated)
+}
+
+func (h *Handler) Connect(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	if err := h.service.CheckAccess(r.Context(), *id, *userID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.log.Warn("failed to upgrade chat connection", "event_id", id, "user_id", userID, "error", err)
+		return
+	}
+
+	c := newClient(conn, *userID)
+
+	h.hub.join(id.String(), c)
+	defer h.hub.leave(id.String(), c)
+
+	go c.writePump()
+	h.readPump(c, *id)
+}
+
+func (h *Handler) readPump(c *client, eventID uuid.UUID) {
+	defer c.close()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, payload, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				h.log.Warn("chat connection closed unexpectedly", "event_id", eventID, "user_id", c.userID, "error", err)
+			}
+			return
+		}
+
+		var req SendMessageRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			h.sendFrameError(c, "неверный формат JSON")
+			continue
+		}
+
+		if errors := validation.ValidateStruct(req); errors != nil {
+			h.sendFrameError(c, errors)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), writeWait)
+		_, err = h.service.SendMessage(ctx, eventID, c.userID, &req)
+		cancel()
+
+		if err != nil {
+			h.sendFrameError(c, h.errorMessage(err))
+			if errors.Is(err, ErrNotFound) || errors.Is(err, ErrNotParticipant) {
+				return
+			}
+		}
+	}
+}
+
+func (h *Handler) sendFrameError(c *client, message interface{}) {
+	payload, err := json.Marshal(ErrorFrame{Error: message})
+	if err != nil {
+		h.log.Error("failed to encode chat error frame", "error", err)
+		return
+	}
+
+	c.enqueue(payload)
+}
+
+func (h *Handler) errorMessage(err error) string {
+	if errors.Is(err, ErrNotFound) {
+		return "событие не найдено"
+	}
+	return err.Error()
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		boom.NotFound(w, h.errorMessage(err))
+	case errors.Is(err, ErrNotParticipant):
+		boom.Forbidden(w, err)
+	case errors.Is(err, ErrEmptyMessage), errors.Is(err, ErrInvalidCursor):
+		boom.BadRequest(w, err)
+	default:
+		boom.Internal(w, err)
+	}
+}
+
+func (h *Handler) getUrlParamUuid(r *http.Request, param string) (*uuid.UUID, error) {
+	str := chi.URLParam(r, param)
+	if str == "" {
+		err := fmt.Errorf("параметр %s необходим", param)
+		h.log.Error("Incorrect ID in URL", param, str, "error", err.Error())
+		return nil, err
+	}
+
+	uid, err := uuid.Parse(str)
+	if err != nil {
+		err := fmt.Errorf("неверный формат параметра %s", param)
+		h.log.Error("Incorrect ID in URL", param, str, "error", err.Error())
+		return nil, err
+	}
+
+	return &uid, nil
+}
+
+func (h *Handler) sendJSON(w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}
+
+func (h *Handler) getUserIDFromContext(ctx context.Context) (*uuid.UUID, error) {
+	userIDStr, ok := ctx.Value("user_id").(string)
+	if !ok {
+		h.log.Error("Incorrect ID in context", "userID", userIDStr)
+		return nil, fmt.Errorf(app_errors.ErrCommon)
+	}
+
+	id, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.log.Error("failed to parse userID from context", "userID", userIDStr, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrCommon)
+	}
+
+	return &id, nil
+}
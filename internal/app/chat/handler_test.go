@@ -0,0 +1,57 @@
+package chat
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	check := checkOrigin([]string{"https://sportmates.ru/", " https://App.Sportmates.ru ", ""})
+
+	tests := []struct {
+		name   string
+		origin string
+		host   string
+		want   bool
+	}{
+		{"no origin", "", "api.sportmates.ru", true},
+		{"allowed origin", "https://sportmates.ru", "api.sportmates.ru", true},
+		{"allowed origin in another case", "https://app.sportmates.ru", "api.sportmates.ru", true},
+		{"same host", "https://api.sportmates.ru", "api.sportmates.ru", true},
+		{"other origin", "https://evil.example", "api.sportmates.ru", false},
+		{"allowed host with another scheme", "http://sportmates.ru", "api.sportmates.ru", false},
+		{"allowed origin as a prefix", "https://sportmates.ru.evil.example", "api.sportmates.ru", false},
+		{"null origin", "null", "api.sportmates.ru", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/events/1/messages/ws", nil)
+			r.Host = tt.host
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+
+			if got := check(r); got != tt.want {
+				t.Errorf("checkOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOriginWithoutConfiguredOrigins(t *testing.T) {
+	check := checkOrigin(nil)
+
+	r := httptest.NewRequest("GET", "/events/1/messages/ws", nil)
+	r.Host = "api.sportmates.ru"
+
+	r.Header.Set("Origin", "https://api.sportmates.ru")
+	if !check(r) {
+		t.Error("same-host origin rejected")
+	}
+
+	r.Header.Set("Origin", "https://sportmates.ru")
+	if check(r) {
+		t.Error("cross-origin request accepted")
+	}
+}
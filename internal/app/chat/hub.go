@@ -0,0 +1,137 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/redis"
+)
+
+const (
+	channelPrefix       = "event_chat:"
+	leaveChannelPrefix  = "event_chat_leave:"
+	resubscribeInterval = time.Second
+)
+
+// Hub fans chat messages out to the WebSocket clients connected to this
+// replica. Messages are published to Redis and delivered back through a
+// pattern subscription, so clients connected to other replicas receive them
+// too. Participants who leave an event are disconnected the same way.
+type Hub struct {
+	log   *slog.Logger
+	redis *redis.Service
+
+	mu    sync.RWMutex
+	rooms map[string]map[*client]struct{}
+}
+
+func NewHub(log *slog.Logger, redis *redis.Service) *Hub {
+	return &Hub{
+		log:   log,
+		redis: redis,
+		rooms: make(map[string]map[*client]struct{}),
+	}
+}
+
+func (h *Hub) Run(ctx context.Context) error {
+	subscription := h.redis.PSubscribe(ctx, channelPrefix+"*", leaveChannelPrefix+"*")
+	defer subscription.Close()
+
+	for {
+		message, err := subscription.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			h.log.Error("failed to receive chat message", "error", err)
+			time.Sleep(resubscribeInterval)
+			continue
+		}
+
+		if eventID, ok := strings.CutPrefix(message.Channel, leaveChannelPrefix); ok {
+			h.disconnect(eventID, message.Payload)
+			continue
+		}
+
+		eventID := strings.TrimPrefix(message.Channel, channelPrefix)
+		h.broadcast(eventID, []byte(message.Payload))
+	}
+}
+
+func (h *Hub) Publish(ctx context.Context, message *GetMessageResponse) error {
+	if h.redis == nil {
+		return errors.New("redis is not available")
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return h.redis.Publish(ctx, channelPrefix+message.EventID, payload)
+}
+
+// Disconnect closes the chat connections of the user on every replica.
+func (h *Hub) Disconnect(ctx context.Context, eventID, userID string) error {
+	if h.redis == nil {
+		return errors.New("redis is not available")
+	}
+
+	return h.redis.Publish(ctx, leaveChannelPrefix+eventID, userID)
+}
+
+func (h *Hub) join(eventID string, c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[eventID]
+	if !ok {
+		room = make(map[*client]struct{})
+		h.rooms[eventID] = room
+	}
+	room[c] = struct{}{}
+}
+
+func (h *Hub) leave(eventID string, c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	room, ok := h.rooms[eventID]
+	if !ok {
+		return
+	}
+
+	delete(room, c)
+	if len(room) == 0 {
+		delete(h.rooms, eventID)
+	}
+}
+
+// disconnect closes the user's clients; they are removed from the room once
+// their connection handler returns.
+func (h *Hub) disconnect(eventID, userID string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for c := range h.rooms[eventID] {
+		if c.userID.String() == userID {
+			c.close()
+		}
+	}
+}
+
+func (h *Hub) broadcast(eventID string, payload []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for c := range h.rooms[eventID] {
+		if !c.enqueue(payload) {
+			h.log.Warn("chat client is too slow, dropping message", "event_id", eventID, "user_id", c.userID)
+		}
+	}
+}
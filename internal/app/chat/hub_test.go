@@ -0,0 +1,116 @@
+package chat
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestHubBroadcast(t *testing.T) {
+	hub := NewHub(slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+
+	first := newClient(nil, uuid.UUID{1})
+	second := newClient(nil, uuid.UUID{2})
+	elsewhere := newClient(nil, uuid.UUID{1})
+
+	hub.join("event-1", first)
+	hub.join("event-1", second)
+	hub.join("event-2", elsewhere)
+
+	hub.broadcast("event-1", []byte("hello"))
+
+	if got := received(first); len(got) != 1 || got[0] != "hello" {
+		t.Errorf("first client received %q, want [hello]", got)
+	}
+	if got := received(second); len(got) != 1 || got[0] != "hello" {
+		t.Errorf("second client received %q, want [hello]", got)
+	}
+	if got := received(elsewhere); len(got) != 0 {
+		t.Errorf("client in another event chat received %q", got)
+	}
+
+	hub.leave("event-1", first)
+	hub.broadcast("event-1", []byte("bye"))
+
+	if got := received(first); len(got) != 0 {
+		t.Errorf("client that left received %q", got)
+	}
+	if got := received(second); len(got) != 1 || got[0] != "bye" {
+		t.Errorf("second client received %q, want [bye]", got)
+	}
+}
+
+func TestHubLeaveRemovesEmptyRoom(t *testing.T) {
+	hub := NewHub(nil, nil)
+	c := newClient(nil, uuid.UUID{1})
+
+	hub.join("event-1", c)
+	hub.leave("event-1", c)
+	hub.leave("event-1", c)
+
+	if _, ok := hub.rooms["event-1"]; ok {
+		t.Error("room of a chat without clients was kept")
+	}
+}
+
+func TestHubBroadcastSkipsSlowClient(t *testing.T) {
+	hub := NewHub(slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+
+	slow := newClient(nil, uuid.UUID{1})
+	hub.join("event-1", slow)
+
+	for i := 0; i < sendBufferSize+1; i++ {
+		hub.broadcast("event-1", []byte("message"))
+	}
+
+	if got := len(received(slow)); got != sendBufferSize {
+		t.Errorf("slow client received %d messages, want %d", got, sendBufferSize)
+	}
+}
+
+func received(c *client) []string {
+	var payloads []string
+	for {
+		select {
+		case payload := <-c.send:
+			payloads = append(payloads, string(payload))
+		default:
+			return payloads
+		}
+	}
+}
+
+func TestHubDisconnect(t *testing.T) {
+	hub := NewHub(nil, nil)
+
+	leaving := newClient(nil, uuid.UUID{1})
+	staying := newClient(nil, uuid.UUID{2})
+	elsewhere := newClient(nil, uuid.UUID{1})
+
+	hub.join("event-1", leaving)
+	hub.join("event-1", staying)
+	hub.join("event-2", elsewhere)
+
+	hub.disconnect("event-1", leaving.userID.String())
+
+	if !isClosed(leaving) {
+		t.Error("client of the leaving user is still open")
+	}
+	if isClosed(staying) {
+		t.Error("client of another user was closed")
+	}
+	if isClosed(elsewhere) {
+		t.Error("client in another event chat was closed")
+	}
+}
+
+func isClosed(c *client) bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,49 @@
+package chat
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func MessageToGetResponse(message *Message) *GetMessageResponse {
+	return &GetMessageResponse{
+		ID:        message.ID.String(),
+		EventID:   message.EventID.String(),
+		UserID:    message.UserID.String(),
+		Body:      message.Body,
+		CreatedAt: message.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
+
+func EncodeCursor(message *Message) string {
+	raw := message.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + message.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(cursor string) (*MessageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("invalid cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &MessageCursor{CreatedAt: createdAt, ID: id}, nil
+}
@@ -0,0 +1,20 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Message struct {
+	ID        uuid.UUID `db:"id"`
+	EventID   uuid.UUID `db:"event_id"`
+	UserID    uuid.UUID `db:"user_id"`
+	Body      string    `db:"body"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type MessageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
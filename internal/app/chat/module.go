@@ -0,0 +1,32 @@
+package chat
+
+import (
+	"log/slog"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/redis"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Module struct {
+	repo    Repository
+	Hub     *Hub
+	Service Service
+	Handler Handler
+}
+
+func NewModule(log *slog.Logger, pool *pgxpool.Pool, redis *redis.Service, allowedOrigins []string) *Module {
+	repo := NewRepository(pool)
+
+	hub := NewHub(log, redis)
+
+	service := NewService(log, repo, hub)
+
+	handler := NewHandler(log, service, hub, allowedOrigins)
+
+	return &Module{
+		repo:    repo,
+		Hub:     hub,
+		Service: service,
+		Handler: *handler,
+	}
+}
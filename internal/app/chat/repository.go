@@ -0,0 +1,105 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound = errors.New("event not found")
+)
+
+type Repository interface {
+	IsParticipant(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) (bool, error)
+	Create(ctx context.Context, model *Message) (*Message, error)
+	GetByEventID(ctx context.Context, eventID uuid.UUID, before *MessageCursor, limit int) ([]*Message, error)
+}
+
+type repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &repository{db: db}
+}
+
+// IsParticipant reports whether the user has a confirmed place in the event.
+// Waitlisted users are not part of the event yet and have no chat access.
+func (r *repository) IsParticipant(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) (bool, error) {
+	const query = `
+		SELECT
+			EXISTS (SELECT 1 FROM events WHERE id = $1),
+			EXISTS (
+				SELECT 1 FROM event_participants
+				WHERE event_id = $1 AND user_id = $2 AND status = 'confirmed'
+			)
+	`
+
+	var eventExists, participant bool
+	if err := r.db.QueryRow(ctx, query, eventID, userID).Scan(&eventExists, &participant); err != nil {
+		return false, fmt.Errorf("failed to check event participant: %w", err)
+	}
+
+	if !eventExists {
+		return false, ErrNotFound
+	}
+
+	return participant, nil
+}
+
+func (r *repository) Create(ctx context.Context, model *Message) (*Message, error) {
+	const query = `
+		INSERT INTO event_messages (event_id, user_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, model.EventID, model.UserID, model.Body).Scan(&model.ID, &model.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create message: %w", err)
+	}
+
+	return model, nil
+}
+
+func (r *repository) GetByEventID(ctx context.Context, eventID uuid.UUID, before *MessageCursor, limit int) ([]*Message, error) {
+	query := `
+		SELECT id, event_id, user_id, body, created_at
+		FROM event_messages
+		WHERE event_id = $1
+	`
+	args := []interface{}{eventID, limit}
+
+	if before != nil {
+		query += ` AND (created_at, id) < ($3, $4)`
+		args = append(args, before.CreatedAt, before.ID)
+	}
+
+	query += ` ORDER BY created_at DESC, id DESC LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*Message, 0)
+	for rows.Next() {
+		var message Message
+		err := rows.Scan(&message.ID, &message.EventID, &message.UserID, &message.Body, &message.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		result = append(result, &message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return result, nil
+}
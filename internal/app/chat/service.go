@@ -0,0 +1,146 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	app_errors "github.com/RuLap/sportmates-api/internal/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMessagesLimit = 50
+)
+
+var (
+	ErrNotParticipant = errors.New("чат доступен только участникам события")
+	ErrEmptyMessage   = errors.New("сообщение не может быть пустым")
+	ErrInvalidCursor  = errors.New("неверный курсор")
+)
+
+type Service interface {
+	CheckAccess(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) error
+	GetMessages(ctx context.Context, eventID uuid.UUID, userID uuid.UUID, req *GetMessagesRequest) (*GetMessagesResponse, error)
+	SendMessage(ctx context.Context, eventID uuid.UUID, userID uuid.UUID, req *SendMessageRequest) (*GetMessageResponse, error)
+	Disconnect(ctx context.Context, eventID uuid.UUID, userID uuid.UUID)
+}
+
+type service struct {
+	log  *slog.Logger
+	repo Repository
+	hub  *Hub
+}
+
+func NewService(log *slog.Logger, repo Repository, hub *Hub) Service {
+	return &service{
+		log:  log,
+		repo: repo,
+		hub:  hub,
+	}
+}
+
+func (s *service) CheckAccess(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) error {
+	participant, err := s.repo.IsParticipant(ctx, eventID, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return err
+		}
+		s.log.Error("failed to check chat access", "event_id", eventID, "user_id", userID, "error", err)
+		return fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	if !participant {
+		return ErrNotParticipant
+	}
+
+	return nil
+}
+
+func (s *service) GetMessages(
+	ctx context.Context,
+	eventID uuid.UUID,
+	userID uuid.UUID,
+	req *GetMessagesRequest,
+) (*GetMessagesResponse, error) {
+	if err := s.CheckAccess(ctx, eventID, userID); err != nil {
+		return nil, err
+	}
+
+	var before *MessageCursor
+	if req.Cursor != "" {
+		cursor, err := DecodeCursor(req.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		before = cursor
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultMessagesLimit
+	}
+
+	messages, err := s.repo.GetByEventID(ctx, eventID, before, limit+1)
+	if err != nil {
+		s.log.Error("failed to load messages", "event_id", eventID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	response := GetMessagesResponse{}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		response.NextCursor = EncodeCursor(messages[limit-1])
+	}
+
+	response.Items = make([]*GetMessageResponse, len(messages))
+	for i, message := range messages {
+		response.Items[i] = MessageToGetResponse(message)
+	}
+
+	return &response, nil
+}
+
+func (s *service) SendMessage(
+	ctx context.Context,
+	eventID uuid.UUID,
+	userID uuid.UUID,
+	req *SendMessageRequest,
+) (*GetMessageResponse, error) {
+	if err := s.CheckAccess(ctx, eventID, userID); err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, ErrEmptyMessage
+	}
+
+	message, err := s.repo.Create(ctx, &Message{
+		EventID: eventID,
+		UserID:  userID,
+		Body:    body,
+	})
+	if err != nil {
+		s.log.Error("failed to save message", "event_id", eventID, "user_id", userID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	response := MessageToGetResponse(message)
+
+	if err := s.hub.Publish(ctx, response); err != nil {
+		s.log.Error("failed to publish message", "event_id", eventID, "message_id", message.ID, "error", err)
+	}
+
+	return response, nil
+}
+
+// Disconnect closes the user's open chat connections, e.g. after they left
+// the event. Access is checked on every message, so a failure only delays it.
+func (s *service) Disconnect(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) {
+	if err := s.hub.Disconnect(ctx, eventID.String(), userID.String()); err != nil {
+		s.log.Error("failed to disconnect chat clients", "event_id", eventID, "user_id", userID, "error", err)
+	}
+}
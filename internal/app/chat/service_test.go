@@ -0,0 +1,186 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeRepository keeps messages in memory and orders them like the real
+// query: newest first, ties broken by id.
+type fakeRepository struct {
+	participants map[uuid.UUID]bool
+	messages     []*Message
+}
+
+func (r *fakeRepository) IsParticipant(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) (bool, error) {
+	return r.participants[userID], nil
+}
+
+func (r *fakeRepository) Create(ctx context.Context, model *Message) (*Message, error) {
+	model.ID = uuid.New()
+	model.CreatedAt = time.Now()
+	r.messages = append(r.messages, model)
+	return model, nil
+}
+
+func (r *fakeRepository) GetByEventID(ctx context.Context, eventID uuid.UUID, before *MessageCursor, limit int) ([]*Message, error) {
+	var messages []*Message
+	for _, message := range r.messages {
+		if message.EventID != eventID {
+			continue
+		}
+		if before != nil && !olderThan(message, before) {
+			continue
+		}
+		messages = append(messages, message)
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return !olderThan(messages[i], &MessageCursor{CreatedAt: messages[j].CreatedAt, ID: messages[j].ID})
+	})
+
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+	return messages, nil
+}
+
+func olderThan(message *Message, cursor *MessageCursor) bool {
+	if !message.CreatedAt.Equal(cursor.CreatedAt) {
+		return message.CreatedAt.Before(cursor.CreatedAt)
+	}
+	return message.ID.String() < cursor.ID.String()
+}
+
+func newTestService(repo Repository) Service {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewService(log, repo, NewHub(log, nil))
+}
+
+func TestSendMessage(t *testing.T) {
+	eventID := uuid.New()
+	member := uuid.New()
+	stranger := uuid.New()
+
+	repo := &fakeRepository{participants: map[uuid.UUID]bool{member: true}}
+	service := newTestService(repo)
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		body    string
+		wantErr error
+	}{
+		{"participant", member, "  see you at the court  ", nil},
+		{"not a participant", stranger, "hello", ErrNotParticipant},
+		{"blank message", member, " \n\t ", ErrEmptyMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := service.SendMessage(context.Background(), eventID, tt.userID, &SendMessageRequest{Body: tt.body})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SendMessage() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if response.Body != "see you at the court" {
+				t.Errorf("body = %q, want it trimmed", response.Body)
+			}
+			if response.EventID != eventID.String() || response.UserID != member.String() {
+				t.Errorf("response = %+v, want event %s and user %s", response, eventID, member)
+			}
+		})
+	}
+
+	if len(repo.messages) != 1 {
+		t.Errorf("saved %d messages, want 1", len(repo.messages))
+	}
+}
+
+func TestGetMessagesPaginates(t *testing.T) {
+	eventID := uuid.New()
+	member := uuid.New()
+
+	repo := &fakeRepository{participants: map[uuid.UUID]bool{member: true}}
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		repo.messages = append(repo.messages, &Message{
+			ID:        uuid.New(),
+			EventID:   eventID,
+			UserID:    member,
+			Body:      string(rune('a' + i)),
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	repo.messages = append(repo.messages, &Message{ID: uuid.New(), EventID: uuid.New(), Body: "x", CreatedAt: start})
+
+	service := newTestService(repo)
+
+	var bodies []string
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > 5 {
+			t.Fatal("pagination does not terminate")
+		}
+
+		response, err := service.GetMessages(context.Background(), eventID, member, &GetMessagesRequest{Cursor: cursor, Limit: 2})
+		if err != nil {
+			t.Fatalf("GetMessages() error = %v", err)
+		}
+		for _, item := range response.Items {
+			bodies = append(bodies, item.Body)
+		}
+
+		if response.NextCursor == "" {
+			break
+		}
+		cursor = response.NextCursor
+	}
+
+	if got, want := len(bodies), 5; got != want {
+		t.Fatalf("got %d messages %q, want %d", got, bodies, want)
+	}
+	for i, body := range []string{"e", "d", "c", "b", "a"} {
+		if bodies[i] != body {
+			t.Errorf("messages = %q, want newest first", bodies)
+			break
+		}
+	}
+}
+
+func TestGetMessagesErrors(t *testing.T) {
+	member := uuid.New()
+	service := newTestService(&fakeRepository{participants: map[uuid.UUID]bool{member: true}})
+
+	if _, err := service.GetMessages(context.Background(), uuid.New(), uuid.New(), &GetMessagesRequest{}); !errors.Is(err, ErrNotParticipant) {
+		t.Errorf("stranger: error = %v, want %v", err, ErrNotParticipant)
+	}
+	if _, err := service.GetMessages(context.Background(), uuid.New(), member, &GetMessagesRequest{Cursor: "not a cursor"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("bad cursor: error = %v, want %v", err, ErrInvalidCursor)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	message := &Message{
+		ID:        uuid.New(),
+		CreatedAt: time.Date(2025, 6, 1, 10, 0, 0, 123456789, time.FixedZone("MSK", 3*60*60)),
+	}
+
+	cursor, err := DecodeCursor(EncodeCursor(message))
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if cursor.ID != message.ID || !cursor.CreatedAt.Equal(message.CreatedAt) {
+		t.Errorf("cursor = %+v, want %s at %s", cursor, message.ID, message.CreatedAt)
+	}
+}
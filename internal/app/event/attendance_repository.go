@@ -0,0 +1,143 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrCheckInCodeNotFound = errors.New("check-in code not found")
+)
+
+type AttendanceRepository interface {
+	SaveCheckInCode(ctx context.Context, eventID uuid.UUID, code *CheckInCode) error
+	GetCheckInCode(ctx context.Context, eventID uuid.UUID) (*CheckInCode, error)
+	CheckIn(ctx context.Context, eventID uuid.UUID, userID uuid.UUID, at time.Time) error
+	GetAttendance(ctx context.Context, eventID uuid.UUID) ([]*Attendance, error)
+	SetAttendance(ctx context.Context, eventID uuid.UUID, attended []uuid.UUID) error
+}
+
+type attendanceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAttendanceRepository(db *pgxpool.Pool) AttendanceRepository {
+	return &attendanceRepository{db: db}
+}
+
+func (r *attendanceRepository) SaveCheckInCode(ctx context.Context, eventID uuid.UUID, code *CheckInCode) error {
+	const query = `
+		UPDATE events
+		SET check_in_code = $2, check_in_token = $3, check_in_expires_at = $4
+		WHERE id = $1 AND canceled_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, eventID, code.Code, code.Token, code.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save check-in code: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *attendanceRepository) GetCheckInCode(ctx context.Context, eventID uuid.UUID) (*CheckInCode, error) {
+	const query = `
+		SELECT check_in_code, check_in_token, check_in_expires_at
+		FROM events
+		WHERE id = $1 AND check_in_code IS NOT NULL
+	`
+
+	var code CheckInCode
+	err := r.db.QueryRow(ctx, query, eventID).Scan(&code.Code, &code.Token, &code.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCheckInCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to get check-in code: %w", err)
+	}
+
+	return &code, nil
+}
+
+func (r *attendanceRepository) CheckIn(ctx context.Context, eventID uuid.UUID, userID uuid.UUID, at time.Time) error {
+	const query = `
+		UPDATE event_participants
+		SET attended = true, checked_in_at = coalesce(checked_in_at, $3)
+		WHERE event_id = $1 AND user_id = $2 AND status = 'confirmed'
+	`
+
+	result, err := r.db.Exec(ctx, query, eventID, userID, at)
+	if err != nil {
+		return fmt.Errorf("failed to check in participant: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotParticipant
+	}
+
+	return nil
+}
+
+func (r *attendanceRepository) GetAttendance(ctx context.Context, eventID uuid.UUID) ([]*Attendance, error) {
+	const query = `
+		SELECT p.user_id, p.status, p.attended, p.checked_in_at
+		FROM event_participants p
+		JOIN events e ON e.id = p.event_id
+		WHERE p.event_id = $1 AND p.user_id <> e.creator_id
+		ORDER BY p.joined_at, p.user_id
+	`
+
+	rows, err := r.db.Query(ctx, query, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attendance: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*Attendance, 0)
+	for rows.Next() {
+		var attendance Attendance
+		err := rows.Scan(&attendance.UserID, &attendance.Status, &attendance.Attended, &attendance.CheckedInAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attendance: %w", err)
+		}
+		result = append(result, &attendance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+// SetAttendance marks the listed confirmed participants as attended and every
+// other confirmed participant except the organizer as a no-show. A check-in
+// with the event code is proof of attendance, so participants who checked in
+// stay attended even when the organizer leaves them out.
+func (r *attendanceRepository) SetAttendance(ctx context.Context, eventID uuid.UUID, attended []uuid.UUID) error {
+	const query = `
+		UPDATE event_participants p
+		SET attended = (p.checked_in_at IS NOT NULL OR p.user_id = ANY($2::uuid[]))
+		FROM events e
+		WHERE e.id = p.event_id
+			AND p.event_id = $1
+			AND p.status = 'confirmed'
+			AND p.user_id <> e.creator_id
+	`
+
+	if _, err := r.db.Exec(ctx, query, eventID, attended); err != nil {
+		return fmt.Errorf("failed to set attendance: %w", err)
+	}
+
+	return nil
+}
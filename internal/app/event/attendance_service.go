@@ -0,0 +1,244 @@
+package event
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	app_errors "github.com/RuLap/sportmates-api/internal/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const (
+	checkInCodeTTL      = 15 * time.Minute
+	checkInOpensBefore  = 30 * time.Minute
+	attendanceWindow    = 7 * 24 * time.Hour
+	checkInCodeLength   = 6
+	checkInCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+var (
+	ErrCheckInNotOpen     = errors.New("отметка о посещении ещё недоступна")
+	ErrCheckInClosed      = errors.New("отметка о посещении уже закрыта")
+	ErrInvalidCheckInCode = errors.New("неверный или просроченный код")
+	ErrEventNotEnded      = errors.New("событие ещё не закончилось")
+	ErrUnknownParticipant = errors.New("пользователь не является участником события")
+	ErrReliabilityTooLow  = errors.New("ваша надёжность ниже требуемой организатором")
+)
+
+func (s *service) CreateCheckInCode(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*CheckInCodeResponse, error) {
+	event, err := s.getOwnedEvent(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := checkAttendanceOpen(event, now); err != nil {
+		return nil, err
+	}
+
+	code, err := generateCheckInCode()
+	if err != nil {
+		s.log.Error("failed to generate check-in code", "error", err)
+		return nil, fmt.Errorf("не удалось сгенерировать код")
+	}
+
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		s.log.Error("failed to generate check-in token", "error", err)
+		return nil, fmt.Errorf("не удалось сгенерировать код")
+	}
+
+	checkIn := CheckInCode{
+		Code:      code,
+		Token:     hex.EncodeToString(rawToken),
+		ExpiresAt: now.Add(checkInCodeTTL),
+	}
+
+	if err := s.attendanceRepo.SaveCheckInCode(ctx, id, &checkIn); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrEventCanceled
+		}
+		s.log.Error("failed to save check-in code", "event_id", id, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	s.log.Info("check-in code issued", "event_id", id, "user_id", userID)
+
+	return CheckInCodeToResponse(&checkIn), nil
+}
+
+func (s *service) CheckIn(ctx context.Context, id uuid.UUID, req *CheckInRequest, userID uuid.UUID) error {
+	event, err := s.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if event.IsCanceled() {
+		return ErrEventCanceled
+	}
+
+	now := time.Now()
+	if err := checkAttendanceOpen(event, now); err != nil {
+		return err
+	}
+
+	checkIn, err := s.attendanceRepo.GetCheckInCode(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrCheckInCodeNotFound) {
+			return ErrInvalidCheckInCode
+		}
+		s.log.Error("failed to load check-in code", "event_id", id, "error", err)
+		return fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	if !now.Before(checkIn.ExpiresAt) || !checkIn.Matches(req.Code) {
+		return ErrInvalidCheckInCode
+	}
+
+	if err := s.attendanceRepo.CheckIn(ctx, id, userID, now); err != nil {
+		if errors.Is(err, ErrNotParticipant) {
+			return err
+		}
+		s.log.Error("failed to check in", "event_id", id, "user_id", userID, "error", err)
+		return fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	s.log.Info("participant checked in", "event_id", id, "user_id", userID)
+
+	return nil
+}
+
+func (s *service) GetAttendance(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]*AttendanceResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.CreatorID != userID {
+		return nil, ErrAccessDenied
+	}
+
+	return s.getAttendance(ctx, id)
+}
+
+// SetAttendance records who came to an ended event. Participants left out
+// of the request become no-shows unless they checked in with the code.
+func (s *service) SetAttendance(
+	ctx context.Context,
+	id uuid.UUID,
+	req *SetAttendanceRequest,
+	userID uuid.UUID,
+) ([]*AttendanceResponse, error) {
+	event, err := s.getOwnedEvent(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !event.HasEnded(now) {
+		return nil, ErrEventNotEnded
+	}
+	if err := checkAttendanceOpen(event, now); err != nil {
+		return nil, err
+	}
+
+	participants, err := s.attendanceRepo.GetAttendance(ctx, id)
+	if err != nil {
+		s.log.Error("failed to load attendance", "event_id", id, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	confirmed := make(map[uuid.UUID]bool, len(participants))
+	for _, participant := range participants {
+		confirmed[participant.UserID] = participant.Status == ConfirmedStatus
+	}
+
+	attended := make([]uuid.UUID, 0, len(req.AttendedUserIDs))
+	for _, value := range req.AttendedUserIDs {
+		participantID, err := uuid.Parse(value)
+		if err != nil || !confirmed[participantID] {
+			return nil, ErrUnknownParticipant
+		}
+		attended = append(attended, participantID)
+	}
+
+	if err := s.attendanceRepo.SetAttendance(ctx, id, attended); err != nil {
+		s.log.Error("failed to save attendance", "event_id", id, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	s.log.Info("attendance recorded", "event_id", id, "user_id", userID, "attended", len(attended))
+
+	return s.getAttendance(ctx, id)
+}
+
+func (s *service) getAttendance(ctx context.Context, id uuid.UUID) ([]*AttendanceResponse, error) {
+	list, err := s.attendanceRepo.GetAttendance(ctx, id)
+	if err != nil {
+		s.log.Error("failed to load attendance", "event_id", id, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	result := make([]*AttendanceResponse, len(list))
+	for i, attendance := range list {
+		result[i] = AttendanceToResponse(attendance)
+	}
+
+	return result, nil
+}
+
+// checkReliability enforces the organizer's minimum reliability. Users without
+// any tracked attendance are let through so that newcomers can join.
+func (s *service) checkReliability(ctx context.Context, event *Event, userID uuid.UUID) error {
+	if event.MinReliability == nil || event.CreatorID == userID {
+		return nil
+	}
+
+	reliability, err := s.profileService.GetReliability(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if reliability.Score != nil && *reliability.Score < *event.MinReliability {
+		return ErrReliabilityTooLow
+	}
+
+	return nil
+}
+
+func checkAttendanceOpen(event *Event, now time.Time) error {
+	if now.Before(event.StartDate.Add(-checkInOpensBefore)) {
+		return ErrCheckInNotOpen
+	}
+
+	end := event.StartDate
+	if event.EndDate != nil {
+		end = *event.EndDate
+	}
+
+	if now.After(end.Add(attendanceWindow)) {
+		return ErrCheckInClosed
+	}
+
+	return nil
+}
+
+func generateCheckInCode() (string, error) {
+	max := big.NewInt(int64(len(checkInCodeAlphabet)))
+
+	code := make([]byte, checkInCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = checkInCodeAlphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
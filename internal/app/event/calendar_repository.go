@@ -0,0 +1,73 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrCalendarTokenNotFound = errors.New("calendar token not found")
+)
+
+type CalendarRepository interface {
+	GetToken(ctx context.Context, userID uuid.UUID) (string, error)
+	SaveToken(ctx context.Context, userID uuid.UUID, token string) error
+	GetUserIDByToken(ctx context.Context, token string) (uuid.UUID, error)
+}
+
+type calendarRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCalendarRepository(db *pgxpool.Pool) CalendarRepository {
+	return &calendarRepository{db: db}
+}
+
+func (r *calendarRepository) GetToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	const query = `SELECT token FROM calendar_tokens WHERE user_id = $1`
+
+	var token string
+	err := r.db.QueryRow(ctx, query, userID).Scan(&token)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrCalendarTokenNotFound
+		}
+		return "", fmt.Errorf("failed to get calendar token: %w", err)
+	}
+
+	return token, nil
+}
+
+func (r *calendarRepository) SaveToken(ctx context.Context, userID uuid.UUID, token string) error {
+	const query = `
+		INSERT INTO calendar_tokens (user_id, token)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET token = EXCLUDED.token, created_at = now()
+	`
+
+	if _, err := r.db.Exec(ctx, query, userID, token); err != nil {
+		return fmt.Errorf("failed to save calendar token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *calendarRepository) GetUserIDByToken(ctx context.Context, token string) (uuid.UUID, error) {
+	const query = `SELECT user_id FROM calendar_tokens WHERE token = $1`
+
+	var userID uuid.UUID
+	err := r.db.QueryRow(ctx, query, token).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrCalendarTokenNotFound
+		}
+		return uuid.Nil, fmt.Errorf("failed to get calendar token owner: %w", err)
+	}
+
+	return userID, nil
+}
@@ -1 +1,145 @@
 package event
+
+import "github.com/RuLap/sportmates-api/internal/app/refdata"
+
+type GetEventResponse struct {
+	ID                string                   `json:"id"`
+	Title             string                   `json:"title"`
+	Description       string                   `json:"description"`
+	StartDate         string                   `json:"start_date"`
+	EndDate           string                   `json:"end_date,omitempty"`
+	City              refdata.GetCityResponse  `json:"city"`
+	Place             string                   `json:"place"`
+	Latitude          *float64                 `json:"latitude,omitempty"`
+	Longitude         *float64                 `json:"longitude,omitempty"`
+	PhotoURL          string                   `json:"photo_url"`
+	Sport             refdata.GetSportResponse `json:"sport"`
+	CreatorID         string                   `json:"creator_id"`
+	SeriesID          string                   `json:"series_id,omitempty"`
+	IsSeriesException bool                     `json:"is_series_exception,omitempty"`
+	MaxParticipants   *int                     `json:"max_participants"`
+	MinReliability    *float64                 `json:"min_reliability,omitempty"`
+	ParticipantsCount int                      `json:"participants_count"`
+	IsCanceled        bool                     `json:"is_canceled"`
+	CreatedAt         string                   `json:"created_at"`
+}
+
+type CreateEventRequest struct {
+	Title           string             `json:"title" validate:"required,max=100"`
+	Description     string             `json:"description" validate:"max=2000"`
+	StartDate       string             `json:"start_date" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	EndDate         string             `json:"end_date" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	CityID          int                `json:"city_id" validate:"required"`
+	Place           string             `json:"place" validate:"required,max=200"`
+	Latitude        *float64           `json:"latitude" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude       *float64           `json:"longitude" validate:"required_with=Latitude,omitempty,longitude"`
+	SportID         string             `json:"sport_id" validate:"required,uuid"`
+	MaxParticipants *int               `json:"max_participants" validate:"omitempty,min=2,max=1000"`
+	MinReliability  *float64           `json:"min_reliability" validate:"omitempty,min=0,max=1"`
+	Recurrence      *RecurrenceRequest `json:"recurrence" validate:"omitempty"`
+}
+
+type RecurrenceRequest struct {
+	Frequency string   `json:"frequency" validate:"required,oneof=daily weekly monthly"`
+	Interval  int      `json:"interval" validate:"omitempty,min=1,max=12"`
+	Weekdays  []string `json:"weekdays" validate:"omitempty,max=7,dive,oneof=MO TU WE TH FR SA SU"`
+	Until     string   `json:"until" validate:"required_without=Count,omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Count     int      `json:"count" validate:"required_without=Until,omitempty,min=1,max=100"`
+}
+
+type UpdateEventRequest struct {
+	Title           *string  `json:"title" validate:"omitempty,min=1,max=100"`
+	Description     *string  `json:"description" validate:"omitempty,max=2000"`
+	StartDate       *string  `json:"start_date" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	EndDate         *string  `json:"end_date" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	CityID          *int     `json:"city_id" validate:"omitempty,min=1"`
+	Place           *string  `json:"place" validate:"omitempty,min=1,max=200"`
+	Latitude        *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude       *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,longitude"`
+	SportID         *string  `json:"sport_id" validate:"omitempty,uuid"`
+	MaxParticipants *int     `json:"max_participants" validate:"omitempty,min=2,max=1000"`
+	MinReliability  *float64 `json:"min_reliability" validate:"omitempty,min=0,max=1"`
+	Scope           string   `json:"scope" validate:"omitempty,oneof=this following"`
+}
+
+type JoinEventResponse struct {
+	Status           string `json:"status"`
+	WaitlistPosition int    `json:"waitlist_position,omitempty"`
+}
+
+type SearchEventsRequest struct {
+	CityID   int      `validate:"omitempty,min=1"`
+	SportIDs []string `validate:"omitempty,max=20,dive,uuid"`
+	From     string   `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To       string   `validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Query    string   `validate:"omitempty,max=100"`
+	Cursor   string   `validate:"omitempty,max=200"`
+	Limit    int      `validate:"omitempty,min=1,max=100"`
+}
+
+type SearchEventsResponse struct {
+	Items      []*GetEventResponse `json:"items"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+type RecommendedEventResponse struct {
+	Event *GetEventResponse `json:"event"`
+	Score float64           `json:"score"`
+}
+
+type NearbyEventsRequest struct {
+	Latitude  *float64 `validate:"required,latitude"`
+	Longitude *float64 `validate:"required,longitude"`
+	RadiusKm  float64  `validate:"omitempty,gt=0,max=100"`
+	Limit     int      `validate:"omitempty,min=1,max=100"`
+}
+
+type NearbyEventResponse struct {
+	Event      *GetEventResponse `json:"event"`
+	DistanceKm float64           `json:"distance_km"`
+}
+
+type CalendarFeedResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+type GetPhotoUploadURLResponse struct {
+	URL string `json:"url"`
+	Key string `json:"key"`
+}
+
+type ConfirmPhotoUploadRequest struct {
+	Key string `json:"key" validate:"required,max=255"`
+}
+
+type GetEventPhotoResponse struct {
+	ID          string `json:"id"`
+	EventID     string `json:"event_id"`
+	UserID      string `json:"user_id"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type CheckInCodeResponse struct {
+	Code      string `json:"code"`
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type CheckInRequest struct {
+	Code string `json:"code" validate:"required,max=100"`
+}
+
+type SetAttendanceRequest struct {
+	AttendedUserIDs []string `json:"attended_user_ids" validate:"max=1000,dive,uuid"`
+}
+
+type AttendanceResponse struct {
+	UserID      string `json:"user_id"`
+	Status      string `json:"status"`
+	Attended    *bool  `json:"attended"`
+	CheckedInAt string `json:"checked_in_at,omitempty"`
+}
@@ -1 +1,454 @@
 package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound = errors.New("event not found")
+)
+
+const eventColumns = `
+	e.id, e.title, e.description, e.start_date, e.end_date, e.city_id, e.place,
+	e.latitude, e.longitude, e.photo_url, e.sport_id, e.creator_id, e.max_participants, e.min_reliability,
+	(SELECT count(*) FROM event_participants p WHERE p.event_id = e.id AND p.status = 'confirmed'),
+	e.series_id, e.series_detached, e."sequence", e.canceled_at, e.created_at, e.updated_at
+`
+
+type EventRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*Event, error)
+	Create(ctx context.Context, model *Event) (*Event, error)
+	Update(ctx context.Context, model *Event) (*Event, error)
+	Cancel(ctx context.Context, id uuid.UUID) error
+	Search(ctx context.Context, filter *EventFilter) ([]*Event, error)
+	GetRecommendationCandidates(ctx context.Context, userID uuid.UUID, profile *RecommendationProfile) ([]*RecommendationCandidate, error)
+	GetNearby(ctx context.Context, filter *NearbyFilter) ([]*NearbyEvent, error)
+	SetPhoto(ctx context.Context, id uuid.UUID, photoKey *string) (*string, error)
+}
+
+type eventRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEventRepository(db *pgxpool.Pool) EventRepository {
+	return &eventRepository{db: db}
+}
+
+func (r *eventRepository) GetByID(ctx context.Context, id uuid.UUID) (*Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events e
+		WHERE e.id = $1
+	`
+
+	event, err := scanEvent(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find event by ID: %w", err)
+	}
+
+	return event, nil
+}
+
+func (r *eventRepository) Create(ctx context.Context, model *Event) (*Event, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = insertEvent(ctx, tx, model)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func (r *eventRepository) Update(ctx context.Context, model *Event) (*Event, error) {
+	err := updateEvent(ctx, r.db, model)
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func (r *eventRepository) Cancel(ctx context.Context, id uuid.UUID) error {
+	const query = `
+		UPDATE events
+		SET canceled_at = now(), updated_at = now(), "sequence" = "sequence" + 1
+		WHERE id = $1 AND canceled_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel event: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *eventRepository) Search(ctx context.Context, filter *EventFilter) ([]*Event, error) {
+	conditions := []string{"e.canceled_at IS NULL"}
+	args := []interface{}{}
+
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.CityID != nil {
+		conditions = append(conditions, "e.city_id = "+arg(*filter.CityID))
+	}
+	if len(filter.SportIDs) > 0 {
+		conditions = append(conditions, "e.sport_id = ANY("+arg(filter.SportIDs)+"::uuid[])")
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "e.start_date >= "+arg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "e.start_date < "+arg(*filter.To))
+	}
+	if filter.Query != "" {
+		conditions = append(conditions,
+			"(e.title || ' ' || coalesce(e.description, '')) ILIKE '%' || "+arg(escapeLike(filter.Query))+" || '%'")
+	}
+	if filter.After != nil {
+		conditions = append(conditions,
+			"(e.start_date, e.id) > ("+arg(filter.After.StartDate)+", "+arg(filter.After.ID)+")")
+	}
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events e
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY e.start_date, e.id
+		LIMIT ` + arg(filter.Limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// GetRecommendationCandidates returns every upcoming event that shares a
+// sport or the region with the profile, or that an acquaintance joined. The
+// set is not truncated, so ranking sees late events that score higher than
+// earlier ones.
+func (r *eventRepository) GetRecommendationCandidates(
+	ctx context.Context,
+	userID uuid.UUID,
+	profile *RecommendationProfile,
+) ([]*RecommendationCandidate, error) {
+	query := `
+		WITH acquaintances AS (
+			SELECT DISTINCT other.user_id
+			FROM event_participants me
+			JOIN event_participants other ON other.event_id = me.event_id AND other.user_id <> me.user_id
+			JOIN events past ON past.id = me.event_id
+			WHERE me.user_id = $1
+				AND me.status = 'confirmed'
+				AND other.status = 'confirmed'
+				AND past.start_date < now()
+				AND past.canceled_at IS NULL
+		),
+		acquainted AS (
+			SELECT p.event_id, count(*) AS acquaintances
+			FROM event_participants p
+			JOIN acquaintances a ON a.user_id = p.user_id
+			JOIN events upcoming ON upcoming.id = p.event_id
+			WHERE p.status = 'confirmed'
+				AND upcoming.start_date > now()
+			GROUP BY p.event_id
+		)
+		SELECT ` + eventColumns + `, c.region_id, COALESCE(acq.acquaintances, 0)
+		FROM events e
+		JOIN cities c ON c.id = e.city_id
+		LEFT JOIN acquainted acq ON acq.event_id = e.id
+		WHERE e.canceled_at IS NULL
+			AND e.start_date > now()
+			AND (e.sport_id = ANY($2::uuid[]) OR c.region_id = $3 OR acq.event_id IS NOT NULL)
+			AND NOT EXISTS (
+				SELECT 1 FROM event_participants p WHERE p.event_id = e.id AND p.user_id = $1
+			)
+		ORDER BY e.start_date, e.id
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, profile.SportIDs, profile.RegionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recommendation candidates: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*RecommendationCandidate, 0)
+	for rows.Next() {
+		var event Event
+		var candidate RecommendationCandidate
+		err := rows.Scan(append(eventScanTargets(&event), &candidate.RegionID, &candidate.Acquaintances)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recommendation candidate: %w", err)
+		}
+		candidate.Event = &event
+		result = append(result, &candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *eventRepository) GetNearby(ctx context.Context, filter *NearbyFilter) ([]*NearbyEvent, error) {
+	box := NewBoundingBox(filter.Latitude, filter.Longitude, filter.RadiusKm)
+
+	longitudeCondition := "e.longitude BETWEEN $5 AND $6"
+	if box.CrossesAntimeridian() {
+		longitudeCondition = "(e.longitude >= $5 OR e.longitude <= $6)"
+	}
+
+	query := `
+		SELECT ` + eventColumns + `, d.distance_km
+		FROM events e
+		CROSS JOIN LATERAL (
+			SELECT 2 * ` + fmt.Sprint(earthRadiusKm) + ` * asin(least(1, sqrt(
+				power(sin(radians(e.latitude - $1) / 2), 2) +
+				cos(radians($1)) * cos(radians(e.latitude)) *
+				power(sin(radians(e.longitude - $2) / 2), 2)
+			))) AS distance_km
+		) d
+		WHERE e.latitude IS NOT NULL
+			AND e.canceled_at IS NULL
+			AND e.latitude BETWEEN $3 AND $4
+			AND ` + longitudeCondition + `
+			AND e.start_date >= $7
+			AND d.distance_km <= $8
+		ORDER BY d.distance_km, e.start_date, e.id
+		LIMIT $9
+	`
+
+	rows, err := r.db.Query(
+		ctx,
+		query,
+		filter.Latitude,
+		filter.Longitude,
+		box.MinLatitude,
+		box.MaxLatitude,
+		box.MinLongitude,
+		box.MaxLongitude,
+		filter.From,
+		filter.RadiusKm,
+		filter.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nearby events: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*NearbyEvent, 0)
+	for rows.Next() {
+		var event Event
+		var nearby NearbyEvent
+		if err := rows.Scan(append(eventScanTargets(&event), &nearby.DistanceKm)...); err != nil {
+			return nil, fmt.Errorf("failed to scan nearby event: %w", err)
+		}
+		nearby.Event = &event
+		result = append(result, &nearby)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+// SetPhoto replaces the cover photo key and returns the previous one so that
+// the old object can be removed from storage.
+func (r *eventRepository) SetPhoto(ctx context.Context, id uuid.UUID, photoKey *string) (*string, error) {
+	const query = `
+		UPDATE events e
+		SET photo_url = $2, updated_at = now()
+		FROM (SELECT id, photo_url FROM events WHERE id = $1 FOR UPDATE) old
+		WHERE e.id = old.id AND e.canceled_at IS NULL
+		RETURNING old.photo_url
+	`
+
+	var previous *string
+	err := r.db.QueryRow(ctx, query, id, photoKey).Scan(&previous)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to set event photo: %w", err)
+	}
+
+	return previous, nil
+}
+
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func insertEvent(ctx context.Context, tx pgx.Tx, model *Event) error {
+	const query = `
+		INSERT INTO events (
+			title, description, start_date, end_date, city_id, place,
+			sport_id, creator_id, max_participants, series_id, latitude, longitude, min_reliability
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		model.Title,
+		model.Description,
+		model.StartDate,
+		model.EndDate,
+		model.CityID,
+		model.Place,
+		model.SportID,
+		model.CreatorID,
+		model.MaxParticipants,
+		model.SeriesID,
+		model.Latitude,
+		model.Longitude,
+		model.MinReliability,
+	).Scan(&model.ID, &model.CreatedAt, &model.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create event: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO event_participants (event_id, user_id, status)
+		VALUES ($1, $2, $3)
+	`, model.ID, model.CreatorID, ConfirmedStatus)
+	if err != nil {
+		return fmt.Errorf("failed to add creator to participants: %w", err)
+	}
+	model.ParticipantsCount = 1
+
+	return nil
+}
+
+func updateEvent(ctx context.Context, db querier, model *Event) error {
+	const query = `
+		UPDATE events
+		SET title = $2, description = $3, start_date = $4, end_date = $5,
+			city_id = $6, place = $7, sport_id = $8, max_participants = $9,
+			series_id = $10, series_detached = $11, latitude = $12, longitude = $13,
+			min_reliability = $14, updated_at = now(), "sequence" = "sequence" + 1
+		WHERE id = $1 AND canceled_at IS NULL
+		RETURNING updated_at, "sequence"
+	`
+
+	err := db.QueryRow(
+		ctx,
+		query,
+		model.ID,
+		model.Title,
+		model.Description,
+		model.StartDate,
+		model.EndDate,
+		model.CityID,
+		model.Place,
+		model.SportID,
+		model.MaxParticipants,
+		model.SeriesID,
+		model.SeriesDetached,
+		model.Latitude,
+		model.Longitude,
+		model.MinReliability,
+	).Scan(&model.UpdatedAt, &model.Sequence)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to update event: %w", err)
+	}
+
+	return nil
+}
+
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
+
+func scanEvents(rows pgx.Rows) ([]*Event, error) {
+	result := make([]*Event, 0)
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		result = append(result, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+func scanEvent(row pgx.Row) (*Event, error) {
+	var event Event
+	if err := row.Scan(eventScanTargets(&event)...); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func eventScanTargets(event *Event) []interface{} {
+	return []interface{}{
+		&event.ID,
+		&event.Title,
+		&event.Description,
+		&event.StartDate,
+		&event.EndDate,
+		&event.CityID,
+		&event.Place,
+		&event.Latitude,
+		&event.Longitude,
+		&event.PhotoURL,
+		&event.SportID,
+		&event.CreatorID,
+		&event.MaxParticipants,
+		&event.MinReliability,
+		&event.ParticipantsCount,
+		&event.SeriesID,
+		&event.SeriesDetached,
+		&event.Sequence,
+		&event.CanceledAt,
+		&event.CreatedAt,
+		&event.UpdatedAt,
+	}
+}
@@ -0,0 +1,59 @@
+package event
+
+import "math"
+
+const (
+	earthRadiusKm  = 6371.0
+	kmPerDegreeLat = 111.045
+)
+
+type BoundingBox struct {
+	MinLatitude  float64
+	MaxLatitude  float64
+	MinLongitude float64
+	MaxLongitude float64
+}
+
+// NewBoundingBox returns a box that contains every point within radiusKm of
+// the center. It is used as an index-friendly prefilter before the exact
+// haversine distance is computed. When the box crosses the antimeridian,
+// MinLongitude is greater than MaxLongitude.
+func NewBoundingBox(latitude, longitude, radiusKm float64) BoundingBox {
+	deltaLat := radiusKm / kmPerDegreeLat
+
+	box := BoundingBox{
+		MinLatitude:  math.Max(latitude-deltaLat, -90),
+		MaxLatitude:  math.Min(latitude+deltaLat, 90),
+		MinLongitude: -180,
+		MaxLongitude: 180,
+	}
+
+	cos := math.Cos(latitude * math.Pi / 180)
+	if box.MinLatitude <= -90 || box.MaxLatitude >= 90 || cos < 1e-6 {
+		return box
+	}
+
+	deltaLon := radiusKm / (kmPerDegreeLat * cos)
+	if deltaLon >= 180 {
+		return box
+	}
+
+	box.MinLongitude = normalizeLongitude(longitude - deltaLon)
+	box.MaxLongitude = normalizeLongitude(longitude + deltaLon)
+
+	return box
+}
+
+func (b BoundingBox) CrossesAntimeridian() bool {
+	return b.MinLongitude > b.MaxLongitude
+}
+
+func normalizeLongitude(longitude float64) float64 {
+	for longitude < -180 {
+		longitude += 360
+	}
+	for longitude > 180 {
+		longitude -= 360
+	}
+	return longitude
+}
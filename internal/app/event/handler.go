@@ -1 +1,778 @@
 package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	app_errors "github.com/RuLap/sportmates-api/internal/pkg/errors"
+	validation "github.com/RuLap/sportmates-api/internal/pkg/validator"
+	"github.com/darahayes/go-boom"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultRecommendedLimit = 20
+	maxRecommendedLimit     = 50
+)
+
+type Handler struct {
+	log     *slog.Logger
+	service Service
+}
+
+func NewHandler(log *slog.Logger, service Service) *Handler {
+	return &Handler{log: log, service: service}
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	response, err := h.service.GetByID(r.Context(), *id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var req CreateEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	response, err := h.service.Create(r.Context(), &req, *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusCreated)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	var req UpdateEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	response, err := h.service.Update(r.Context(), *id, &req, *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	scope := EditScope(r.URL.Query().Get("scope"))
+	if scope != "" && scope != ThisScope && scope != FollowingScope {
+		boom.BadRequest(w, "неверный формат параметра scope")
+		return
+	}
+
+	if err := h.service.Cancel(r.Context(), *id, *userID, scope); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ModerateCancel(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	moderatorID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	scope := EditScope(r.URL.Query().Get("scope"))
+	if scope != "" && scope != ThisScope && scope != FollowingScope {
+		boom.BadRequest(w, "неверный формат параметра scope")
+		return
+	}
+
+	if err := h.service.ModerateCancel(r.Context(), *id, *moderatorID, scope); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) Join(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	response, err := h.service.Join(r.Context(), *id, *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) Leave(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	if err := h.service.Leave(r.Context(), *id, *userID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	req := SearchEventsRequest{
+		From:   query.Get("from"),
+		To:     query.Get("to"),
+		Query:  query.Get("q"),
+		Cursor: query.Get("cursor"),
+	}
+
+	var err error
+	if cityID := query.Get("city_id"); cityID != "" {
+		if req.CityID, err = strconv.Atoi(cityID); err != nil {
+			boom.BadRequest(w, "неверный формат параметра city_id")
+			return
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		if req.Limit, err = strconv.Atoi(limit); err != nil {
+			boom.BadRequest(w, "неверный формат параметра limit")
+			return
+		}
+	}
+
+	for _, value := range query["sport_id"] {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				req.SportIDs = append(req.SportIDs, id)
+			}
+		}
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	response, err := h.service.Search(r.Context(), &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) GetRecommended(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	limit := defaultRecommendedLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxRecommendedLimit {
+			boom.BadRequest(w, "неверный формат параметра limit")
+			return
+		}
+	}
+
+	response, err := h.service.GetRecommended(r.Context(), *userID, limit)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) GetNearby(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var req NearbyEventsRequest
+	var err error
+
+	if req.Latitude, err = parseFloatParam(query, "lat"); err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	if req.Longitude, err = parseFloatParam(query, "lon"); err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	radius, err := parseFloatParam(query, "radius_km")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+	if radius != nil {
+		req.RadiusKm = *radius
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		if req.Limit, err = strconv.Atoi(limit); err != nil {
+			boom.BadRequest(w, "неверный формат параметра limit")
+			return
+		}
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	response, err := h.service.GetNearby(r.Context(), &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	calendar, err := h.service.GetCalendar(r.Context(), *id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%s.ics"`, id))
+	h.sendCalendar(w, calendar)
+}
+
+func (h *Handler) GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		boom.NotFound(w, "календарь не найден")
+		return
+	}
+
+	calendar, err := h.service.GetCalendarFeed(r.Context(), token)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendCalendar(w, calendar)
+}
+
+func (h *Handler) GetCalendarFeedURL(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	response, err := h.service.GetCalendarFeedURL(r.Context(), *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) RotateCalendarFeedURL(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	response, err := h.service.RotateCalendarFeedURL(r.Context(), *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) GetCoverUploadURL(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	response, err := h.service.GetCoverUploadURL(r.Context(), *id, *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) ConfirmCoverUpload(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	var req ConfirmPhotoUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	response, err := h.service.ConfirmCoverUpload(r.Context(), *id, &req, *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) DeleteCover(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	if err := h.service.DeleteCover(r.Context(), *id, *userID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetPhotos(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	response, err := h.service.GetPhotos(r.Context(), *id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) GetGalleryUploadURL(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	response, err := h.service.GetGalleryUploadURL(r.Context(), *id, *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) ConfirmGalleryUpload(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	var req ConfirmPhotoUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	response, err := h.service.ConfirmGalleryUpload(r.Context(), *id, &req, *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusCreated)
+}
+
+func (h *Handler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	photoID, err := h.getUrlParamUuid(r, "photoID")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	if err := h.service.DeletePhoto(r.Context(), *id, *photoID, *userID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) CreateCheckInCode(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	response, err := h.service.CreateCheckInCode(r.Context(), *id, *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusCreated)
+}
+
+func (h *Handler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	var req CheckInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	if err := h.service.CheckIn(r.Context(), *id, &req, *userID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetAttendance(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	response, err := h.service.GetAttendance(r.Context(), *id, *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) SetAttendance(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getUrlParamUuid(r, "id")
+	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	userID, err := h.getUserIDFromContext(r.Context())
+	if err != nil {
+		boom.Unathorized(w, err)
+		return
+	}
+
+	var req SetAttendanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	response, err := h.service.SetAttendance(r.Context(), *id, &req, *userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		boom.NotFound(w, "событие не найдено")
+	case errors.Is(err, ErrCalendarTokenNotFound):
+		boom.NotFound(w, "календарь не найден")
+	case errors.Is(err, ErrPhotoNotFound):
+		boom.NotFound(w, "фотография не найдена")
+	case errors.Is(err, ErrPhotoExists):
+		boom.Conflict(w, "фотография уже добавлена")
+	case errors.Is(err, ErrGalleryParticipant),
+		errors.Is(err, ErrReliabilityTooLow):
+		boom.Forbidden(w, err)
+	case errors.Is(err, ErrAccessDenied):
+		boom.Forbidden(w, err)
+	case errors.Is(err, ErrAlreadyJoined):
+		boom.Conflict(w, "вы уже записаны на событие")
+	case errors.Is(err, ErrNotParticipant):
+		boom.NotFound(w, "вы не записаны на событие")
+	case errors.Is(err, ErrEventCanceled),
+		errors.Is(err, ErrEventStarted),
+		errors.Is(err, ErrCreatorLeave),
+		errors.Is(err, ErrCapacityLow),
+		errors.Is(err, ErrGalleryLimit),
+		errors.Is(err, ErrCheckInNotOpen),
+		errors.Is(err, ErrCheckInClosed),
+		errors.Is(err, ErrEventNotEnded):
+		boom.Conflict(w, err)
+	case errors.Is(err, ErrInvalidDates),
+		errors.Is(err, ErrInvalidFilter),
+		errors.Is(err, ErrInvalidRecurrence),
+		errors.Is(err, ErrTooManyOccurrences),
+		errors.Is(err, ErrInvalidCity),
+		errors.Is(err, ErrInvalidSport),
+		errors.Is(err, ErrPhotoNotUploaded),
+		errors.Is(err, ErrPhotoTooLarge),
+		errors.Is(err, ErrPhotoContentType),
+		errors.Is(err, ErrInvalidPhotoKey),
+		errors.Is(err, ErrInvalidCheckInCode),
+		errors.Is(err, ErrUnknownParticipant):
+		boom.BadRequest(w, err)
+	default:
+		boom.Internal(w, err)
+	}
+}
+
+func (h *Handler) getUrlParamUuid(r *http.Request, param string) (*uuid.UUID, error) {
+	str := chi.URLParam(r, param)
+	if str == "" {
+		err := fmt.Errorf("параметр %s необходим", param)
+		h.log.Error("Incorrect ID in URL", param, str, "error", err.Error())
+		return nil, err
+	}
+
+	uid, err := uuid.Parse(str)
+	if err != nil {
+		err := fmt.Errorf("неверный формат параметра %s", param)
+		h.log.Error("Incorrect ID in URL", param, str, "error", err.Error())
+		return nil, err
+	}
+
+	return &uid, nil
+}
+
+func parseFloatParam(query url.Values, param string) (*float64, error) {
+	value := query.Get(param)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат параметра %s", param)
+	}
+
+	return &parsed, nil
+}
+
+func (h *Handler) sendJSON(w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}
+
+func (h *Handler) sendCalendar(w http.ResponseWriter, calendar []byte) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(calendar)
+}
+
+func (h *Handler) getUserIDFromContext(ctx context.Context) (*uuid.UUID, error) {
+	userIDStr, ok := ctx.Value("user_id").(string)
+	if !ok {
+		h.log.Error("Incorrect ID in context", "userID", userIDStr)
+		return nil, fmt.Errorf(app_errors.ErrCommon)
+	}
+
+	id, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.log.Error("failed to parse userID from context", "userID", userIDStr, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrCommon)
+	}
+
+	return &id, nil
+}
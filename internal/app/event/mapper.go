@@ -0,0 +1,341 @@
+package event
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/app/refdata"
+	"github.com/RuLap/sportmates-api/internal/pkg/ical"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSearchLimit    = 20
+	defaultNearbyRadiusKm = 10
+	defaultNearbyLimit    = 20
+	calendarUIDDomain     = "sportmates.ru"
+	eventURLFormat        = "%s/events/%s"
+)
+
+func EventToGetResponse(event *Event, city *refdata.GetCityResponse, sport *refdata.GetSportResponse) *GetEventResponse {
+	dto := GetEventResponse{
+		ID:                event.ID.String(),
+		Title:             event.Title,
+		StartDate:         event.StartDate.Format(time.RFC3339),
+		Place:             event.Place,
+		Latitude:          event.Latitude,
+		Longitude:         event.Longitude,
+		CreatorID:         event.CreatorID.String(),
+		MaxParticipants:   event.MaxParticipants,
+		MinReliability:    event.MinReliability,
+		ParticipantsCount: event.ParticipantsCount,
+		IsCanceled:        event.IsCanceled(),
+		CreatedAt:         event.CreatedAt.Format(time.RFC3339),
+	}
+
+	if event.Description != nil {
+		dto.Description = *event.Description
+	}
+	if event.EndDate != nil {
+		dto.EndDate = event.EndDate.Format(time.RFC3339)
+	}
+	if event.SeriesID != nil {
+		dto.SeriesID = event.SeriesID.String()
+		dto.IsSeriesException = event.SeriesDetached
+	}
+
+	dto.City = *city
+	dto.Sport = *sport
+
+	return &dto
+}
+
+func CreateRequestToEvent(dto *CreateEventRequest) (*Event, error) {
+	model := Event{
+		Title:           dto.Title,
+		CityID:          dto.CityID,
+		Place:           dto.Place,
+		Latitude:        dto.Latitude,
+		Longitude:       dto.Longitude,
+		MaxParticipants: dto.MaxParticipants,
+		MinReliability:  dto.MinReliability,
+	}
+
+	if dto.Description != "" {
+		model.Description = &dto.Description
+	}
+
+	startDate, err := time.Parse(time.RFC3339, dto.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	model.StartDate = startDate
+
+	if dto.EndDate != "" {
+		endDate, err := time.Parse(time.RFC3339, dto.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		model.EndDate = &endDate
+	}
+
+	sportID, err := uuid.Parse(dto.SportID)
+	if err != nil {
+		return nil, err
+	}
+	model.SportID = sportID
+
+	return &model, nil
+}
+
+func ApplyUpdateRequest(model *Event, dto *UpdateEventRequest) error {
+	if dto.Title != nil {
+		model.Title = *dto.Title
+	}
+	if dto.Description != nil {
+		model.Description = dto.Description
+	}
+	if dto.StartDate != nil {
+		startDate, err := time.Parse(time.RFC3339, *dto.StartDate)
+		if err != nil {
+			return err
+		}
+		model.StartDate = startDate
+	}
+	if dto.EndDate != nil {
+		endDate, err := time.Parse(time.RFC3339, *dto.EndDate)
+		if err != nil {
+			return err
+		}
+		model.EndDate = &endDate
+	}
+	if dto.CityID != nil {
+		model.CityID = *dto.CityID
+	}
+	if dto.Place != nil {
+		model.Place = *dto.Place
+	}
+	if dto.Latitude != nil && dto.Longitude != nil {
+		model.Latitude = dto.Latitude
+		model.Longitude = dto.Longitude
+	}
+	if dto.SportID != nil {
+		sportID, err := uuid.Parse(*dto.SportID)
+		if err != nil {
+			return err
+		}
+		model.SportID = sportID
+	}
+	if dto.MaxParticipants != nil {
+		model.MaxParticipants = dto.MaxParticipants
+	}
+	if dto.MinReliability != nil {
+		model.MinReliability = dto.MinReliability
+	}
+
+	return nil
+}
+
+func RecurrenceRequestToSeries(dto *RecurrenceRequest, startDate time.Time) (*EventSeries, error) {
+	model := EventSeries{
+		Frequency: Frequency(dto.Frequency),
+		Interval:  dto.Interval,
+		Weekdays:  dto.Weekdays,
+		StartDate: startDate,
+	}
+
+	if model.Interval == 0 {
+		model.Interval = 1
+	}
+
+	if dto.Until != "" {
+		until, err := time.Parse(time.RFC3339, dto.Until)
+		if err != nil {
+			return nil, err
+		}
+		model.Until = &until
+	}
+
+	if dto.Count != 0 {
+		model.Count = &dto.Count
+	}
+
+	return &model, nil
+}
+
+func ParticipantToJoinResponse(participant *EventParticipant, waitlistPosition int) *JoinEventResponse {
+	return &JoinEventResponse{
+		Status:           string(participant.Status),
+		WaitlistPosition: waitlistPosition,
+	}
+}
+
+func PhotoToGetResponse(photo *EventPhoto, url string) *GetEventPhotoResponse {
+	return &GetEventPhotoResponse{
+		ID:          photo.ID.String(),
+		EventID:     photo.EventID.String(),
+		UserID:      photo.UserID.String(),
+		URL:         url,
+		ContentType: photo.ContentType,
+		Size:        photo.Size,
+		CreatedAt:   photo.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func CheckInCodeToResponse(code *CheckInCode) *CheckInCodeResponse {
+	return &CheckInCodeResponse{
+		Code:      code.Code,
+		Token:     code.Token,
+		ExpiresAt: code.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+func AttendanceToResponse(attendance *Attendance) *AttendanceResponse {
+	dto := AttendanceResponse{
+		UserID:   attendance.UserID.String(),
+		Status:   string(attendance.Status),
+		Attended: attendance.Attended,
+	}
+
+	if attendance.CheckedInAt != nil {
+		dto.CheckedInAt = attendance.CheckedInAt.Format(time.RFC3339)
+	}
+
+	return &dto
+}
+
+func EventToCalendarEvent(event *Event, city *refdata.GetCityResponse, status ParticipantStatus, publicURL string) ical.Event {
+	result := ical.Event{
+		UID:          event.ID.String() + "@" + calendarUIDDomain,
+		Sequence:     event.Sequence,
+		Stamp:        event.UpdatedAt,
+		LastModified: event.UpdatedAt,
+		Start:        event.StartDate,
+		End:          event.EndDate,
+		Summary:      event.Title,
+		Location:     event.Place,
+		URL:          fmt.Sprintf(eventURLFormat, publicURL, event.ID),
+		Latitude:     event.Latitude,
+		Longitude:    event.Longitude,
+		Status:       ical.StatusConfirmed,
+	}
+
+	if event.Description != nil {
+		result.Description = *event.Description
+	}
+	if city != nil && city.Name != "" {
+		result.Location = event.Place + ", " + city.Name
+	}
+
+	switch {
+	case event.IsCanceled():
+		result.Status = ical.StatusCancelled
+	case status == WaitlistedStatus:
+		result.Status = ical.StatusTentative
+	}
+
+	return result
+}
+
+func SearchRequestToFilter(dto *SearchEventsRequest, now time.Time) (*EventFilter, error) {
+	filter := EventFilter{
+		Query: strings.TrimSpace(dto.Query),
+		Limit: dto.Limit,
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultSearchLimit
+	}
+
+	if dto.CityID != 0 {
+		filter.CityID = &dto.CityID
+	}
+
+	for _, id := range dto.SportIDs {
+		sportID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, err
+		}
+		filter.SportIDs = append(filter.SportIDs, sportID)
+	}
+
+	if dto.From != "" {
+		from, err := time.Parse(time.RFC3339, dto.From)
+		if err != nil {
+			return nil, err
+		}
+		filter.From = &from
+	} else {
+		filter.From = &now
+	}
+
+	if dto.To != "" {
+		to, err := time.Parse(time.RFC3339, dto.To)
+		if err != nil {
+			return nil, err
+		}
+		filter.To = &to
+	}
+
+	if dto.Cursor != "" {
+		cursor, err := DecodeCursor(dto.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = cursor
+	}
+
+	return &filter, nil
+}
+
+func NearbyRequestToFilter(dto *NearbyEventsRequest, now time.Time) *NearbyFilter {
+	filter := NearbyFilter{
+		Latitude:  *dto.Latitude,
+		Longitude: *dto.Longitude,
+		RadiusKm:  dto.RadiusKm,
+		From:      now,
+		Limit:     dto.Limit,
+	}
+
+	if filter.RadiusKm == 0 {
+		filter.RadiusKm = defaultNearbyRadiusKm
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultNearbyLimit
+	}
+
+	return &filter
+}
+
+func EncodeCursor(event *Event) string {
+	raw := event.StartDate.UTC().Format(time.RFC3339Nano) + "|" + event.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(cursor string) (*EventCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("malformed cursor")
+	}
+
+	startDate, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &EventCursor{StartDate: startDate, ID: id}, nil
+}
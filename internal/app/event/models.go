@@ -1 +1,161 @@
 package event
+
+import (
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ParticipantStatus string
+
+const (
+	ConfirmedStatus  ParticipantStatus = "confirmed"
+	WaitlistedStatus ParticipantStatus = "waitlisted"
+)
+
+type Event struct {
+	ID                uuid.UUID  `db:"id"`
+	Title             string     `db:"title"`
+	Description       *string    `db:"description"`
+	StartDate         time.Time  `db:"start_date"`
+	EndDate           *time.Time `db:"end_date"`
+	CityID            int        `db:"city_id"`
+	Place             string     `db:"place"`
+	Latitude          *float64   `db:"latitude"`
+	Longitude         *float64   `db:"longitude"`
+	PhotoURL          *string    `db:"photo_url"`
+	SportID           uuid.UUID  `db:"sport_id"`
+	CreatorID         uuid.UUID  `db:"creator_id"`
+	MaxParticipants   *int       `db:"max_participants"`
+	MinReliability    *float64   `db:"min_reliability"`
+	ParticipantsCount int        `db:"participants_count"`
+	SeriesID          *uuid.UUID `db:"series_id"`
+	SeriesDetached    bool       `db:"series_detached"`
+	Sequence          int        `db:"sequence"`
+	CanceledAt        *time.Time `db:"canceled_at"`
+	CreatedAt         time.Time  `db:"created_at"`
+	UpdatedAt         time.Time  `db:"updated_at"`
+}
+
+func (e *Event) IsCanceled() bool {
+	return e.CanceledAt != nil
+}
+
+func (e *Event) HasStarted(now time.Time) bool {
+	return !e.StartDate.After(now)
+}
+
+func (e *Event) HasEnded(now time.Time) bool {
+	if e.EndDate == nil {
+		return e.HasStarted(now)
+	}
+	return !e.EndDate.After(now)
+}
+
+type EventParticipant struct {
+	EventID  uuid.UUID         `db:"event_id"`
+	UserID   uuid.UUID         `db:"user_id"`
+	Status   ParticipantStatus `db:"status"`
+	JoinedAt time.Time         `db:"joined_at"`
+}
+
+type EditScope string
+
+const (
+	ThisScope      EditScope = "this"
+	FollowingScope EditScope = "following"
+)
+
+type Frequency string
+
+const (
+	DailyFrequency   Frequency = "daily"
+	WeeklyFrequency  Frequency = "weekly"
+	MonthlyFrequency Frequency = "monthly"
+)
+
+func (f Frequency) IsValid() bool {
+	return f == DailyFrequency || f == WeeklyFrequency || f == MonthlyFrequency
+}
+
+type EventSeries struct {
+	ID        uuid.UUID  `db:"id"`
+	CreatorID uuid.UUID  `db:"creator_id"`
+	Frequency Frequency  `db:"frequency"`
+	Interval  int        `db:"interval"`
+	Weekdays  []string   `db:"weekdays"`
+	StartDate time.Time  `db:"start_date"`
+	Until     *time.Time `db:"until"`
+	Count     *int       `db:"count"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+type NearbyFilter struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+	From      time.Time
+	Limit     int
+}
+
+type NearbyEvent struct {
+	Event      *Event
+	DistanceKm float64
+}
+
+type EventCursor struct {
+	StartDate time.Time
+	ID        uuid.UUID
+}
+
+type EventFilter struct {
+	CityID   *int
+	SportIDs []uuid.UUID
+	From     *time.Time
+	To       *time.Time
+	Query    string
+	After    *EventCursor
+	Limit    int
+}
+
+type JoinedEvent struct {
+	Event  *Event
+	Status ParticipantStatus
+}
+
+type EventPhoto struct {
+	ID          uuid.UUID `db:"id"`
+	EventID     uuid.UUID `db:"event_id"`
+	UserID      uuid.UUID `db:"user_id"`
+	ObjectKey   string    `db:"object_key"`
+	ContentType string    `db:"content_type"`
+	Size        int64     `db:"size"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type CheckInCode struct {
+	Code      string
+	Token     string
+	ExpiresAt time.Time
+}
+
+// Matches accepts either the short code typed by a participant or the token
+// embedded in the QR code.
+func (c *CheckInCode) Matches(value string) bool {
+	value = strings.TrimSpace(value)
+	if subtle.ConstantTimeCompare([]byte(value), []byte(c.Token)) == 1 {
+		return true
+	}
+
+	return subtle.ConstantTimeCompare([]byte(strings.ToUpper(value)), []byte(c.Code)) == 1
+}
+
+type Attendance struct {
+	UserID      uuid.UUID
+	Status      ParticipantStatus
+	Attended    *bool
+	CheckedInAt *time.Time
+}
@@ -1 +1,73 @@
 package event
+
+import (
+	"log/slog"
+
+	"github.com/RuLap/sportmates-api/internal/app/chat"
+	"github.com/RuLap/sportmates-api/internal/app/profile"
+	"github.com/RuLap/sportmates-api/internal/app/refdata"
+	"github.com/RuLap/sportmates-api/internal/pkg/rabbitmq"
+	"github.com/RuLap/sportmates-api/internal/pkg/storage/minio"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Module struct {
+	eventRepo       EventRepository
+	participantRepo ParticipantRepository
+	seriesRepo      SeriesRepository
+	calendarRepo    CalendarRepository
+	photoRepo       PhotoRepository
+	attendanceRepo  AttendanceRepository
+	Service         Service
+	Handler         Handler
+}
+
+func NewModule(
+	log *slog.Logger,
+	pool *pgxpool.Pool,
+	rabbitmq *rabbitmq.Service,
+	minio *minio.Service,
+	refdataService refdata.Service,
+	profileService profile.Service,
+	chatService chat.Service,
+	publicURL string,
+) *Module {
+	eventRepo := NewEventRepository(pool)
+	participantRepo := NewParticipantRepository(pool)
+	seriesRepo := NewSeriesRepository(pool)
+	calendarRepo := NewCalendarRepository(pool)
+	photoRepo := NewPhotoRepository(pool)
+	attendanceRepo := NewAttendanceRepository(pool)
+
+	recommender := NewRecommender(DefaultRecommendationWeights)
+
+	service := NewService(
+		log,
+		rabbitmq,
+		minio,
+		eventRepo,
+		participantRepo,
+		seriesRepo,
+		calendarRepo,
+		photoRepo,
+		attendanceRepo,
+		recommender,
+		refdataService,
+		profileService,
+		chatService,
+		publicURL,
+	)
+
+	handler := NewHandler(log, service)
+
+	return &Module{
+		eventRepo:       eventRepo,
+		participantRepo: participantRepo,
+		seriesRepo:      seriesRepo,
+		calendarRepo:    calendarRepo,
+		photoRepo:       photoRepo,
+		attendanceRepo:  attendanceRepo,
+		Service:         service,
+		Handler:         *handler,
+	}
+}
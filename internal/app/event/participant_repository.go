@@ -0,0 +1,304 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrAlreadyJoined  = errors.New("user already joined the event")
+	ErrNotParticipant = errors.New("user is not a participant of the event")
+)
+
+type ParticipantRepository interface {
+	Join(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) (*EventParticipant, error)
+	Leave(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error)
+	PromoteWaitlisted(ctx context.Context, eventID uuid.UUID) ([]uuid.UUID, error)
+	GetWaitlistPosition(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) (int, error)
+	GetJoinedEvents(ctx context.Context, userID uuid.UUID, from time.Time) ([]*JoinedEvent, error)
+	GetStatus(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) (ParticipantStatus, error)
+}
+
+type participantRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewParticipantRepository(db *pgxpool.Pool) ParticipantRepository {
+	return &participantRepository{db: db}
+}
+
+// Join locks the event row so that concurrent joins are serialized and the
+// capacity check and insert happen atomically.
+func (r *participantRepository) Join(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) (*EventParticipant, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	capacity, err := r.lockEvent(ctx, tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if capacity.canceledAt != nil {
+		return nil, ErrEventCanceled
+	}
+
+	if !capacity.startDate.After(time.Now()) {
+		return nil, ErrEventStarted
+	}
+
+	status := ConfirmedStatus
+	if capacity.maxParticipants != nil && capacity.confirmed >= *capacity.maxParticipants {
+		status = WaitlistedStatus
+	}
+
+	participant := EventParticipant{
+		EventID: eventID,
+		UserID:  userID,
+		Status:  status,
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO event_participants (event_id, user_id, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+		RETURNING joined_at
+	`, eventID, userID, status).Scan(&participant.JoinedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAlreadyJoined
+		}
+		return nil, fmt.Errorf("failed to join event: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &participant, nil
+}
+
+func (r *participantRepository) Leave(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	capacity, err := r.lockEvent(ctx, tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	var status ParticipantStatus
+	err = tx.QueryRow(ctx, `
+		DELETE FROM event_participants
+		WHERE event_id = $1 AND user_id = $2
+		RETURNING status
+	`, eventID, userID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotParticipant
+		}
+		return nil, fmt.Errorf("failed to leave event: %w", err)
+	}
+
+	promoted := []uuid.UUID{}
+	if status == ConfirmedStatus && capacity.canceledAt == nil {
+		capacity.confirmed--
+		promoted, err = r.promote(ctx, tx, eventID, capacity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return promoted, nil
+}
+
+func (r *participantRepository) PromoteWaitlisted(ctx context.Context, eventID uuid.UUID) ([]uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	capacity, err := r.lockEvent(ctx, tx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if capacity.canceledAt != nil {
+		return []uuid.UUID{}, nil
+	}
+
+	promoted, err := r.promote(ctx, tx, eventID, capacity)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return promoted, nil
+}
+
+func (r *participantRepository) GetWaitlistPosition(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) (int, error) {
+	const query = `
+		SELECT count(*)
+		FROM event_participants w, event_participants me
+		WHERE me.event_id = $1 AND me.user_id = $2 AND me.status = 'waitlisted'
+			AND w.event_id = me.event_id AND w.status = 'waitlisted'
+			AND (w.joined_at, w.user_id) <= (me.joined_at, me.user_id)
+	`
+
+	var position int
+	err := r.db.QueryRow(ctx, query, eventID, userID).Scan(&position)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get waitlist position: %w", err)
+	}
+
+	return position, nil
+}
+
+type eventCapacity struct {
+	maxParticipants *int
+	confirmed       int
+	startDate       time.Time
+	canceledAt      *time.Time
+}
+
+func (r *participantRepository) lockEvent(ctx context.Context, tx pgx.Tx, eventID uuid.UUID) (*eventCapacity, error) {
+	var capacity eventCapacity
+	err := tx.QueryRow(ctx, `
+		SELECT max_participants, start_date, canceled_at
+		FROM events
+		WHERE id = $1
+		FOR UPDATE
+	`, eventID).Scan(&capacity.maxParticipants, &capacity.startDate, &capacity.canceledAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to lock event: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		SELECT count(*)
+		FROM event_participants
+		WHERE event_id = $1 AND status = 'confirmed'
+	`, eventID).Scan(&capacity.confirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count participants: %w", err)
+	}
+
+	return &capacity, nil
+}
+
+func (r *participantRepository) promote(ctx context.Context, tx pgx.Tx, eventID uuid.UUID, capacity *eventCapacity) ([]uuid.UUID, error) {
+	var limit *int
+	if capacity.maxParticipants != nil {
+		free := *capacity.maxParticipants - capacity.confirmed
+		if free <= 0 {
+			return []uuid.UUID{}, nil
+		}
+		limit = &free
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE event_participants
+		SET status = 'confirmed'
+		WHERE event_id = $1 AND user_id IN (
+			SELECT user_id
+			FROM event_participants
+			WHERE event_id = $1 AND status = 'waitlisted'
+			ORDER BY joined_at, user_id
+			LIMIT $2
+		)
+		RETURNING user_id
+	`, eventID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to promote waitlisted participants: %w", err)
+	}
+	defer rows.Close()
+
+	promoted := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return promoted, nil
+}
+
+// GetJoinedEvents returns the user's events starting after from, including
+// canceled ones, so that calendar clients can pick up cancellations.
+func (r *participantRepository) GetJoinedEvents(ctx context.Context, userID uuid.UUID, from time.Time) ([]*JoinedEvent, error) {
+	query := `
+		SELECT ` + eventColumns + `, p.status
+		FROM event_participants p
+		JOIN events e ON e.id = p.event_id
+		WHERE p.user_id = $1 AND e.start_date >= $2
+		ORDER BY e.start_date, e.id
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query joined events: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*JoinedEvent, 0)
+	for rows.Next() {
+		var event Event
+		var joined JoinedEvent
+		if err := rows.Scan(append(eventScanTargets(&event), &joined.Status)...); err != nil {
+			return nil, fmt.Errorf("failed to scan joined event: %w", err)
+		}
+		joined.Event = &event
+		result = append(result, &joined)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *participantRepository) GetStatus(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) (ParticipantStatus, error) {
+	const query = `SELECT status FROM event_participants WHERE event_id = $1 AND user_id = $2`
+
+	var status ParticipantStatus
+	err := r.db.QueryRow(ctx, query, eventID, userID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotParticipant
+		}
+		return "", fmt.Errorf("failed to get participant status: %w", err)
+	}
+
+	return status, nil
+}
@@ -0,0 +1,150 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrPhotoNotFound = errors.New("photo not found")
+	ErrPhotoExists   = errors.New("photo already exists")
+)
+
+type PhotoRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*EventPhoto, error)
+	GetByEventID(ctx context.Context, eventID uuid.UUID) ([]*EventPhoto, error)
+	CountByUser(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) (int, error)
+	Create(ctx context.Context, model *EventPhoto) (*EventPhoto, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type photoRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPhotoRepository(db *pgxpool.Pool) PhotoRepository {
+	return &photoRepository{db: db}
+}
+
+func (r *photoRepository) GetByID(ctx context.Context, id uuid.UUID) (*EventPhoto, error) {
+	const query = `
+		SELECT id, event_id, user_id, object_key, content_type, size, created_at
+		FROM event_photos
+		WHERE id = $1
+	`
+
+	var photo EventPhoto
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&photo.ID,
+		&photo.EventID,
+		&photo.UserID,
+		&photo.ObjectKey,
+		&photo.ContentType,
+		&photo.Size,
+		&photo.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPhotoNotFound
+		}
+		return nil, fmt.Errorf("failed to find photo by ID: %w", err)
+	}
+
+	return &photo, nil
+}
+
+func (r *photoRepository) GetByEventID(ctx context.Context, eventID uuid.UUID) ([]*EventPhoto, error) {
+	const query = `
+		SELECT id, event_id, user_id, object_key, content_type, size, created_at
+		FROM event_photos
+		WHERE event_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.Query(ctx, query, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event photos: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*EventPhoto, 0)
+	for rows.Next() {
+		var photo EventPhoto
+		err := rows.Scan(
+			&photo.ID,
+			&photo.EventID,
+			&photo.UserID,
+			&photo.ObjectKey,
+			&photo.ContentType,
+			&photo.Size,
+			&photo.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event photo: %w", err)
+		}
+		result = append(result, &photo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *photoRepository) CountByUser(ctx context.Context, eventID uuid.UUID, userID uuid.UUID) (int, error) {
+	const query = `SELECT count(*) FROM event_photos WHERE event_id = $1 AND user_id = $2`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, eventID, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count user photos: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *photoRepository) Create(ctx context.Context, model *EventPhoto) (*EventPhoto, error) {
+	const query = `
+		INSERT INTO event_photos (event_id, user_id, object_key, content_type, size)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		model.EventID,
+		model.UserID,
+		model.ObjectKey,
+		model.ContentType,
+		model.Size,
+	).Scan(&model.ID, &model.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrPhotoExists
+		}
+		return nil, fmt.Errorf("failed to create event photo: %w", err)
+	}
+
+	return model, nil
+}
+
+func (r *photoRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM event_photos WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete event photo: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrPhotoNotFound
+	}
+
+	return nil
+}
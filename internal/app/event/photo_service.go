@@ -0,0 +1,314 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	app_errors "github.com/RuLap/sportmates-api/internal/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const (
+	PhotosBucketName     = "sportmates-events"
+	maxPhotoSize         = 10 << 20
+	maxGalleryUserPhotos = 20
+)
+
+var allowedPhotoContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+var (
+	ErrPhotoNotUploaded   = errors.New("файл не загружен")
+	ErrPhotoTooLarge      = errors.New("размер файла не должен превышать 10 МБ")
+	ErrPhotoContentType   = errors.New("допустимы только изображения JPEG, PNG и WEBP")
+	ErrInvalidPhotoKey    = errors.New("неверный ключ файла")
+	ErrGalleryLimit       = errors.New("достигнут лимит фотографий для события")
+	ErrGalleryParticipant = errors.New("добавлять фотографии могут только участники события")
+)
+
+func (s *service) GetCoverUploadURL(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*GetPhotoUploadURLResponse, error) {
+	if _, err := s.getOwnedEvent(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.generatePhotoUploadURL(ctx, coverKeyPrefix(id)+uuid.NewString())
+}
+
+func (s *service) ConfirmCoverUpload(
+	ctx context.Context,
+	id uuid.UUID,
+	req *ConfirmPhotoUploadRequest,
+	userID uuid.UUID,
+) (*GetEventResponse, error) {
+	if _, err := s.getOwnedEvent(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(req.Key, coverKeyPrefix(id)) {
+		return nil, ErrInvalidPhotoKey
+	}
+
+	if _, _, err := s.verifyUploadedPhoto(ctx, req.Key); err != nil {
+		return nil, err
+	}
+
+	previous, err := s.eventRepo.SetPhoto(ctx, id, &req.Key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrEventCanceled
+		}
+		s.log.Error("failed to set event cover", "event_id", id, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	if previous != nil && *previous != req.Key {
+		s.deletePhotoObject(ctx, *previous)
+	}
+
+	s.log.Info("event cover updated", "event_id", id, "user_id", userID)
+
+	return s.GetByID(ctx, id)
+}
+
+func (s *service) DeleteCover(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	if _, err := s.getOwnedEvent(ctx, id, userID); err != nil {
+		return err
+	}
+
+	previous, err := s.eventRepo.SetPhoto(ctx, id, nil)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrEventCanceled
+		}
+		s.log.Error("failed to delete event cover", "event_id", id, "error", err)
+		return fmt.Errorf(app_errors.ErrFailedToDeleteData)
+	}
+
+	if previous != nil {
+		s.deletePhotoObject(ctx, *previous)
+	}
+
+	return nil
+}
+
+func (s *service) GetPhotos(ctx context.Context, id uuid.UUID) ([]*GetEventPhotoResponse, error) {
+	if _, err := s.eventRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	photos, err := s.photoRepo.GetByEventID(ctx, id)
+	if err != nil {
+		s.log.Error("failed to load event photos", "event_id", id, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	result := make([]*GetEventPhotoResponse, 0, len(photos))
+	for _, photo := range photos {
+		url, err := s.getPhotoDownloadURL(ctx, photo.ObjectKey)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, PhotoToGetResponse(photo, url))
+	}
+
+	return result, nil
+}
+
+func (s *service) GetGalleryUploadURL(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*GetPhotoUploadURLResponse, error) {
+	if err := s.checkGalleryAccess(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.generatePhotoUploadURL(ctx, galleryKeyPrefix(id, userID)+uuid.NewString())
+}
+
+func (s *service) ConfirmGalleryUpload(
+	ctx context.Context,
+	id uuid.UUID,
+	req *ConfirmPhotoUploadRequest,
+	userID uuid.UUID,
+) (*GetEventPhotoResponse, error) {
+	if err := s.checkGalleryAccess(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(req.Key, galleryKeyPrefix(id, userID)) {
+		return nil, ErrInvalidPhotoKey
+	}
+
+	contentType, size, err := s.verifyUploadedPhoto(ctx, req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	photo, err := s.photoRepo.Create(ctx, &EventPhoto{
+		EventID:     id,
+		UserID:      userID,
+		ObjectKey:   req.Key,
+		ContentType: contentType,
+		Size:        size,
+	})
+	if err != nil {
+		if errors.Is(err, ErrPhotoExists) {
+			return nil, err
+		}
+		s.log.Error("failed to save event photo", "event_id", id, "user_id", userID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	url, err := s.getPhotoDownloadURL(ctx, photo.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("event photo added", "event_id", id, "photo_id", photo.ID, "user_id", userID)
+
+	return PhotoToGetResponse(photo, url), nil
+}
+
+func (s *service) DeletePhoto(ctx context.Context, id uuid.UUID, photoID uuid.UUID, userID uuid.UUID) error {
+	photo, err := s.photoRepo.GetByID(ctx, photoID)
+	if err != nil {
+		return err
+	}
+
+	if photo.EventID != id {
+		return ErrPhotoNotFound
+	}
+
+	if photo.UserID != userID {
+		event, err := s.eventRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if event.CreatorID != userID {
+			s.log.Warn("attempt to delete foreign photo", "photo_id", photoID, "user_id", userID)
+			return ErrAccessDenied
+		}
+	}
+
+	if err := s.photoRepo.Delete(ctx, photoID); err != nil {
+		if errors.Is(err, ErrPhotoNotFound) {
+			return err
+		}
+		s.log.Error("failed to delete event photo", "photo_id", photoID, "error", err)
+		return fmt.Errorf(app_errors.ErrFailedToDeleteData)
+	}
+
+	s.deletePhotoObject(ctx, photo.ObjectKey)
+
+	s.log.Info("event photo deleted", "event_id", id, "photo_id", photoID, "user_id", userID)
+
+	return nil
+}
+
+func (s *service) checkGalleryAccess(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	status, err := s.participantRepo.GetStatus(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotParticipant) {
+			if _, err := s.eventRepo.GetByID(ctx, id); err != nil {
+				return err
+			}
+			return ErrGalleryParticipant
+		}
+		s.log.Error("failed to check participant status", "event_id", id, "user_id", userID, "error", err)
+		return fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	if status != ConfirmedStatus {
+		return ErrGalleryParticipant
+	}
+
+	count, err := s.photoRepo.CountByUser(ctx, id, userID)
+	if err != nil {
+		s.log.Error("failed to count user photos", "event_id", id, "user_id", userID, "error", err)
+		return fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	if count >= maxGalleryUserPhotos {
+		return ErrGalleryLimit
+	}
+
+	return nil
+}
+
+func (s *service) generatePhotoUploadURL(ctx context.Context, s3key string) (*GetPhotoUploadURLResponse, error) {
+	if err := s.minio.EnsureBucket(ctx, PhotosBucketName); err != nil {
+		s.log.Error("failed to ensure photos bucket", "bucket", PhotosBucketName, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	uploadURL, err := s.minio.GenerateUploadURL(ctx, PhotosBucketName, s3key)
+	if err != nil {
+		s.log.Error("failed to generate upload url", "objName", s3key, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	return &GetPhotoUploadURLResponse{
+		URL: uploadURL,
+		Key: s3key,
+	}, nil
+}
+
+// verifyUploadedPhoto checks the object uploaded through a presigned URL.
+// Presigned PUT URLs cannot restrict the payload, so objects that violate the
+// limits are removed here.
+func (s *service) verifyUploadedPhoto(ctx context.Context, s3key string) (string, int64, error) {
+	exists, err := s.minio.FileExists(ctx, PhotosBucketName, s3key)
+	if err != nil {
+		s.log.Error("failed to check uploaded photo", "objName", s3key, "error", err)
+		return "", 0, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	if !exists {
+		return "", 0, ErrPhotoNotUploaded
+	}
+
+	info, err := s.minio.GetFileInfo(ctx, PhotosBucketName, s3key)
+	if err != nil {
+		s.log.Error("failed to get uploaded photo info", "objName", s3key, "error", err)
+		return "", 0, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	if info.Size > maxPhotoSize {
+		s.deletePhotoObject(ctx, s3key)
+		return "", 0, ErrPhotoTooLarge
+	}
+
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(info.ContentType, ";")[0]))
+	if !allowedPhotoContentTypes[contentType] {
+		s.deletePhotoObject(ctx, s3key)
+		return "", 0, ErrPhotoContentType
+	}
+
+	return contentType, info.Size, nil
+}
+
+func (s *service) getPhotoDownloadURL(ctx context.Context, s3key string) (string, error) {
+	downloadURL, err := s.minio.GenerateDownloadURL(ctx, PhotosBucketName, s3key, "photo")
+	if err != nil {
+		s.log.Error("failed to generate download URL", "objName", s3key, "error", err)
+		return "", fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	return downloadURL, nil
+}
+
+func (s *service) deletePhotoObject(ctx context.Context, s3key string) {
+	if err := s.minio.DeleteFile(ctx, PhotosBucketName, s3key); err != nil {
+		s.log.Error("failed to delete photo object", "objName", s3key, "error", err)
+	}
+}
+
+func coverKeyPrefix(eventID uuid.UUID) string {
+	return fmt.Sprintf("events/%s/cover/", eventID)
+}
+
+func galleryKeyPrefix(eventID uuid.UUID, userID uuid.UUID) string {
+	return fmt.Sprintf("events/%s/gallery/%s/", eventID, userID)
+}
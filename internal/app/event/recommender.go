@@ -0,0 +1,100 @@
+package event
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type RecommendationWeights struct {
+	Sport           float64
+	City            float64
+	Region          float64
+	Acquaintance    float64
+	MaxAcquaintance int
+}
+
+var DefaultRecommendationWeights = RecommendationWeights{
+	Sport:           100,
+	City:            50,
+	Region:          20,
+	Acquaintance:    10,
+	MaxAcquaintance: 5,
+}
+
+type RecommendationProfile struct {
+	SportIDs []uuid.UUID
+	CityID   int
+	RegionID int
+}
+
+type RecommendationCandidate struct {
+	Event         *Event
+	RegionID      int
+	Acquaintances int
+}
+
+type ScoredEvent struct {
+	Event *Event
+	Score float64
+}
+
+type Recommender struct {
+	weights RecommendationWeights
+}
+
+func NewRecommender(weights RecommendationWeights) *Recommender {
+	return &Recommender{weights: weights}
+}
+
+func (r *Recommender) Score(profile *RecommendationProfile, candidate *RecommendationCandidate) float64 {
+	var score float64
+
+	for _, sportID := range profile.SportIDs {
+		if candidate.Event.SportID == sportID {
+			score += r.weights.Sport
+			break
+		}
+	}
+
+	switch {
+	case profile.CityID != 0 && candidate.Event.CityID == profile.CityID:
+		score += r.weights.City
+	case profile.RegionID != 0 && candidate.RegionID == profile.RegionID:
+		score += r.weights.Region
+	}
+
+	acquaintances := candidate.Acquaintances
+	if acquaintances > r.weights.MaxAcquaintance {
+		acquaintances = r.weights.MaxAcquaintance
+	}
+	score += float64(acquaintances) * r.weights.Acquaintance
+
+	return score
+}
+
+// Rank orders candidates by score, breaking ties by the earliest start date,
+// and drops candidates that match nothing in the profile.
+func (r *Recommender) Rank(profile *RecommendationProfile, candidates []*RecommendationCandidate, limit int) []*ScoredEvent {
+	scored := make([]*ScoredEvent, 0, len(candidates))
+	for _, candidate := range candidates {
+		score := r.Score(profile, candidate)
+		if score <= 0 {
+			continue
+		}
+		scored = append(scored, &ScoredEvent{Event: candidate.Event, Score: score})
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score > scored[j].Score
+		}
+		return scored[i].Event.StartDate.Before(scored[j].Event.StartDate)
+	})
+
+	if limit > 0 && len(scored) > limit {
+		scored = scored[:limit]
+	}
+
+	return scored
+}
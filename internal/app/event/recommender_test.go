@@ -0,0 +1,260 @@
+package event
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/app/profile"
+	"github.com/RuLap/sportmates-api/internal/app/refdata"
+	"github.com/google/uuid"
+)
+
+var (
+	football = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	tennis   = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	chess    = uuid.MustParse("00000000-0000-0000-0000-000000000003")
+)
+
+const (
+	moscow          = 1
+	moscowRegion    = 10
+	podolsk         = 2
+	kazan           = 3
+	tatarstanRegion = 20
+)
+
+var testStart = time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+func candidate(sportID uuid.UUID, cityID, regionID, acquaintances int, start time.Time) *RecommendationCandidate {
+	return &RecommendationCandidate{
+		Event: &Event{
+			ID:        uuid.New(),
+			SportID:   sportID,
+			CityID:    cityID,
+			StartDate: start,
+		},
+		RegionID:      regionID,
+		Acquaintances: acquaintances,
+	}
+}
+
+func TestRecommenderScore(t *testing.T) {
+	recommender := NewRecommender(DefaultRecommendationWeights)
+	profile := &RecommendationProfile{
+		SportIDs: []uuid.UUID{football, tennis},
+		CityID:   moscow,
+		RegionID: moscowRegion,
+	}
+
+	tests := []struct {
+		name      string
+		candidate *RecommendationCandidate
+		profile   *RecommendationProfile
+		want      float64
+	}{
+		{"sport and city", candidate(football, moscow, moscowRegion, 0, testStart), profile, 150},
+		{"second sport of the profile", candidate(tennis, moscow, moscowRegion, 0, testStart), profile, 150},
+		{"city takes precedence over region", candidate(chess, moscow, moscowRegion, 0, testStart), profile, 50},
+		{"region when the city differs", candidate(chess, podolsk, moscowRegion, 0, testStart), profile, 20},
+		{"sport and region", candidate(football, podolsk, moscowRegion, 0, testStart), profile, 120},
+		{"sport in another region", candidate(football, kazan, tatarstanRegion, 0, testStart), profile, 100},
+		{"nothing matches", candidate(chess, kazan, tatarstanRegion, 0, testStart), profile, 0},
+		{"acquaintances", candidate(chess, kazan, tatarstanRegion, 3, testStart), profile, 30},
+		{"acquaintances at the cap", candidate(chess, kazan, tatarstanRegion, 5, testStart), profile, 50},
+		{"acquaintances above the cap", candidate(chess, kazan, tatarstanRegion, 40, testStart), profile, 50},
+		{"everything", candidate(football, moscow, moscowRegion, 7, testStart), profile, 200},
+		{
+			name:      "unknown city does not match events without one",
+			candidate: candidate(chess, 0, 0, 0, testStart),
+			profile:   &RecommendationProfile{SportIDs: []uuid.UUID{football}},
+			want:      0,
+		},
+		{
+			name:      "region only profile",
+			candidate: candidate(chess, podolsk, moscowRegion, 0, testStart),
+			profile:   &RecommendationProfile{RegionID: moscowRegion},
+			want:      20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recommender.Score(tt.profile, tt.candidate); got != tt.want {
+				t.Errorf("Score() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecommenderScoreCustomCap(t *testing.T) {
+	weights := DefaultRecommendationWeights
+	weights.MaxAcquaintance = 2
+	recommender := NewRecommender(weights)
+
+	got := recommender.Score(&RecommendationProfile{}, candidate(chess, kazan, tatarstanRegion, 4, testStart))
+	if got != 20 {
+		t.Errorf("Score() = %v, want 20", got)
+	}
+}
+
+func TestRecommenderRank(t *testing.T) {
+	recommender := NewRecommender(DefaultRecommendationWeights)
+	profile := &RecommendationProfile{
+		SportIDs: []uuid.UUID{football},
+		CityID:   moscow,
+		RegionID: moscowRegion,
+	}
+
+	sportAndCity := candidate(football, moscow, moscowRegion, 0, testStart.Add(48*time.Hour))
+	sportAndCityEarlier := candidate(football, moscow, moscowRegion, 0, testStart)
+	sportOnly := candidate(football, kazan, tatarstanRegion, 0, testStart)
+	cityOnly := candidate(chess, moscow, moscowRegion, 0, testStart)
+	regionOnly := candidate(chess, podolsk, moscowRegion, 0, testStart)
+	noMatch := candidate(chess, kazan, tatarstanRegion, 0, testStart)
+
+	candidates := []*RecommendationCandidate{noMatch, regionOnly, cityOnly, sportOnly, sportAndCity, sportAndCityEarlier}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []*RecommendationCandidate
+	}{
+		{
+			name:  "ordered by score, then start date",
+			limit: 0,
+			want:  []*RecommendationCandidate{sportAndCityEarlier, sportAndCity, sportOnly, cityOnly, regionOnly},
+		},
+		{
+			name:  "limited",
+			limit: 2,
+			want:  []*RecommendationCandidate{sportAndCityEarlier, sportAndCity},
+		},
+		{
+			name:  "limit above the number of matches",
+			limit: 10,
+			want:  []*RecommendationCandidate{sportAndCityEarlier, sportAndCity, sportOnly, cityOnly, regionOnly},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := recommender.Rank(profile, candidates, tt.limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Rank() returned %d events, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].Event != tt.want[i].Event {
+					t.Errorf("Rank()[%d] = %s (score %v), want %s", i, got[i].Event.ID, got[i].Score, tt.want[i].Event.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestRecommenderRankDropsZeroScores(t *testing.T) {
+	recommender := NewRecommender(DefaultRecommendationWeights)
+	profile := &RecommendationProfile{SportIDs: []uuid.UUID{football}, CityID: moscow}
+
+	candidates := []*RecommendationCandidate{
+		candidate(chess, kazan, tatarstanRegion, 0, testStart),
+		candidate(tennis, podolsk, moscowRegion, 0, testStart),
+	}
+
+	if got := recommender.Rank(profile, candidates, 10); len(got) != 0 {
+		t.Errorf("Rank() returned %d events, want none", len(got))
+	}
+
+	if got := recommender.Rank(profile, nil, 10); len(got) != 0 {
+		t.Errorf("Rank() of no candidates returned %d events", len(got))
+	}
+}
+
+func TestRecommenderRankAcquaintancesOutweighStartDate(t *testing.T) {
+	recommender := NewRecommender(DefaultRecommendationWeights)
+	profile := &RecommendationProfile{SportIDs: []uuid.UUID{football}, CityID: moscow}
+
+	// Acquaintances, even capped, outweigh an earlier start date.
+	earlier := candidate(football, moscow, moscowRegion, 0, testStart)
+	withFriends := candidate(football, moscow, moscowRegion, 10, testStart.Add(time.Hour))
+
+	got := recommender.Rank(profile, []*RecommendationCandidate{earlier, withFriends}, 0)
+	if len(got) != 2 || got[0].Event != withFriends.Event || got[0].Score != 200 {
+		t.Fatalf("Rank() = %+v, want the event with acquaintances first with score 200", got)
+	}
+}
+
+type fakeCandidateRepository struct {
+	EventRepository
+	candidates []*RecommendationCandidate
+}
+
+func (r *fakeCandidateRepository) GetRecommendationCandidates(ctx context.Context, userID uuid.UUID, profile *RecommendationProfile) ([]*RecommendationCandidate, error) {
+	return r.candidates, nil
+}
+
+type fakeProfileService struct {
+	profile.Service
+	profile *profile.GetProfileResponse
+}
+
+func (s *fakeProfileService) GetUserByID(ctx context.Context, id uuid.UUID) (*profile.GetProfileResponse, error) {
+	return s.profile, nil
+}
+
+type fakeRefdataService struct {
+	refdata.Service
+}
+
+func (s *fakeRefdataService) GetCityByID(ctx context.Context, id int) (*refdata.GetCityResponse, error) {
+	return &refdata.GetCityResponse{ID: id}, nil
+}
+
+func (s *fakeRefdataService) GetSportByID(ctx context.Context, id string) (*refdata.GetSportResponse, error) {
+	return &refdata.GetSportResponse{ID: id}, nil
+}
+
+func (s *fakeRefdataService) GetSportsByIDs(ctx context.Context, ids []string) ([]*refdata.GetSportResponse, error) {
+	return nil, nil
+}
+
+func TestGetRecommendedRanksLateEvents(t *testing.T) {
+	// Hundreds of region-only events start before the one event that matches
+	// the sport, the city and has acquaintances.
+	var candidates []*RecommendationCandidate
+	for i := 0; i < 500; i++ {
+		candidates = append(candidates, candidate(chess, podolsk, moscowRegion, 0, testStart.Add(time.Duration(i)*time.Hour)))
+	}
+	best := candidate(football, moscow, moscowRegion, 3, testStart.Add(365*24*time.Hour))
+	candidates = append(candidates, best)
+
+	userProfile := &profile.GetProfileResponse{
+		City:   refdata.GetCityResponse{ID: moscow, Region: refdata.GetRegionResponse{ID: moscowRegion}},
+		Sports: []refdata.GetSportResponse{{ID: football.String()}},
+	}
+
+	service := NewService(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		nil, nil,
+		&fakeCandidateRepository{candidates: candidates},
+		nil, nil, nil, nil, nil,
+		NewRecommender(DefaultRecommendationWeights),
+		&fakeRefdataService{},
+		&fakeProfileService{profile: userProfile},
+		nil,
+		"https://sportmates.test",
+	)
+
+	got, err := service.GetRecommended(context.Background(), uuid.New(), 5)
+	if err != nil {
+		t.Fatalf("GetRecommended() error = %v", err)
+	}
+	if len(got) != 5 {
+		t.Fatalf("GetRecommended() returned %d events, want 5", len(got))
+	}
+	if got[0].Event.ID != best.Event.ID.String() || got[0].Score != 180 {
+		t.Errorf("GetRecommended()[0] = %s (score %v), want %s with score 180", got[0].Event.ID, got[0].Score, best.Event.ID)
+	}
+}
@@ -0,0 +1,165 @@
+package event
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+const (
+	maxSeriesOccurrences = 100
+	maxRecurrenceSteps   = 5000
+)
+
+var (
+	ErrInvalidRecurrence  = errors.New("неверное правило повторения")
+	ErrTooManyOccurrences = errors.New("слишком много повторений, уменьшите count или until")
+)
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+type RecurrenceRule struct {
+	Frequency Frequency
+	Interval  int
+	Weekdays  []time.Weekday
+	Until     *time.Time
+	Count     *int
+}
+
+func SeriesToRule(series *EventSeries) (*RecurrenceRule, error) {
+	rule := RecurrenceRule{
+		Frequency: series.Frequency,
+		Interval:  series.Interval,
+		Until:     series.Until,
+		Count:     series.Count,
+	}
+
+	for _, code := range series.Weekdays {
+		weekday, ok := weekdayCodes[code]
+		if !ok {
+			return nil, ErrInvalidRecurrence
+		}
+		rule.Weekdays = append(rule.Weekdays, weekday)
+	}
+
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *RecurrenceRule) Validate() error {
+	if !r.Frequency.IsValid() || r.Interval < 1 {
+		return ErrInvalidRecurrence
+	}
+
+	if r.Until == nil && r.Count == nil {
+		return ErrInvalidRecurrence
+	}
+
+	if r.Count != nil && *r.Count < 1 {
+		return ErrInvalidRecurrence
+	}
+
+	if len(r.Weekdays) > 0 && r.Frequency != WeeklyFrequency {
+		return ErrInvalidRecurrence
+	}
+
+	return nil
+}
+
+// Occurrences expands the rule starting at start, which is always the first
+// occurrence. Wall-clock time is kept in start's location, so weekly games
+// stay at the same local hour.
+func (r *RecurrenceRule) Occurrences(start time.Time) ([]time.Time, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := []time.Time{}
+	add := func(t time.Time) (bool, error) {
+		if t.Before(start) {
+			return false, nil
+		}
+		if r.Until != nil && t.After(*r.Until) {
+			return true, nil
+		}
+
+		result = append(result, t)
+		if len(result) > maxSeriesOccurrences {
+			return true, ErrTooManyOccurrences
+		}
+
+		return r.Count != nil && len(result) >= *r.Count, nil
+	}
+
+	switch r.Frequency {
+	case DailyFrequency:
+		for i := 0; i < maxRecurrenceSteps; i++ {
+			if done, err := add(start.AddDate(0, 0, i*r.Interval)); done || err != nil {
+				return result, err
+			}
+		}
+	case WeeklyFrequency:
+		weekdays := r.sortedWeekdays(start)
+		weekStart := start.AddDate(0, 0, -mondayOffset(start.Weekday()))
+		for i := 0; i < maxRecurrenceSteps; i++ {
+			base := weekStart.AddDate(0, 0, 7*i*r.Interval)
+			for _, weekday := range weekdays {
+				if done, err := add(base.AddDate(0, 0, mondayOffset(weekday))); done || err != nil {
+					return result, err
+				}
+			}
+		}
+	case MonthlyFrequency:
+		for i := 0; i < maxRecurrenceSteps; i++ {
+			t := time.Date(
+				start.Year(), start.Month()+time.Month(i*r.Interval), start.Day(),
+				start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location(),
+			)
+			if t.Day() != start.Day() {
+				continue
+			}
+			if done, err := add(t); done || err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (r *RecurrenceRule) sortedWeekdays(start time.Time) []time.Weekday {
+	if len(r.Weekdays) == 0 {
+		return []time.Weekday{start.Weekday()}
+	}
+
+	seen := make(map[time.Weekday]struct{}, len(r.Weekdays))
+	weekdays := make([]time.Weekday, 0, len(r.Weekdays))
+	for _, weekday := range r.Weekdays {
+		if _, ok := seen[weekday]; ok {
+			continue
+		}
+		seen[weekday] = struct{}{}
+		weekdays = append(weekdays, weekday)
+	}
+
+	sort.Slice(weekdays, func(i, j int) bool {
+		return mondayOffset(weekdays[i]) < mondayOffset(weekdays[j])
+	})
+
+	return weekdays
+}
+
+func mondayOffset(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
@@ -0,0 +1,204 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrSeriesNotFound = errors.New("event series not found")
+)
+
+type SeriesRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*EventSeries, error)
+	Create(ctx context.Context, series *EventSeries, occurrences []*Event) (*EventSeries, error)
+	GetFollowingEvents(ctx context.Context, seriesID uuid.UUID, from time.Time) ([]*Event, error)
+	CountEventsBefore(ctx context.Context, seriesID uuid.UUID, before time.Time) (int, error)
+	UpdateFollowing(ctx context.Context, series *EventSeries, split *EventSeries, occurrences []*Event) error
+	CancelFollowing(ctx context.Context, seriesID uuid.UUID, from time.Time) (int64, error)
+}
+
+type seriesRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSeriesRepository(db *pgxpool.Pool) SeriesRepository {
+	return &seriesRepository{db: db}
+}
+
+func (r *seriesRepository) GetByID(ctx context.Context, id uuid.UUID) (*EventSeries, error) {
+	const query = `
+		SELECT id, creator_id, frequency, "interval", weekdays, start_date, until, "count", created_at, updated_at
+		FROM event_series
+		WHERE id = $1
+	`
+
+	var series EventSeries
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&series.ID,
+		&series.CreatorID,
+		&series.Frequency,
+		&series.Interval,
+		&series.Weekdays,
+		&series.StartDate,
+		&series.Until,
+		&series.Count,
+		&series.CreatedAt,
+		&series.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSeriesNotFound
+		}
+		return nil, fmt.Errorf("failed to find event series by ID: %w", err)
+	}
+
+	return &series, nil
+}
+
+func (r *seriesRepository) Create(ctx context.Context, series *EventSeries, occurrences []*Event) (*EventSeries, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = insertSeries(ctx, tx, series)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, occurrence := range occurrences {
+		occurrence.SeriesID = &series.ID
+		if err := insertEvent(ctx, tx, occurrence); err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func (r *seriesRepository) GetFollowingEvents(ctx context.Context, seriesID uuid.UUID, from time.Time) ([]*Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events e
+		WHERE e.series_id = $1 AND e.start_date >= $2 AND e.canceled_at IS NULL
+		ORDER BY e.start_date, e.id
+	`
+
+	rows, err := r.db.Query(ctx, query, seriesID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query series events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+func (r *seriesRepository) CountEventsBefore(ctx context.Context, seriesID uuid.UUID, before time.Time) (int, error) {
+	const query = `
+		SELECT count(*)
+		FROM events
+		WHERE series_id = $1 AND start_date < $2
+	`
+
+	var count int
+	err := r.db.QueryRow(ctx, query, seriesID, before).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count series events: %w", err)
+	}
+
+	return count, nil
+}
+
+// UpdateFollowing saves the truncated series, inserts the split-off series
+// (if any) and moves the given occurrences into it, all in one transaction.
+func (r *seriesRepository) UpdateFollowing(ctx context.Context, series *EventSeries, split *EventSeries, occurrences []*Event) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE event_series
+		SET start_date = $2, until = $3, "count" = $4, updated_at = now()
+		WHERE id = $1
+	`, series.ID, series.StartDate, series.Until, series.Count)
+	if err != nil {
+		return fmt.Errorf("failed to update event series: %w", err)
+	}
+
+	if split != nil {
+		if err := insertSeries(ctx, tx, split); err != nil {
+			return err
+		}
+	}
+
+	for _, occurrence := range occurrences {
+		if split != nil {
+			occurrence.SeriesID = &split.ID
+		}
+		if err := updateEvent(ctx, tx, occurrence); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *seriesRepository) CancelFollowing(ctx context.Context, seriesID uuid.UUID, from time.Time) (int64, error) {
+	const query = `
+		UPDATE events
+		SET canceled_at = now(), updated_at = now(), "sequence" = "sequence" + 1
+		WHERE series_id = $1 AND start_date >= $2 AND canceled_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, seriesID, from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel series events: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func insertSeries(ctx context.Context, tx pgx.Tx, series *EventSeries) error {
+	const query = `
+		INSERT INTO event_series (creator_id, frequency, "interval", weekdays, start_date, until, "count")
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	weekdays := series.Weekdays
+	if weekdays == nil {
+		weekdays = []string{}
+	}
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		series.CreatorID,
+		series.Frequency,
+		series.Interval,
+		weekdays,
+		series.StartDate,
+		series.Until,
+		series.Count,
+	).Scan(&series.ID, &series.CreatedAt, &series.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create event series: %w", err)
+	}
+
+	return nil
+}
@@ -1 +1,909 @@
 package event
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/app/chat"
+	"github.com/RuLap/sportmates-api/internal/app/profile"
+	"github.com/RuLap/sportmates-api/internal/app/refdata"
+	app_errors "github.com/RuLap/sportmates-api/internal/pkg/errors"
+	"github.com/RuLap/sportmates-api/internal/pkg/events"
+	"github.com/RuLap/sportmates-api/internal/pkg/ical"
+	"github.com/RuLap/sportmates-api/internal/pkg/rabbitmq"
+	"github.com/RuLap/sportmates-api/internal/pkg/storage/minio"
+	"github.com/google/uuid"
+)
+
+const (
+	calendarFeedHistory   = 90 * 24 * time.Hour
+	calendarFeedURLFormat = "%s/calendar/%s.ics"
+	calendarProdID        = "-//SportMates//SportMates API//RU"
+)
+
+var (
+	ErrAccessDenied  = errors.New(app_errors.ErrAccessDenied)
+	ErrEventCanceled = errors.New("событие отменено")
+	ErrInvalidDates  = errors.New("дата окончания должна быть позже даты начала")
+	ErrInvalidCity   = errors.New("город не найден")
+	ErrInvalidSport  = errors.New("вид спорта не найден")
+	ErrEventStarted  = errors.New("событие уже началось")
+	ErrCreatorLeave  = errors.New("организатор не может покинуть событие, его можно только отменить")
+	ErrCapacityLow   = errors.New("количество мест не может быть меньше числа участников")
+	ErrInvalidFilter = errors.New("неверные параметры поиска")
+)
+
+type Service interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*GetEventResponse, error)
+	Create(ctx context.Context, req *CreateEventRequest, creatorID uuid.UUID) (*GetEventResponse, error)
+	Update(ctx context.Context, id uuid.UUID, req *UpdateEventRequest, userID uuid.UUID) (*GetEventResponse, error)
+	Cancel(ctx context.Context, id uuid.UUID, userID uuid.UUID, scope EditScope) error
+	ModerateCancel(ctx context.Context, id uuid.UUID, moderatorID uuid.UUID, scope EditScope) error
+
+	Join(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*JoinEventResponse, error)
+	Leave(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+
+	Search(ctx context.Context, req *SearchEventsRequest) (*SearchEventsResponse, error)
+	GetRecommended(ctx context.Context, userID uuid.UUID, limit int) ([]*RecommendedEventResponse, error)
+	GetNearby(ctx context.Context, req *NearbyEventsRequest) ([]*NearbyEventResponse, error)
+
+	GetCalendar(ctx context.Context, id uuid.UUID) ([]byte, error)
+	GetCalendarFeed(ctx context.Context, token string) ([]byte, error)
+	GetCalendarFeedURL(ctx context.Context, userID uuid.UUID) (*CalendarFeedResponse, error)
+	RotateCalendarFeedURL(ctx context.Context, userID uuid.UUID) (*CalendarFeedResponse, error)
+
+	GetCoverUploadURL(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*GetPhotoUploadURLResponse, error)
+	ConfirmCoverUpload(ctx context.Context, id uuid.UUID, req *ConfirmPhotoUploadRequest, userID uuid.UUID) (*GetEventResponse, error)
+	DeleteCover(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	GetPhotos(ctx context.Context, id uuid.UUID) ([]*GetEventPhotoResponse, error)
+	GetGalleryUploadURL(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*GetPhotoUploadURLResponse, error)
+	ConfirmGalleryUpload(ctx context.Context, id uuid.UUID, req *ConfirmPhotoUploadRequest, userID uuid.UUID) (*GetEventPhotoResponse, error)
+	DeletePhoto(ctx context.Context, id uuid.UUID, photoID uuid.UUID, userID uuid.UUID) error
+
+	CreateCheckInCode(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*CheckInCodeResponse, error)
+	CheckIn(ctx context.Context, id uuid.UUID, req *CheckInRequest, userID uuid.UUID) error
+	GetAttendance(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]*AttendanceResponse, error)
+	SetAttendance(ctx context.Context, id uuid.UUID, req *SetAttendanceRequest, userID uuid.UUID) ([]*AttendanceResponse, error)
+}
+
+type service struct {
+	log             *slog.Logger
+	rabbitmq        *rabbitmq.Service
+	minio           *minio.Service
+	eventRepo       EventRepository
+	participantRepo ParticipantRepository
+	seriesRepo      SeriesRepository
+	calendarRepo    CalendarRepository
+	photoRepo       PhotoRepository
+	attendanceRepo  AttendanceRepository
+	recommender     *Recommender
+	refdataService  refdata.Service
+	profileService  profile.Service
+	chatService     chat.Service
+	publicURL       string
+}
+
+func NewService(
+	log *slog.Logger,
+	rabbitmq *rabbitmq.Service,
+	minio *minio.Service,
+	eventRepo EventRepository,
+	participantRepo ParticipantRepository,
+	seriesRepo SeriesRepository,
+	calendarRepo CalendarRepository,
+	photoRepo PhotoRepository,
+	attendanceRepo AttendanceRepository,
+	recommender *Recommender,
+	refdataService refdata.Service,
+	profileService profile.Service,
+	chatService chat.Service,
+	publicURL string,
+) Service {
+	return &service{
+		log:             log,
+		rabbitmq:        rabbitmq,
+		minio:           minio,
+		eventRepo:       eventRepo,
+		participantRepo: participantRepo,
+		seriesRepo:      seriesRepo,
+		calendarRepo:    calendarRepo,
+		photoRepo:       photoRepo,
+		attendanceRepo:  attendanceRepo,
+		recommender:     recommender,
+		refdataService:  refdataService,
+		profileService:  profileService,
+		chatService:     chatService,
+		publicURL:       publicURL,
+	}
+}
+
+func (s *service) GetByID(ctx context.Context, id uuid.UUID) (*GetEventResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(ctx, event)
+}
+
+func (s *service) Create(ctx context.Context, req *CreateEventRequest, creatorID uuid.UUID) (*GetEventResponse, error) {
+	event, err := CreateRequestToEvent(req)
+	if err != nil {
+		return nil, fmt.Errorf(app_errors.ErrInvalidData)
+	}
+	event.CreatorID = creatorID
+
+	if err := s.validateEvent(ctx, event); err != nil {
+		return nil, err
+	}
+
+	if req.Recurrence != nil {
+		return s.createSeries(ctx, event, req.Recurrence)
+	}
+
+	result, err := s.eventRepo.Create(ctx, event)
+	if err != nil {
+		s.log.Error("failed to create event", "creator_id", creatorID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	s.log.Info("event created", "event_id", result.ID, "creator_id", creatorID)
+
+	return s.toResponse(ctx, result)
+}
+
+func (s *service) Update(ctx context.Context, id uuid.UUID, req *UpdateEventRequest, userID uuid.UUID) (*GetEventResponse, error) {
+	event, err := s.getOwnedEvent(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.SeriesID != nil {
+		if EditScope(req.Scope) == FollowingScope {
+			return s.updateFollowing(ctx, event, req)
+		}
+		event.SeriesDetached = true
+	}
+
+	if err := ApplyUpdateRequest(event, req); err != nil {
+		return nil, fmt.Errorf(app_errors.ErrInvalidData)
+	}
+
+	if err := s.validateEvent(ctx, event); err != nil {
+		return nil, err
+	}
+
+	if event.MaxParticipants != nil && *event.MaxParticipants < event.ParticipantsCount {
+		return nil, ErrCapacityLow
+	}
+
+	_, err = s.eventRepo.Update(ctx, event)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrEventCanceled
+		}
+		s.log.Error("failed to update event", "event_id", id, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	if req.MaxParticipants != nil {
+		promoted, err := s.participantRepo.PromoteWaitlisted(ctx, id)
+		if err != nil {
+			s.log.Error("failed to promote waitlisted participants", "event_id", id, "error", err)
+		}
+		s.publishPromotions(id, promoted)
+	}
+
+	s.log.Info("event updated", "event_id", id, "user_id", userID)
+
+	return s.GetByID(ctx, id)
+}
+
+func (s *service) Cancel(ctx context.Context, id uuid.UUID, userID uuid.UUID, scope EditScope) error {
+	event, err := s.getOwnedEvent(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.cancel(ctx, event, scope); err != nil {
+		return err
+	}
+
+	s.log.Info("event canceled", "event_id", id, "user_id", userID)
+
+	return nil
+}
+
+// ModerateCancel cancels an event on behalf of a moderator, regardless of who
+// created it.
+func (s *service) ModerateCancel(ctx context.Context, id uuid.UUID, moderatorID uuid.UUID, scope EditScope) error {
+	event, err := s.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if event.IsCanceled() {
+		return ErrEventCanceled
+	}
+
+	if err := s.cancel(ctx, event, scope); err != nil {
+		return err
+	}
+
+	s.log.Info("event canceled by moderator", "event_id", id, "moderator_id", moderatorID, "creator_id", event.CreatorID)
+
+	return nil
+}
+
+func (s *service) cancel(ctx context.Context, event *Event, scope EditScope) error {
+	id := event.ID
+
+	if event.SeriesID != nil && scope == FollowingScope {
+		canceled, err := s.seriesRepo.CancelFollowing(ctx, *event.SeriesID, event.StartDate)
+		if err != nil {
+			s.log.Error("failed to cancel series events", "event_id", id, "series_id", *event.SeriesID, "error", err)
+			return fmt.Errorf(app_errors.ErrFailedToSaveData)
+		}
+
+		s.log.Info("series events canceled", "event_id", id, "series_id", *event.SeriesID, "count", canceled)
+		s.publishCancellation(event, true)
+		return nil
+	}
+
+	if err := s.eventRepo.Cancel(ctx, id); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrEventCanceled
+		}
+		s.log.Error("failed to cancel event", "event_id", id, "error", err)
+		return fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	s.publishCancellation(event, false)
+
+	return nil
+}
+
+func (s *service) Join(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*JoinEventResponse, error) {
+	event, err := s.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkReliability(ctx, event, userID); err != nil {
+		return nil, err
+	}
+
+	participant, err := s.participantRepo.Join(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) ||
+			errors.Is(err, ErrAlreadyJoined) ||
+			errors.Is(err, ErrEventCanceled) ||
+			errors.Is(err, ErrEventStarted) {
+			return nil, err
+		}
+		s.log.Error("failed to join event", "event_id", id, "user_id", userID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	position := 0
+	if participant.Status == WaitlistedStatus {
+		position, err = s.participantRepo.GetWaitlistPosition(ctx, id, userID)
+		if err != nil {
+			s.log.Error("failed to get waitlist position", "event_id", id, "user_id", userID, "error", err)
+		}
+	}
+
+	s.log.Info("user joined event", "event_id", id, "user_id", userID, "status", participant.Status)
+
+	return ParticipantToJoinResponse(participant, position), nil
+}
+
+func (s *service) Leave(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	event, err := s.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if event.CreatorID == userID {
+		return ErrCreatorLeave
+	}
+
+	promoted, err := s.participantRepo.Leave(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrNotParticipant) {
+			return err
+		}
+		s.log.Error("failed to leave event", "event_id", id, "user_id", userID, "error", err)
+		return fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	s.publishPromotions(id, promoted)
+	s.chatService.Disconnect(ctx, id, userID)
+
+	s.log.Info("user left event", "event_id", id, "user_id", userID)
+
+	return nil
+}
+
+func (s *service) Search(ctx context.Context, req *SearchEventsRequest) (*SearchEventsResponse, error) {
+	filter, err := SearchRequestToFilter(req, time.Now())
+	if err != nil {
+		return nil, ErrInvalidFilter
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, ErrInvalidFilter
+	}
+
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	result, err := s.eventRepo.Search(ctx, filter)
+	if err != nil {
+		s.log.Error("failed to search events", "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	response := SearchEventsResponse{}
+	if len(result) > limit {
+		result = result[:limit]
+		response.NextCursor = EncodeCursor(result[limit-1])
+	}
+
+	response.Items, err = s.toResponses(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (s *service) GetRecommended(ctx context.Context, userID uuid.UUID, limit int) ([]*RecommendedEventResponse, error) {
+	recommendationProfile, err := s.getRecommendationProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.eventRepo.GetRecommendationCandidates(ctx, userID, recommendationProfile)
+	if err != nil {
+		s.log.Error("failed to load recommendation candidates", "user_id", userID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	ranked := s.recommender.Rank(recommendationProfile, candidates, limit)
+
+	list := make([]*Event, len(ranked))
+	for i, item := range ranked {
+		list[i] = item.Event
+	}
+
+	responses, err := s.toResponses(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*RecommendedEventResponse, len(ranked))
+	for i, item := range ranked {
+		result[i] = &RecommendedEventResponse{
+			Event: responses[i],
+			Score: item.Score,
+		}
+	}
+
+	return result, nil
+}
+
+func (s *service) GetNearby(ctx context.Context, req *NearbyEventsRequest) ([]*NearbyEventResponse, error) {
+	filter := NearbyRequestToFilter(req, time.Now())
+
+	nearby, err := s.eventRepo.GetNearby(ctx, filter)
+	if err != nil {
+		s.log.Error("failed to load nearby events", "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	list := make([]*Event, len(nearby))
+	for i, item := range nearby {
+		list[i] = item.Event
+	}
+
+	responses, err := s.toResponses(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*NearbyEventResponse, len(nearby))
+	for i, item := range nearby {
+		result[i] = &NearbyEventResponse{
+			Event:      responses[i],
+			DistanceKm: item.DistanceKm,
+		}
+	}
+
+	return result, nil
+}
+
+func (s *service) GetCalendar(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	event, err := s.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	city, err := s.refdataService.GetCityByID(ctx, event.CityID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := ical.Calendar{
+		ProdID: calendarProdID,
+		Events: []ical.Event{EventToCalendarEvent(event, city, ConfirmedStatus, s.publicURL)},
+	}
+
+	return calendar.Encode(), nil
+}
+
+func (s *service) GetCalendarFeed(ctx context.Context, token string) ([]byte, error) {
+	userID, err := s.calendarRepo.GetUserIDByToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, ErrCalendarTokenNotFound) {
+			return nil, err
+		}
+		s.log.Error("failed to resolve calendar token", "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	joined, err := s.participantRepo.GetJoinedEvents(ctx, userID, time.Now().Add(-calendarFeedHistory))
+	if err != nil {
+		s.log.Error("failed to load joined events", "user_id", userID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	cities := make(map[int]*refdata.GetCityResponse)
+	calendar := ical.Calendar{
+		ProdID: calendarProdID,
+		Name:   "SportMates",
+		Events: make([]ical.Event, 0, len(joined)),
+	}
+
+	for _, item := range joined {
+		city, ok := cities[item.Event.CityID]
+		if !ok {
+			city, err = s.refdataService.GetCityByID(ctx, item.Event.CityID)
+			if err != nil {
+				return nil, err
+			}
+			cities[item.Event.CityID] = city
+		}
+		calendar.Events = append(calendar.Events, EventToCalendarEvent(item.Event, city, item.Status, s.publicURL))
+	}
+
+	return calendar.Encode(), nil
+}
+
+func (s *service) GetCalendarFeedURL(ctx context.Context, userID uuid.UUID) (*CalendarFeedResponse, error) {
+	token, err := s.calendarRepo.GetToken(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrCalendarTokenNotFound) {
+			return s.RotateCalendarFeedURL(ctx, userID)
+		}
+		s.log.Error("failed to get calendar token", "user_id", userID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	return &CalendarFeedResponse{
+		Token: token,
+		URL:   fmt.Sprintf(calendarFeedURLFormat, s.publicURL, token),
+	}, nil
+}
+
+func (s *service) RotateCalendarFeedURL(ctx context.Context, userID uuid.UUID) (*CalendarFeedResponse, error) {
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		s.log.Error("failed to generate calendar token", "error", err)
+		return nil, fmt.Errorf("не удалось сгенерировать токен")
+	}
+	token := hex.EncodeToString(rawToken)
+
+	if err := s.calendarRepo.SaveToken(ctx, userID, token); err != nil {
+		s.log.Error("failed to save calendar token", "user_id", userID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	s.log.Info("calendar feed token issued", "user_id", userID)
+
+	return &CalendarFeedResponse{
+		Token: token,
+		URL:   fmt.Sprintf(calendarFeedURLFormat, s.publicURL, token),
+	}, nil
+}
+
+func (s *service) getRecommendationProfile(ctx context.Context, userID uuid.UUID) (*RecommendationProfile, error) {
+	userProfile, err := s.profileService.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, profile.ErrNotFound) {
+			return &RecommendationProfile{}, nil
+		}
+		s.log.Error("failed to load profile for recommendations", "user_id", userID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	result := RecommendationProfile{
+		CityID:   userProfile.City.ID,
+		RegionID: userProfile.City.Region.ID,
+	}
+
+	for _, sport := range userProfile.Sports {
+		sportID, err := uuid.Parse(sport.ID)
+		if err != nil {
+			continue
+		}
+		result.SportIDs = append(result.SportIDs, sportID)
+	}
+
+	return &result, nil
+}
+
+func (s *service) publishPromotions(eventID uuid.UUID, userIDs []uuid.UUID) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	if s.rabbitmq == nil {
+		s.log.Warn("event service not available - promotions not published", "event_id", eventID)
+		return
+	}
+
+	now := time.Now()
+	for _, userID := range userIDs {
+		event := events.ParticipantPromotedEvent{
+			EventID:    eventID.String(),
+			UserID:     userID.String(),
+			PromotedAt: now,
+		}
+
+		if err := s.rabbitmq.Publish(event); err != nil {
+			s.log.Error("failed to publish promotion event", "event_id", eventID, "user_id", userID, "error", err)
+		}
+	}
+}
+
+func (s *service) publishCancellation(event *Event, following bool) {
+	if s.rabbitmq == nil {
+		s.log.Warn("event service not available - cancellation not published", "event_id", event.ID)
+		return
+	}
+
+	message := events.EventCanceledEvent{
+		EventID:    event.ID.String(),
+		CanceledAt: time.Now(),
+	}
+	if following {
+		seriesID := event.SeriesID.String()
+		from := event.StartDate
+		message.SeriesID = &seriesID
+		message.From = &from
+	}
+
+	if err := s.rabbitmq.Publish(message); err != nil {
+		s.log.Error("failed to publish cancellation event", "event_id", event.ID, "error", err)
+	}
+}
+
+func (s *service) createSeries(ctx context.Context, template *Event, req *RecurrenceRequest) (*GetEventResponse, error) {
+	series, err := RecurrenceRequestToSeries(req, template.StartDate)
+	if err != nil {
+		return nil, ErrInvalidRecurrence
+	}
+	series.CreatorID = template.CreatorID
+
+	rule, err := SeriesToRule(series)
+	if err != nil {
+		return nil, err
+	}
+
+	starts, err := rule.Occurrences(template.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(starts) == 0 {
+		return nil, ErrInvalidRecurrence
+	}
+
+	occurrences := make([]*Event, len(starts))
+	for i, start := range starts {
+		occurrence := *template
+		occurrence.StartDate = start
+		if template.EndDate != nil {
+			end := start.Add(template.EndDate.Sub(template.StartDate))
+			occurrence.EndDate = &end
+		}
+		occurrences[i] = &occurrence
+	}
+
+	if _, err := s.seriesRepo.Create(ctx, series, occurrences); err != nil {
+		s.log.Error("failed to create event series", "creator_id", template.CreatorID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	s.log.Info("event series created",
+		"series_id", series.ID,
+		"creator_id", template.CreatorID,
+		"occurrences", len(occurrences),
+	)
+
+	return s.toResponse(ctx, occurrences[0])
+}
+
+// updateFollowing applies the request to the occurrence and every later one in
+// its series. Time changes are applied as a shift, and the series is split so
+// that its definition keeps describing the earlier occurrences. Later
+// occurrences edited on their own stay exceptions: they move to the new series
+// but keep their fields, time and duration.
+func (s *service) updateFollowing(ctx context.Context, event *Event, req *UpdateEventRequest) (*GetEventResponse, error) {
+	series, err := s.seriesRepo.GetByID(ctx, *event.SeriesID)
+	if err != nil {
+		s.log.Error("failed to load event series", "series_id", *event.SeriesID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	following, err := s.seriesRepo.GetFollowingEvents(ctx, series.ID, event.StartDate)
+	if err != nil {
+		s.log.Error("failed to load following events", "series_id", series.ID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	original := *event
+	if err := ApplyUpdateRequest(event, req); err != nil {
+		return nil, fmt.Errorf(app_errors.ErrInvalidData)
+	}
+
+	if err := s.validateEvent(ctx, event); err != nil {
+		return nil, err
+	}
+
+	shift := event.StartDate.Sub(original.StartDate)
+	retimed := req.StartDate != nil || req.EndDate != nil
+
+	fields := *req
+	fields.StartDate = nil
+	fields.EndDate = nil
+
+	occurrences := make([]*Event, 0, len(following))
+	for _, occurrence := range following {
+		switch {
+		case occurrence.ID == event.ID:
+			occurrence = event
+			occurrence.SeriesDetached = false
+		case occurrence.SeriesDetached:
+			// Edited on its own before: only its series changes.
+		default:
+			if err := ApplyUpdateRequest(occurrence, &fields); err != nil {
+				return nil, fmt.Errorf(app_errors.ErrInvalidData)
+			}
+			if retimed {
+				occurrence.StartDate = occurrence.StartDate.Add(shift)
+				occurrence.EndDate = nil
+				if event.EndDate != nil {
+					end := occurrence.StartDate.Add(event.EndDate.Sub(event.StartDate))
+					occurrence.EndDate = &end
+				}
+			}
+		}
+
+		if occurrence.MaxParticipants != nil && *occurrence.MaxParticipants < occurrence.ParticipantsCount {
+			return nil, ErrCapacityLow
+		}
+
+		occurrences = append(occurrences, occurrence)
+	}
+
+	before, err := s.seriesRepo.CountEventsBefore(ctx, series.ID, original.StartDate)
+	if err != nil {
+		s.log.Error("failed to count series events", "series_id", series.ID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToLoadData)
+	}
+
+	split := splitSeries(series, before, original.StartDate, event.StartDate)
+
+	if err := s.seriesRepo.UpdateFollowing(ctx, series, split, occurrences); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrEventCanceled
+		}
+		s.log.Error("failed to update following events", "series_id", series.ID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	if req.MaxParticipants != nil {
+		for _, occurrence := range occurrences {
+			promoted, err := s.participantRepo.PromoteWaitlisted(ctx, occurrence.ID)
+			if err != nil {
+				s.log.Error("failed to promote waitlisted participants", "event_id", occurrence.ID, "error", err)
+			}
+			s.publishPromotions(occurrence.ID, promoted)
+		}
+	}
+
+	s.log.Info("series events updated", "series_id", series.ID, "event_id", event.ID, "count", len(occurrences))
+
+	return s.GetByID(ctx, event.ID)
+}
+
+// splitSeries truncates series right before the edited occurrence and returns
+// the definition for the new series starting at it. When the edited occurrence
+// is the first one, the series is moved in place and nil is returned.
+func splitSeries(series *EventSeries, before int, originalStart time.Time, newStart time.Time) *EventSeries {
+	shift := newStart.Sub(originalStart)
+
+	var until *time.Time
+	if series.Until != nil {
+		shifted := series.Until.Add(shift)
+		until = &shifted
+	}
+
+	weekdays := shiftWeekdays(series.Weekdays, originalStart.Weekday(), newStart.Weekday())
+
+	if before == 0 {
+		series.StartDate = newStart
+		series.Until = until
+		series.Weekdays = weekdays
+		return nil
+	}
+
+	split := EventSeries{
+		CreatorID: series.CreatorID,
+		Frequency: series.Frequency,
+		Interval:  series.Interval,
+		Weekdays:  weekdays,
+		StartDate: newStart,
+		Until:     until,
+	}
+
+	if series.Count != nil {
+		remaining := *series.Count - before
+		if remaining < 1 {
+			remaining = 1
+		}
+		split.Count = &remaining
+		series.Count = &before
+	}
+
+	truncated := originalStart.Add(-time.Second)
+	series.Until = &truncated
+
+	return &split
+}
+
+func shiftWeekdays(codes []string, from time.Weekday, to time.Weekday) []string {
+	delta := (int(to) - int(from) + 7) % 7
+	if delta == 0 || len(codes) == 0 {
+		return codes
+	}
+
+	names := make(map[time.Weekday]string, len(weekdayCodes))
+	for code, weekday := range weekdayCodes {
+		names[weekday] = code
+	}
+
+	result := make([]string, 0, len(codes))
+	for _, code := range codes {
+		weekday, ok := weekdayCodes[code]
+		if !ok {
+			continue
+		}
+		result = append(result, names[time.Weekday((int(weekday)+delta)%7)])
+	}
+
+	return result
+}
+
+func (s *service) getOwnedEvent(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Event, error) {
+	event, err := s.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.CreatorID != userID {
+		s.log.Warn("attempt to modify foreign event", "event_id", id, "user_id", userID)
+		return nil, ErrAccessDenied
+	}
+
+	if event.IsCanceled() {
+		return nil, ErrEventCanceled
+	}
+
+	return event, nil
+}
+
+func (s *service) validateEvent(ctx context.Context, event *Event) error {
+	if event.EndDate != nil && !event.EndDate.After(event.StartDate) {
+		return ErrInvalidDates
+	}
+
+	if _, err := s.refdataService.GetCityByID(ctx, event.CityID); err != nil {
+		if errors.Is(err, refdata.ErrCityNotFound) {
+			return ErrInvalidCity
+		}
+		return err
+	}
+
+	if _, err := s.refdataService.GetSportByID(ctx, event.SportID.String()); err != nil {
+		if errors.Is(err, refdata.ErrNotFound) {
+			return ErrInvalidSport
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) toResponse(ctx context.Context, event *Event) (*GetEventResponse, error) {
+	city, err := s.refdataService.GetCityByID(ctx, event.CityID)
+	if err != nil {
+		return nil, err
+	}
+
+	sport, err := s.refdataService.GetSportByID(ctx, event.SportID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	response := EventToGetResponse(event, city, sport)
+
+	if event.PhotoURL != nil {
+		response.PhotoURL, err = s.getPhotoDownloadURL(ctx, *event.PhotoURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+func (s *service) toResponses(ctx context.Context, list []*Event) ([]*GetEventResponse, error) {
+	cities := make(map[int]*refdata.GetCityResponse)
+	sportIDs := make([]string, 0)
+	for _, event := range list {
+		if _, ok := cities[event.CityID]; !ok {
+			city, err := s.refdataService.GetCityByID(ctx, event.CityID)
+			if err != nil {
+				return nil, err
+			}
+			cities[event.CityID] = city
+		}
+		sportIDs = append(sportIDs, event.SportID.String())
+	}
+
+	sports, err := s.refdataService.GetSportsByIDs(ctx, sportIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	sportsByID := make(map[string]*refdata.GetSportResponse, len(sports))
+	for _, sport := range sports {
+		sportsByID[sport.ID] = sport
+	}
+
+	result := make([]*GetEventResponse, 0, len(list))
+	for _, event := range list {
+		sport, ok := sportsByID[event.SportID.String()]
+		if !ok {
+			sport = &refdata.GetSportResponse{ID: event.SportID.String()}
+		}
+		response := EventToGetResponse(event, cities[event.CityID], sport)
+		if event.PhotoURL != nil {
+			response.PhotoURL, err = s.getPhotoDownloadURL(ctx, *event.PhotoURL)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, response)
+	}
+
+	return result, nil
+}
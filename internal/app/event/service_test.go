@@ -0,0 +1,172 @@
+package event
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeSeriesStore keeps the events of one series in memory. Its two views
+// implement the event and series repositories the edits go through.
+type fakeSeriesStore struct {
+	series *EventSeries
+	events map[uuid.UUID]*Event
+}
+
+type fakeSeriesEventRepository struct {
+	EventRepository
+	store *fakeSeriesStore
+}
+
+func (r *fakeSeriesEventRepository) GetByID(ctx context.Context, id uuid.UUID) (*Event, error) {
+	event, ok := r.store.events[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	result := *event
+	return &result, nil
+}
+
+type fakeSeriesRepository struct {
+	SeriesRepository
+	store *fakeSeriesStore
+}
+
+func (r *fakeSeriesRepository) GetByID(ctx context.Context, id uuid.UUID) (*EventSeries, error) {
+	result := *r.store.series
+	return &result, nil
+}
+
+func (r *fakeSeriesRepository) GetFollowingEvents(ctx context.Context, seriesID uuid.UUID, from time.Time) ([]*Event, error) {
+	var result []*Event
+	for _, event := range r.store.events {
+		if !event.StartDate.Before(from) {
+			copied := *event
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartDate.Before(result[j].StartDate) })
+	return result, nil
+}
+
+func (r *fakeSeriesRepository) CountEventsBefore(ctx context.Context, seriesID uuid.UUID, before time.Time) (int, error) {
+	count := 0
+	for _, event := range r.store.events {
+		if event.StartDate.Before(before) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeSeriesRepository) UpdateFollowing(ctx context.Context, series *EventSeries, split *EventSeries, occurrences []*Event) error {
+	if split != nil {
+		split.ID = uuid.New()
+	}
+	for _, occurrence := range occurrences {
+		if split != nil {
+			occurrence.SeriesID = &split.ID
+		}
+		r.store.events[occurrence.ID] = occurrence
+	}
+	return nil
+}
+
+func TestUpdateFollowingKeepsDetachedOccurrences(t *testing.T) {
+	creatorID := uuid.New()
+	series := &EventSeries{ID: uuid.New(), CreatorID: creatorID, Frequency: WeeklyFrequency, Interval: 1, StartDate: testStart}
+	store := &fakeSeriesStore{series: series, events: make(map[uuid.UUID]*Event)}
+
+	occurrences := make([]*Event, 4)
+	for i := range occurrences {
+		start := testStart.Add(time.Duration(i) * 7 * 24 * time.Hour)
+		end := start.Add(90 * time.Minute)
+		occurrences[i] = &Event{
+			ID:        uuid.New(),
+			Title:     "Football",
+			StartDate: start,
+			EndDate:   &end,
+			CityID:    moscow,
+			SportID:   football,
+			CreatorID: creatorID,
+			SeriesID:  &series.ID,
+		}
+		store.events[occurrences[i].ID] = occurrences[i]
+	}
+
+	// The third occurrence was moved to the evening and made longer on its own.
+	exception := occurrences[2]
+	exception.Title = "Final"
+	exception.StartDate = exception.StartDate.Add(2 * time.Hour)
+	exceptionEnd := exception.StartDate.Add(2 * time.Hour)
+	exception.EndDate = &exceptionEnd
+	exception.SeriesDetached = true
+
+	service := NewService(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		nil, nil,
+		&fakeSeriesEventRepository{store: store},
+		nil,
+		&fakeSeriesRepository{store: store},
+		nil, nil, nil, nil,
+		&fakeRefdataService{},
+		nil, nil,
+		"https://sportmates.test",
+	)
+
+	edited := occurrences[1]
+	title := "Football 7x7"
+	start := edited.StartDate.Add(time.Hour).Format(time.RFC3339)
+	end := edited.StartDate.Add(time.Hour + 2*time.Hour).Format(time.RFC3339)
+
+	_, err := service.Update(context.Background(), edited.ID, &UpdateEventRequest{
+		Title:     &title,
+		StartDate: &start,
+		EndDate:   &end,
+		Scope:     string(FollowingScope),
+	}, creatorID)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		event        *Event
+		wantTitle    string
+		wantStart    time.Time
+		wantDuration time.Duration
+		wantDetached bool
+	}{
+		{"earlier occurrence", occurrences[0], "Football", occurrences[0].StartDate, 90 * time.Minute, false},
+		{"edited occurrence", edited, title, edited.StartDate.Add(time.Hour), 2 * time.Hour, false},
+		{"detached occurrence", exception, "Final", exception.StartDate, 2 * time.Hour, true},
+		{"later occurrence", occurrences[3], title, occurrences[3].StartDate.Add(time.Hour), 2 * time.Hour, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := store.events[tt.event.ID]
+			if got.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got.Title, tt.wantTitle)
+			}
+			if !got.StartDate.Equal(tt.wantStart) {
+				t.Errorf("start = %s, want %s", got.StartDate, tt.wantStart)
+			}
+			if got.EndDate == nil || got.EndDate.Sub(got.StartDate) != tt.wantDuration {
+				t.Errorf("end = %v, want %s after the start", got.EndDate, tt.wantDuration)
+			}
+			if got.SeriesDetached != tt.wantDetached {
+				t.Errorf("detached = %v, want %v", got.SeriesDetached, tt.wantDetached)
+			}
+		})
+	}
+
+	if *store.events[exception.ID].SeriesID != *store.events[edited.ID].SeriesID {
+		t.Error("detached occurrence was left in the truncated series")
+	}
+}
@@ -50,6 +50,16 @@ func (s *MailService) handleEmailEvent(event events.EmailEvent) error {
 		return s.sendConfirmationEmail(event)
 	case "password_reset":
 		return s.sendPasswordResetEmail(event)
+	case "account_locked":
+		return s.sendAccountLockedEmail(event)
+	case "email_changed":
+		return s.sendEmailChangedEmail(event)
+	case "magic_link":
+		return s.sendMagicLinkEmail(event)
+	case "data_export_ready":
+		return s.sendDataExportReadyEmail(event)
+	case "account_deleted":
+		return s.sendAccountDeletedEmail(event)
 	case "welcome":
 		return s.sendWelcomeEmail(event)
 	default:
@@ -107,6 +117,132 @@ func (s *MailService) sendPasswordResetEmail(event events.EmailEvent) error {
 	return nil
 }
 
+func (s *MailService) sendAccountLockedEmail(event events.EmailEvent) error {
+	s.log.Info("sending account locked email", "to", event.To)
+
+	userEmail, _ := event.Data["user_email"].(string)
+	lockedUntil, _ := event.Data["locked_until"].(string)
+	ip, _ := event.Data["ip"].(string)
+	resetURL, _ := event.Data["reset_url"].(string)
+
+	msg := mailer.MailMessage{
+		Email:   userEmail,
+		Subject: "Вход в аккаунт временно заблокирован",
+		Type:    "account_locked",
+		Params: map[string]interface{}{
+			"UserEmail":   userEmail,
+			"LockedUntil": lockedUntil,
+			"IP":          ip,
+			"ResetURL":    resetURL,
+		},
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		s.log.Error("failed to send account locked email", "error", err, "to", userEmail)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *MailService) sendEmailChangedEmail(event events.EmailEvent) error {
+	s.log.Info("sending email changed email", "to", event.To)
+
+	userEmail, _ := event.Data["user_email"].(string)
+	newEmail, _ := event.Data["new_email"].(string)
+	revertURL, _ := event.Data["revert_url"].(string)
+
+	msg := mailer.MailMessage{
+		Email:   userEmail,
+		Subject: "Email вашего аккаунта изменен",
+		Type:    "email_changed",
+		Params: map[string]interface{}{
+			"UserEmail": userEmail,
+			"NewEmail":  newEmail,
+			"RevertURL": revertURL,
+		},
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		s.log.Error("failed to send email changed email", "error", err, "to", userEmail)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *MailService) sendMagicLinkEmail(event events.EmailEvent) error {
+	s.log.Info("sending magic link email", "to", event.To)
+
+	loginURL, _ := event.Data["login_url"].(string)
+	userEmail, _ := event.Data["user_email"].(string)
+	expiresInMinutes, _ := event.Data["expires_in_minutes"].(float64)
+
+	msg := mailer.MailMessage{
+		Email:   userEmail,
+		Subject: "Вход в Sportmates",
+		Type:    "magic_link",
+		Params: map[string]interface{}{
+			"LoginURL":         loginURL,
+			"UserEmail":        userEmail,
+			"ExpiresInMinutes": int(expiresInMinutes),
+		},
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		s.log.Error("failed to send magic link email", "error", err, "to", userEmail)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *MailService) sendDataExportReadyEmail(event events.EmailEvent) error {
+	s.log.Info("sending data export ready email", "to", event.To)
+
+	userEmail, _ := event.Data["user_email"].(string)
+	downloadURL, _ := event.Data["download_url"].(string)
+
+	msg := mailer.MailMessage{
+		Email:   userEmail,
+		Subject: "Ваши данные готовы к скачиванию",
+		Type:    "data_export_ready",
+		Params: map[string]interface{}{
+			"UserEmail":   userEmail,
+			"DownloadURL": downloadURL,
+		},
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		s.log.Error("failed to send data export ready email", "error", err, "to", userEmail)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *MailService) sendAccountDeletedEmail(event events.EmailEvent) error {
+	s.log.Info("sending account deleted email", "to", event.To)
+
+	userEmail, _ := event.Data["user_email"].(string)
+
+	msg := mailer.MailMessage{
+		Email:   userEmail,
+		Subject: "Ваш аккаунт удален",
+		Type:    "account_deleted",
+		Params: map[string]interface{}{
+			"UserEmail": userEmail,
+		},
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		s.log.Error("failed to send account deleted email", "error", err, "to", userEmail)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
 func (s *MailService) sendWelcomeEmail(event events.EmailEvent) error {
 	s.log.Info("sending welcome email", "to", event.To)
 
@@ -7,11 +7,18 @@ type GetProfileResponse struct {
 	FirstName   string                     `json:"first_name"`
 	LastName    string                     `json:"last_name"`
 	Gender      string                     `json:"gender"`
-	BirthDate   string                     `json:"birth_date"`
+	BirthDate   string                     `json:"birth_date,omitempty"`
 	AvatarURL   string                     `json:"avatar_url"`
 	Description string                     `json:"description"`
 	City        refdata.GetCityResponse    `json:"city"`
 	Sports      []refdata.GetSportResponse `json:"sports"`
+	Reliability *ReliabilityResponse       `json:"reliability"`
+}
+
+type ReliabilityResponse struct {
+	Score    *float64 `json:"score"`
+	Attended int      `json:"attended"`
+	Joined   int      `json:"joined"`
 }
 
 type SaveProfileRequest struct {
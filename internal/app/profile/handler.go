@@ -3,6 +3,7 @@ package profile
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -30,8 +31,18 @@ func (h *Handler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.service.GetUserByID(r.Context(), *id)
+	viewerID, err := h.getUserIDFromContext(r.Context())
 	if err != nil {
+		boom.BadRequest(w, err)
+		return
+	}
+
+	response, err := h.service.GetProfile(r.Context(), *id, *viewerID)
+	if err != nil {
+		if stderrors.Is(err, ErrNotFound) {
+			boom.NotFound(w, "профиль не найден")
+			return
+		}
 		boom.Internal(w, err)
 		return
 	}
@@ -23,6 +23,14 @@ func ProfileToGetResponse(profile *Profile, city *refdata.GetCityResponse, sport
 	return &dto
 }
 
+func ReliabilityToResponse(reliability *Reliability) *ReliabilityResponse {
+	return &ReliabilityResponse{
+		Score:    reliability.Score(),
+		Attended: reliability.Attended,
+		Joined:   reliability.Joined,
+	}
+}
+
 func SaveRequestToProfile(dto *SaveProfileRequest) (*Profile, error) {
 	model := Profile{
 		FirstName:   dto.FirstName,
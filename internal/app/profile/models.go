@@ -19,7 +19,43 @@ type Profile struct {
 	UpdatedAt   time.Time `db:"updated_at"`
 }
 
+const (
+	EveryoneVisibility     = "everyone"
+	ParticipantsVisibility = "participants"
+	NobodyVisibility       = "nobody"
+)
+
+// Privacy is the part of the account settings that limits who sees the
+// profile. Users who never changed their settings get DefaultPrivacy.
+type Privacy struct {
+	Visibility    string `db:"profile_visibility"`
+	ShowBirthDate bool   `db:"show_birth_date"`
+}
+
+func DefaultPrivacy() *Privacy {
+	return &Privacy{
+		Visibility:    EveryoneVisibility,
+		ShowBirthDate: true,
+	}
+}
+
 type UserSport struct {
 	UserID  uuid.UUID `db:"user_id"`
 	SportID uuid.UUID `db:"sport_id"`
 }
+
+type Reliability struct {
+	Attended int
+	Joined   int
+}
+
+// Score is the share of tracked participations the user actually attended.
+// It is nil until attendance was recorded for at least one event.
+func (r *Reliability) Score() *float64 {
+	if r == nil || r.Joined == 0 {
+		return nil
+	}
+
+	score := float64(r.Attended) / float64(r.Joined)
+	return &score
+}
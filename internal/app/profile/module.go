@@ -10,7 +10,7 @@ import (
 
 type Module struct {
 	repo           Repository
-	service        Service
+	Service        Service
 	refdataService refdata.Service
 	Handler        Handler
 }
@@ -24,7 +24,7 @@ func NewModule(log *slog.Logger, pool *pgxpool.Pool, minio *minio.Service, refda
 
 	return &Module{
 		repo:    repo,
-		service: service,
+		Service: service,
 		Handler: *handler,
 	}
 }
@@ -21,6 +21,9 @@ type Repository interface {
 	Create(ctx context.Context, model *Profile, sportIDs []string) (*Profile, error)
 	Update(ctx context.Context, model *Profile, sportIDs []string) (*Profile, error)
 	GetUserSports(ctx context.Context, userID uuid.UUID) ([]*UserSport, error)
+	GetReliability(ctx context.Context, userID uuid.UUID) (*Reliability, error)
+	GetPrivacy(ctx context.Context, userID uuid.UUID) (*Privacy, error)
+	SharesEvent(ctx context.Context, userID uuid.UUID, otherID uuid.UUID) (bool, error)
 }
 
 type repository struct {
@@ -263,3 +266,68 @@ func (r *repository) updateUserSports(ctx context.Context, tx pgx.Tx, userID uui
 
 	return nil
 }
+
+// GetReliability counts the user's confirmed participations in events of other
+// organizers for which attendance was recorded.
+func (r *repository) GetReliability(ctx context.Context, userID uuid.UUID) (*Reliability, error) {
+	const query = `
+		SELECT count(*) FILTER (WHERE p.attended), count(*)
+		FROM event_participants p
+		JOIN events e ON e.id = p.event_id
+		WHERE p.user_id = $1
+			AND p.status = 'confirmed'
+			AND p.attended IS NOT NULL
+			AND e.creator_id <> p.user_id
+			AND e.canceled_at IS NULL
+	`
+
+	var reliability Reliability
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&reliability.Attended, &reliability.Joined); err != nil {
+		return nil, fmt.Errorf("failed to get user reliability: %w", err)
+	}
+
+	return &reliability, nil
+}
+
+// GetPrivacy reads the privacy part of the account settings. Users who never
+// changed their settings have no row and get DefaultPrivacy.
+func (r *repository) GetPrivacy(ctx context.Context, userID uuid.UUID) (*Privacy, error) {
+	const query = `
+		SELECT profile_visibility, show_birth_date
+		FROM user_settings
+		WHERE user_id = $1
+	`
+
+	privacy := DefaultPrivacy()
+	err := r.db.QueryRow(ctx, query, userID).Scan(&privacy.Visibility, &privacy.ShowBirthDate)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get privacy settings: %w", err)
+	}
+
+	return privacy, nil
+}
+
+// SharesEvent reports whether both users are confirmed participants of the
+// same event that was not canceled. Organizers take part in their own events.
+func (r *repository) SharesEvent(ctx context.Context, userID uuid.UUID, otherID uuid.UUID) (bool, error) {
+	const query = `
+		SELECT EXISTS (
+			SELECT 1
+			FROM event_participants mine
+			JOIN event_participants theirs ON theirs.event_id = mine.event_id
+			JOIN events e ON e.id = mine.event_id
+			WHERE mine.user_id = $1
+				AND theirs.user_id = $2
+				AND mine.status = 'confirmed'
+				AND theirs.status = 'confirmed'
+				AND e.canceled_at IS NULL
+		)
+	`
+
+	var shares bool
+	if err := r.db.QueryRow(ctx, query, userID, otherID).Scan(&shares); err != nil {
+		return false, fmt.Errorf("failed to check shared events: %w", err)
+	}
+
+	return shares, nil
+}
@@ -11,11 +11,15 @@ import (
 	"github.com/google/uuid"
 )
 
+const AvatarsBucketName = "trackmus_avatars"
+
 type Service interface {
 	GetUserByID(ctx context.Context, id uuid.UUID) (*GetProfileResponse, error)
+	GetProfile(ctx context.Context, id uuid.UUID, viewerID uuid.UUID) (*GetProfileResponse, error)
 	SaveProfile(ctx context.Context, req *SaveProfileRequest, id *uuid.UUID) (*GetProfileResponse, error)
 	GetAvatarUploadURL(ctx context.Context, userID uuid.UUID) (*GetUploadURLResponse, error)
 	ConfirmAvatarUpload(ctx context.Context, userID uuid.UUID) (*ConfirmUploadAvatarResponse, error)
+	GetReliability(ctx context.Context, userID uuid.UUID) (*ReliabilityResponse, error)
 }
 
 type service struct {
@@ -30,7 +34,7 @@ func NewService(log *slog.Logger, minio *minio.Service, repo Repository, refdata
 	return &service{
 		log:            log,
 		minio:          minio,
-		bucketName:     "trackmus_avatars",
+		bucketName:     AvatarsBucketName,
 		repo:           repo,
 		refdataService: refdataService,
 	}
@@ -52,11 +56,67 @@ func (s *service) GetUserByID(ctx context.Context, id uuid.UUID) (*GetProfileRes
 		return nil, err
 	}
 
+	reliability, err := s.GetReliability(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	profileDTO := ProfileToGetResponse(profile, city, sports)
+	profileDTO.Reliability = reliability
 
 	return profileDTO, nil
 }
 
+// GetProfile returns the profile as the viewer may see it according to the
+// owner's privacy settings. A hidden profile is reported as not found, and the
+// birth date is left out if the owner chose so. Owners always see everything.
+func (s *service) GetProfile(ctx context.Context, id uuid.UUID, viewerID uuid.UUID) (*GetProfileResponse, error) {
+	if id == viewerID {
+		return s.GetUserByID(ctx, id)
+	}
+
+	privacy, err := s.repo.GetPrivacy(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get privacy settings", "user_id", id, "error", err)
+		return nil, fmt.Errorf(errors.ErrFailedToLoadData)
+	}
+
+	visible, err := s.isVisible(ctx, privacy, id, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrNotFound
+	}
+
+	response, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !privacy.ShowBirthDate {
+		response.BirthDate = ""
+	}
+
+	return response, nil
+}
+
+func (s *service) isVisible(ctx context.Context, privacy *Privacy, id uuid.UUID, viewerID uuid.UUID) (bool, error) {
+	switch privacy.Visibility {
+	case EveryoneVisibility:
+		return true, nil
+	case ParticipantsVisibility:
+		shares, err := s.repo.SharesEvent(ctx, id, viewerID)
+		if err != nil {
+			s.log.Error("failed to check shared events", "user_id", id, "viewer_id", viewerID, "error", err)
+			return false, fmt.Errorf(errors.ErrFailedToLoadData)
+		}
+		return shares, nil
+	default:
+		return false, nil
+	}
+}
+
 func (s *service) SaveProfile(ctx context.Context, req *SaveProfileRequest, id *uuid.UUID) (*GetProfileResponse, error) {
 	profile, err := SaveRequestToProfile(req)
 	if err != nil {
@@ -94,7 +154,13 @@ func (s *service) SaveProfile(ctx context.Context, req *SaveProfileRequest, id *
 		return nil, err
 	}
 
+	reliability, err := s.GetReliability(ctx, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	response := ProfileToGetResponse(result, city, sports)
+	response.Reliability = reliability
 
 	return response, nil
 }
@@ -127,6 +193,16 @@ func (s *service) ConfirmAvatarUpload(ctx context.Context, userID uuid.UUID) (*C
 	}, nil
 }
 
+func (s *service) GetReliability(ctx context.Context, userID uuid.UUID) (*ReliabilityResponse, error) {
+	reliability, err := s.repo.GetReliability(ctx, userID)
+	if err != nil {
+		s.log.Error("failed to get user reliability", "user_id", userID, "error", err)
+		return nil, fmt.Errorf(errors.ErrFailedToLoadData)
+	}
+
+	return ReliabilityToResponse(reliability), nil
+}
+
 func (s *service) getDownloadAvatarURL(ctx context.Context, userID uuid.UUID) (string, error) {
 	s3key := userID.String()
 	downloadUrl, err := s.minio.GenerateDownloadURL(ctx, s.bucketName, s3key, "avatar")
@@ -0,0 +1,95 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/app/refdata"
+	"github.com/google/uuid"
+)
+
+type fakeRepository struct {
+	Repository
+	profile *Profile
+	privacy *Privacy
+	shared  bool
+}
+
+func (r *fakeRepository) GetByID(ctx context.Context, id uuid.UUID) (*Profile, error) {
+	return r.profile, nil
+}
+
+func (r *fakeRepository) GetUserSports(ctx context.Context, userID uuid.UUID) ([]*UserSport, error) {
+	return nil, nil
+}
+
+func (r *fakeRepository) GetReliability(ctx context.Context, userID uuid.UUID) (*Reliability, error) {
+	return &Reliability{}, nil
+}
+
+func (r *fakeRepository) GetPrivacy(ctx context.Context, userID uuid.UUID) (*Privacy, error) {
+	return r.privacy, nil
+}
+
+func (r *fakeRepository) SharesEvent(ctx context.Context, userID uuid.UUID, otherID uuid.UUID) (bool, error) {
+	return r.shared, nil
+}
+
+type fakeRefdataService struct {
+	refdata.Service
+}
+
+func (s fakeRefdataService) GetCityByID(ctx context.Context, id int) (*refdata.GetCityResponse, error) {
+	return &refdata.GetCityResponse{ID: id}, nil
+}
+
+func TestGetProfilePrivacy(t *testing.T) {
+	ownerID, viewerID := uuid.New(), uuid.New()
+	birthDate := time.Date(1995, 4, 12, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		viewerID      uuid.UUID
+		privacy       *Privacy
+		shared        bool
+		wantHidden    bool
+		wantBirthDate bool
+	}{
+		{"everyone", viewerID, DefaultPrivacy(), false, false, true},
+		{"nobody", viewerID, &Privacy{Visibility: NobodyVisibility, ShowBirthDate: true}, true, true, false},
+		{"participants without shared event", viewerID, &Privacy{Visibility: ParticipantsVisibility, ShowBirthDate: true}, false, true, false},
+		{"participants with shared event", viewerID, &Privacy{Visibility: ParticipantsVisibility, ShowBirthDate: true}, true, false, true},
+		{"birth date hidden", viewerID, &Privacy{Visibility: EveryoneVisibility, ShowBirthDate: false}, false, false, false},
+		{"owner", ownerID, &Privacy{Visibility: NobodyVisibility, ShowBirthDate: false}, false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{
+				profile: &Profile{ID: ownerID, FirstName: "Anna", BirthDate: birthDate},
+				privacy: tt.privacy,
+				shared:  tt.shared,
+			}
+			s := NewService(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, repo, fakeRefdataService{})
+
+			response, err := s.GetProfile(context.Background(), ownerID, tt.viewerID)
+			if tt.wantHidden {
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("err = %v, want %v", err, ErrNotFound)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := response.BirthDate != ""; got != tt.wantBirthDate {
+				t.Errorf("birth date = %q, want shown = %v", response.BirthDate, tt.wantBirthDate)
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package refdata
 
 type GetCityResponse struct {
-	ID     int               `json:"id"`
-	Name   string            `json:"name"`
-	Region GetRegionResponse `json:"region"`
+	ID        int               `json:"id"`
+	Name      string            `json:"name"`
+	Latitude  *float64          `json:"latitude,omitempty"`
+	Longitude *float64          `json:"longitude,omitempty"`
+	Region    GetRegionResponse `json:"region"`
 }
 
 type GetRegionResponse struct {
@@ -16,3 +18,20 @@ type GetSportResponse struct {
 	Name    string `json:"name"`
 	IconURL string `json:"icon_url"`
 }
+
+type CreateSportRequest struct {
+	Name    string `json:"name" validate:"required,max=100"`
+	IconURL string `json:"icon_url" validate:"omitempty,url,max=500"`
+}
+
+type UpdateSportRequest struct {
+	Name    *string `json:"name" validate:"omitempty,min=1,max=100"`
+	IconURL *string `json:"icon_url" validate:"omitempty,url,max=500"`
+}
+
+type CreateCityRequest struct {
+	Name      string   `json:"name" validate:"required,max=100"`
+	RegionID  int      `json:"region_id" validate:"required"`
+	Latitude  *float64 `json:"latitude" validate:"omitempty,latitude"`
+	Longitude *float64 `json:"longitude" validate:"omitempty,longitude"`
+}
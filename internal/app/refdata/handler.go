@@ -2,12 +2,15 @@ package refdata
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
 
+	validation "github.com/RuLap/sportmates-api/internal/pkg/validator"
 	"github.com/darahayes/go-boom"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type Handler struct {
@@ -113,6 +116,88 @@ func (h *Handler) GetSportByID(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(sport)
 }
 
+func (h *Handler) CreateSport(w http.ResponseWriter, r *http.Request) {
+	var req CreateSportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	sport, err := h.service.CreateSport(r.Context(), &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, sport, http.StatusCreated)
+}
+
+func (h *Handler) UpdateSport(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := uuid.Parse(id); err != nil {
+		boom.BadRequest(w, "неверный формат идентификатора")
+		return
+	}
+
+	var req UpdateSportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	sport, err := h.service.UpdateSport(r.Context(), id, &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, sport, http.StatusOK)
+}
+
+func (h *Handler) CreateCity(w http.ResponseWriter, r *http.Request) {
+	var req CreateCityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "ошибки валидации", errors)
+		return
+	}
+
+	city, err := h.service.CreateCity(r.Context(), &req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	h.sendJSON(w, city, http.StatusCreated)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		boom.NotFound(w, "вид спорта не найден")
+	case errors.Is(err, ErrSportExists):
+		boom.Conflict(w, "вид спорта с таким названием уже существует")
+	case errors.Is(err, ErrRegionNotFound):
+		boom.BadRequest(w, "регион не найден")
+	default:
+		boom.Internal(w, err)
+	}
+}
+
 func (h *Handler) sendJSON(w http.ResponseWriter, data interface{}, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,6 +18,7 @@ var (
 type LocationRepository interface {
 	GetCityByID(ctx context.Context, id int) (*City, error)
 	GetCitiesByRegionID(ctx context.Context, regionID int) ([]*City, error)
+	CreateCity(ctx context.Context, city *City) error
 
 	GetRegionByID(ctx context.Context, id int) (*Region, error)
 	GetAllRegions(ctx context.Context) ([]*Region, error)
@@ -32,13 +34,13 @@ func NewLocationRepository(db *pgxpool.Pool) LocationRepository {
 
 func (r *locationRepository) GetCityByID(ctx context.Context, id int) (*City, error) {
 	query := `
-		SELECT id, name, region_id
+		SELECT id, name, region_id, latitude, longitude
 		FROM cities
 		WHERE id = $1
 	`
 
 	var city City
-	err := r.db.QueryRow(ctx, query, id).Scan(&city.ID, &city.Name, &city.RegionID)
+	err := r.db.QueryRow(ctx, query, id).Scan(&city.ID, &city.Name, &city.RegionID, &city.Latitude, &city.Longitude)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -52,7 +54,7 @@ func (r *locationRepository) GetCityByID(ctx context.Context, id int) (*City, er
 
 func (r *locationRepository) GetCitiesByRegionID(ctx context.Context, regionID int) ([]*City, error) {
 	query := `
-		SELECT id, name, region_id
+		SELECT id, name, region_id, latitude, longitude
 		FROM cities
 		WHERE region_id = $1
 		ORDER BY name
@@ -67,7 +69,7 @@ func (r *locationRepository) GetCitiesByRegionID(ctx context.Context, regionID i
 	cities := make([]*City, 0)
 	for rows.Next() {
 		var city City
-		err := rows.Scan(&city.ID, &city.Name, &city.RegionID)
+		err := rows.Scan(&city.ID, &city.Name, &city.RegionID, &city.Latitude, &city.Longitude)
 		if err != nil {
 			return nil, err
 		}
@@ -82,6 +84,25 @@ func (r *locationRepository) GetCitiesByRegionID(ctx context.Context, regionID i
 	return cities, nil
 }
 
+func (r *locationRepository) CreateCity(ctx context.Context, city *City) error {
+	query := `
+		INSERT INTO cities (name, region_id, latitude, longitude)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(ctx, query, city.Name, city.RegionID, city.Latitude, city.Longitude).Scan(&city.ID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrRegionNotFound
+		}
+		return fmt.Errorf("insert city: %w", err)
+	}
+
+	return nil
+}
+
 func (r *locationRepository) GetAllRegions(ctx context.Context) ([]*Region, error) {
 	query := `
 		SELECT id, name
@@ -2,9 +2,11 @@ package refdata
 
 func CityToGetResponse(city *City, region GetRegionResponse) *GetCityResponse {
 	dto := GetCityResponse{
-		ID:     city.ID,
-		Name:   city.Name,
-		Region: region,
+		ID:        city.ID,
+		Name:      city.Name,
+		Latitude:  city.Latitude,
+		Longitude: city.Longitude,
+		Region:    region,
 	}
 
 	return &dto
@@ -28,3 +30,19 @@ func SportToGetResponse(sport *Sport) *GetSportResponse {
 
 	return &dto
 }
+
+func CreateSportRequestToSport(dto *CreateSportRequest) *Sport {
+	return &Sport{
+		Name:    dto.Name,
+		IconURL: dto.IconURL,
+	}
+}
+
+func CreateCityRequestToCity(dto *CreateCityRequest) *City {
+	return &City{
+		Name:      dto.Name,
+		RegionID:  dto.RegionID,
+		Latitude:  dto.Latitude,
+		Longitude: dto.Longitude,
+	}
+}
@@ -3,9 +3,11 @@ package refdata
 import "github.com/google/uuid"
 
 type City struct {
-	ID       int    `db:"id"`
-	Name     string `db:"name"`
-	RegionID int    `db:"region_id"`
+	ID        int      `db:"id"`
+	Name      string   `db:"name"`
+	RegionID  int      `db:"region_id"`
+	Latitude  *float64 `db:"latitude"`
+	Longitude *float64 `db:"longitude"`
 }
 
 type Region struct {
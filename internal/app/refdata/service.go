@@ -2,7 +2,11 @@ package refdata
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+
+	app_errors "github.com/RuLap/sportmates-api/internal/pkg/errors"
 )
 
 type Service interface {
@@ -15,6 +19,10 @@ type Service interface {
 	GetAllSports(ctx context.Context) ([]*GetSportResponse, error)
 	GetSportByID(ctx context.Context, id string) (*GetSportResponse, error)
 	GetSportsByIDs(ctx context.Context, ids []string) ([]*GetSportResponse, error)
+
+	CreateSport(ctx context.Context, req *CreateSportRequest) (*GetSportResponse, error)
+	UpdateSport(ctx context.Context, id string, req *UpdateSportRequest) (*GetSportResponse, error)
+	CreateCity(ctx context.Context, req *CreateCityRequest) (*GetCityResponse, error)
 }
 
 type service struct {
@@ -129,3 +137,66 @@ func (s *service) GetSportsByIDs(ctx context.Context, ids []string) ([]*GetSport
 
 	return result, nil
 }
+
+func (s *service) CreateSport(ctx context.Context, req *CreateSportRequest) (*GetSportResponse, error) {
+	sport := CreateSportRequestToSport(req)
+
+	if err := s.sportRepo.CreateSport(ctx, sport); err != nil {
+		if errors.Is(err, ErrSportExists) {
+			return nil, err
+		}
+		s.log.Error("failed to create sport", "name", req.Name, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	s.log.Info("sport created", "sport_id", sport.ID, "name", sport.Name)
+
+	return SportToGetResponse(sport), nil
+}
+
+func (s *service) UpdateSport(ctx context.Context, id string, req *UpdateSportRequest) (*GetSportResponse, error) {
+	sport, err := s.sportRepo.GetSportByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		sport.Name = *req.Name
+	}
+	if req.IconURL != nil {
+		sport.IconURL = *req.IconURL
+	}
+
+	if err := s.sportRepo.UpdateSport(ctx, sport); err != nil {
+		if errors.Is(err, ErrSportExists) || errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		s.log.Error("failed to update sport", "sport_id", id, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	s.log.Info("sport updated", "sport_id", id)
+
+	return SportToGetResponse(sport), nil
+}
+
+func (s *service) CreateCity(ctx context.Context, req *CreateCityRequest) (*GetCityResponse, error) {
+	region, err := s.GetRegionByID(ctx, req.RegionID)
+	if err != nil {
+		return nil, err
+	}
+
+	city := CreateCityRequestToCity(req)
+
+	if err := s.locationRepo.CreateCity(ctx, city); err != nil {
+		if errors.Is(err, ErrRegionNotFound) {
+			return nil, err
+		}
+		s.log.Error("failed to create city", "name", req.Name, "region_id", req.RegionID, "error", err)
+		return nil, fmt.Errorf(app_errors.ErrFailedToSaveData)
+	}
+
+	s.log.Info("city created", "city_id", city.ID, "name", city.Name)
+
+	return CityToGetResponse(city, *region), nil
+}
@@ -6,17 +6,22 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var (
-	ErrNotFound = errors.New("sport not found")
+	ErrNotFound    = errors.New("sport not found")
+	ErrSportExists = errors.New("sport already exists")
 )
 
 type SportRepository interface {
 	GetAllSports(ctx context.Context) ([]*Sport, error)
 	GetSportByID(ctx context.Context, id string) (*Sport, error)
 	GetSportsByIDs(ctx context.Context, ids []string) ([]*Sport, error)
+
+	CreateSport(ctx context.Context, sport *Sport) error
+	UpdateSport(ctx context.Context, sport *Sport) error
 }
 
 type sportRepository struct {
@@ -106,3 +111,48 @@ func (r *sportRepository) GetSportsByIDs(ctx context.Context, ids []string) ([]*
 
 	return sports, nil
 }
+
+func (r *sportRepository) CreateSport(ctx context.Context, sport *Sport) error {
+	const query = `
+		INSERT INTO sports (name, icon_url)
+		VALUES ($1, $2)
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(ctx, query, sport.Name, sport.IconURL).Scan(&sport.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrSportExists
+		}
+		return fmt.Errorf("insert sport: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sportRepository) UpdateSport(ctx context.Context, sport *Sport) error {
+	const query = `
+		UPDATE sports
+		SET name = $2, icon_url = $3
+		WHERE id = $1
+	`
+
+	result, err := r.db.Exec(ctx, query, sport.ID, sport.Name, sport.IconURL)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrSportExists
+		}
+		return fmt.Errorf("update sport: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,168 @@
+package user
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrForbidden = stderrors.New("недостаточно прав")
+)
+
+func (s *service) GetUserForAdmin(ctx context.Context, userID string) (*AdminUserResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("неверный ID пользователя")
+	}
+
+	user, roles, err := s.getUserWithRoles(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return UserToAdminResponse(user, roles), nil
+}
+
+// BanUser blocks sign-in and revokes every token the user holds. Moderators
+// can only ban regular users; staff accounts can be banned by admins only.
+func (s *service) BanUser(ctx context.Context, moderatorID string, moderatorRoles []string, userID string, req *BanUserRequest) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("неверный ID пользователя")
+	}
+
+	if userID == moderatorID {
+		return ErrForbidden
+	}
+
+	_, roles, err := s.getUserWithRoles(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if len(roles) > 0 && !slices.Contains(moderatorRoles, AdminRole) {
+		s.log.Warn("moderator attempted to ban staff account", "moderator_id", moderatorID, "user_id", userID)
+		return ErrForbidden
+	}
+
+	if err := s.repo.Ban(ctx, id, req.Reason); err != nil {
+		if stderrors.Is(err, ErrUserNotFound) {
+			return err
+		}
+		s.log.Error("failed to ban user", "error", err, "user_id", userID)
+		return fmt.Errorf("произошла ошибка")
+	}
+
+	if err := s.revokeAllTokens(ctx, userID); err != nil {
+		s.log.Error("failed to revoke tokens of banned user", "error", err, "user_id", userID)
+		return fmt.Errorf("произошла ошибка")
+	}
+
+	s.log.Info("user banned", "user_id", userID, "moderator_id", moderatorID, "reason", req.Reason)
+	return nil
+}
+
+// UnbanUser lifts a ban. Like bans, staff accounts can be unbanned by admins
+// only.
+func (s *service) UnbanUser(ctx context.Context, moderatorID string, moderatorRoles []string, userID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("неверный ID пользователя")
+	}
+
+	_, roles, err := s.getUserWithRoles(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if len(roles) > 0 && !slices.Contains(moderatorRoles, AdminRole) {
+		s.log.Warn("moderator attempted to unban staff account", "moderator_id", moderatorID, "user_id", userID)
+		return ErrForbidden
+	}
+
+	if err := s.repo.Unban(ctx, id); err != nil {
+		if stderrors.Is(err, ErrUserNotFound) {
+			return err
+		}
+		s.log.Error("failed to unban user", "error", err, "user_id", userID)
+		return fmt.Errorf("произошла ошибка")
+	}
+
+	s.log.Info("user unbanned", "user_id", userID, "moderator_id", moderatorID)
+	return nil
+}
+
+// SetRoles replaces the user's roles. When a role is taken away, the user's
+// tokens are revoked so that access is lost right away rather than when the
+// access token expires; new roles show up on the next refresh. Admins cannot
+// change their own roles to avoid locking everyone out.
+func (s *service) SetRoles(ctx context.Context, adminID string, userID string, req *SetRolesRequest) (*AdminUserResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("неверный ID пользователя")
+	}
+
+	grantedBy, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, fmt.Errorf("неверный ID пользователя")
+	}
+
+	if userID == adminID {
+		return nil, ErrForbidden
+	}
+
+	_, currentRoles, err := s.getUserWithRoles(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := slices.Clone(req.Roles)
+	slices.Sort(roles)
+	roles = slices.Compact(roles)
+
+	if err := s.roleRepo.SetRoles(ctx, id, roles, grantedBy); err != nil {
+		if stderrors.Is(err, ErrUnknownRole) || stderrors.Is(err, ErrUserNotFound) {
+			return nil, err
+		}
+		s.log.Error("failed to set user roles", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	for _, role := range currentRoles {
+		if slices.Contains(roles, role) {
+			continue
+		}
+
+		if err := s.revokeAllTokens(ctx, userID); err != nil {
+			s.log.Error("failed to revoke tokens after role change", "error", err, "user_id", userID)
+		}
+		break
+	}
+
+	s.log.Info("user roles changed", "user_id", userID, "admin_id", adminID, "roles", roles)
+
+	return s.GetUserForAdmin(ctx, userID)
+}
+
+func (s *service) getUserWithRoles(ctx context.Context, id uuid.UUID) (*User, []string, error) {
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if stderrors.Is(err, ErrUserNotFound) {
+			return nil, nil, err
+		}
+		s.log.Error("failed to get user", "error", err, "user_id", id)
+		return nil, nil, fmt.Errorf("произошла ошибка")
+	}
+
+	roles, err := s.roleRepo.GetRoles(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get user roles", "error", err, "user_id", id)
+		return nil, nil, fmt.Errorf("произошла ошибка")
+	}
+
+	return user, roles, nil
+}
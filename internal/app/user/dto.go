@@ -10,12 +10,13 @@ type AuthResponse struct {
 
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8,max=32"`
+	Password string `json:"password" validate:"required"`
 }
 
+// RegisterRequest.Password is checked against the PasswordPolicy.
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8,max=32"`
+	Password string `json:"password" validate:"required"`
 }
 
 type SendConfirmationEmailRequest struct {
@@ -26,6 +27,112 @@ type ConfirmEmailRequest struct {
 	Token string `json:"token" validate:"required"`
 }
 
+type ChangeEmailRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RevertEmailChangeRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// DeviceID is an optional identifier the client keeps for the installation.
+// The link can only be used from the device it was requested on.
+type MagicLinkRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	DeviceID string `json:"device_id" validate:"max=128"`
+}
+
+type VerifyMagicLinkRequest struct {
+	Token    string `json:"token" validate:"required"`
+	DeviceID string `json:"device_id" validate:"max=128"`
+}
+
+type SendPhoneCodeRequest struct {
+	Phone string `json:"phone" validate:"required,max=32"`
+}
+
+type PhoneCodeResponse struct {
+	ExpiresIn  int64 `json:"expires_in"`
+	RetryAfter int64 `json:"retry_after"`
+}
+
+type VerifyPhoneCodeRequest struct {
+	Phone string `json:"phone" validate:"required,max=32"`
+	Code  string `json:"code" validate:"required,len=6,numeric"`
+}
+
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword         string `json:"old_password" validate:"required"`
+	NewPassword         string `json:"new_password" validate:"required"`
+	LogoutOtherSessions bool   `json:"logout_other_sessions"`
+}
+
+type SessionResponse struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	Current    bool   `json:"current"`
+}
+
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+	ExpiresIn         int64  `json:"expires_in"`
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+type EnrollTwoFactorResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type VerifyTwoFactorRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type VerifyTwoFactorResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type DisableTwoFactorRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type AdminUserResponse struct {
+	ID             string   `json:"id"`
+	Email          string   `json:"email"`
+	EmailConfirmed bool     `json:"email_confirmed"`
+	Phone          *string  `json:"phone,omitempty"`
+	Provider       string   `json:"provider"`
+	Roles          []string `json:"roles"`
+	BannedAt       *string  `json:"banned_at,omitempty"`
+	BanReason      *string  `json:"ban_reason,omitempty"`
+}
+
+type BanUserRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type SetRolesRequest struct {
+	Roles []string `json:"roles" validate:"dive,required"`
+}
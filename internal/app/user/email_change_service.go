@@ -0,0 +1,214 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/events"
+	"github.com/google/uuid"
+)
+
+const emailRevertTTL = 7 * 24 * time.Hour
+
+var (
+	ErrEmailChangeUnavailable = stderrors.New("смена email доступна только для аккаунтов с паролем")
+	ErrSameEmail              = stderrors.New("новый email совпадает с текущим")
+)
+
+// ChangeEmail sends a confirmation link to the new address. The address is
+// switched only when the link is confirmed, see ConfirmEmail.
+func (s *service) ChangeEmail(ctx context.Context, userID string, req *ChangeEmailRequest) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("неверный ID пользователя")
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get user", "error", err, "user_id", userID)
+		return fmt.Errorf("произошла ошибка")
+	}
+
+	// Google accounts are looked up by their Google address and have no
+	// password to confirm the change with.
+	if user.Provider != LocalProvider || user.Password == nil {
+		return ErrEmailChangeUnavailable
+	}
+
+	if err := s.VerifyPassword(ctx, userID, req.Password); err != nil {
+		return err
+	}
+
+	if req.Email == user.Email {
+		return ErrSameEmail
+	}
+
+	if _, err := s.repo.GetByEmailProvider(ctx, req.Email, LocalProvider); err == nil {
+		return ErrUserAlreadyExists
+	}
+
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		s.log.Error("failed to generate token", "error", err)
+		return fmt.Errorf("не удалось сгенерировать токен")
+	}
+	token := hex.EncodeToString(rawToken)
+
+	if err := s.redis.StoreEmailChange(ctx, userID, req.Email, token); err != nil {
+		s.log.Error("failed to store token in redis", "error", err, "user_id", userID)
+		return fmt.Errorf("не удалось сохранить токен")
+	}
+
+	confirmationURL := fmt.Sprintf("%s/confirm?token=%s", s.publicURL, token)
+
+	if s.rabbitmq != nil {
+		event := events.EmailEvent{
+			To:       req.Email,
+			Template: "email_confirmation",
+			Subject:  "Подтвердите ваш email",
+			Data: map[string]interface{}{
+				"confirmation_url": confirmationURL,
+				"user_email":       req.Email,
+			},
+		}
+
+		if err := s.rabbitmq.PublishEmail(event); err != nil {
+			s.log.Error("failed to publish email event", "error", err)
+		}
+	} else {
+		s.log.Warn("event service not available - email not sent")
+	}
+
+	s.log.Info("email change requested", "user_id", userID)
+	return nil
+}
+
+// applyEmailChange switches the address once the new one is confirmed and
+// lets the previous address revert the change. Tokens carry the email, so
+// every session is ended and the user signs in with the new address.
+func (s *service) applyEmailChange(ctx context.Context, userID, newEmail string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		s.log.Error("failed to parse user id", "error", err, "user_id", userID)
+		return fmt.Errorf("не удалось подтвердить email")
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get user", "error", err, "user_id", userID)
+		return fmt.Errorf("не удалось подтвердить email")
+	}
+
+	if err := s.repo.UpdateEmail(ctx, id, newEmail); err != nil {
+		if stderrors.Is(err, ErrUserAlreadyExists) {
+			return err
+		}
+		s.log.Error("failed to update email", "error", err, "user_id", userID)
+		return fmt.Errorf("не удалось подтвердить email")
+	}
+
+	if err := s.revokeAllTokens(ctx, userID); err != nil {
+		s.log.Error("failed to revoke tokens after email change", "error", err, "user_id", userID)
+	}
+
+	s.sendEmailChangedNotice(ctx, userID, user.Email, newEmail)
+
+	s.log.Info("email changed", "user_id", userID)
+	return nil
+}
+
+func (s *service) sendEmailChangedNotice(ctx context.Context, userID, oldEmail, newEmail string) {
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		s.log.Error("failed to generate token", "error", err)
+		return
+	}
+	token := hex.EncodeToString(rawToken)
+
+	revert := EmailRevert{
+		UserID:   userID,
+		Email:    oldEmail,
+		NewEmail: newEmail,
+	}
+	if err := s.redis.StoreEmailRevert(ctx, token, revert, emailRevertTTL); err != nil {
+		s.log.Error("failed to store email revert token", "error", err, "user_id", userID)
+		return
+	}
+
+	if s.rabbitmq == nil {
+		s.log.Warn("event service not available - email not sent")
+		return
+	}
+
+	event := events.EmailEvent{
+		To:       oldEmail,
+		Template: "email_changed",
+		Subject:  "Email вашего аккаунта изменен",
+		Data: map[string]interface{}{
+			"user_email": oldEmail,
+			"new_email":  newEmail,
+			"revert_url": fmt.Sprintf("%s/revert-email?token=%s", s.publicURL, token),
+		},
+	}
+
+	if err := s.rabbitmq.PublishEmail(event); err != nil {
+		s.log.Error("failed to publish email event", "error", err)
+	}
+}
+
+// RevertEmailChange restores the previous address, as long as the account
+// still has the address the link was sent about. Whoever changed it may
+// still know the password, so all sessions and any change they requested
+// since are ended as well.
+func (s *service) RevertEmailChange(ctx context.Context, req *RevertEmailChangeRequest) error {
+	var revert EmailRevert
+	if err := s.redis.ConsumeEmailRevert(ctx, req.Token, &revert); err != nil {
+		s.log.Warn("invalid or expired email revert token", "error", err)
+		return fmt.Errorf("неверная или устаревшая ссылка")
+	}
+
+	id, err := uuid.Parse(revert.UserID)
+	if err != nil {
+		s.log.Error("failed to parse user id", "error", err, "user_id", revert.UserID)
+		return fmt.Errorf("произошла ошибка")
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if stderrors.Is(err, ErrUserNotFound) {
+			return err
+		}
+		s.log.Error("failed to get user", "error", err, "user_id", revert.UserID)
+		return fmt.Errorf("произошла ошибка")
+	}
+
+	// The address changed again since the link was sent: reverting now would
+	// undo that later change rather than this one.
+	if user.Email != revert.NewEmail {
+		s.log.Warn("stale email revert token", "user_id", revert.UserID)
+		return fmt.Errorf("неверная или устаревшая ссылка")
+	}
+
+	if err := s.repo.UpdateEmail(ctx, id, revert.Email); err != nil {
+		if stderrors.Is(err, ErrUserAlreadyExists) || stderrors.Is(err, ErrUserNotFound) {
+			return err
+		}
+		s.log.Error("failed to revert email", "error", err, "user_id", revert.UserID)
+		return fmt.Errorf("не удалось восстановить email")
+	}
+
+	if err := s.revokeAllTokens(ctx, revert.UserID); err != nil {
+		s.log.Error("failed to revoke tokens after email revert", "error", err, "user_id", revert.UserID)
+	}
+
+	if err := s.redis.DeleteEmailChange(ctx, revert.UserID); err != nil {
+		s.log.Error("failed to drop pending email change", "error", err, "user_id", revert.UserID)
+	}
+
+	s.log.Info("email change reverted", "user_id", revert.UserID)
+	return nil
+}
@@ -0,0 +1,175 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newEmailChangeUser(t *testing.T, s *service) *User {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password-1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	password := string(hash)
+	return s.repo.(*fakeRepository).add(&User{Email: "old@example.com", EmailConfirmed: true, Password: &password})
+}
+
+// requestEmailChange asks for a change and returns the token of the link
+// sent to the new address.
+func requestEmailChange(t *testing.T, s *service, mr *miniredis.Miniredis, user *User, email string) string {
+	t.Helper()
+
+	err := s.ChangeEmail(context.Background(), user.ID.String(), &ChangeEmailRequest{Email: email, Password: "password-1"})
+	if err != nil {
+		t.Fatalf("ChangeEmail: %v", err)
+	}
+
+	token, err := mr.Get("email_confirm:user:" + user.ID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func currentEmail(t *testing.T, s *service, user *User) string {
+	t.Helper()
+
+	current, err := s.repo.GetByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return current.Email
+}
+
+func TestChangeEmailInvalidatesPreviousLink(t *testing.T) {
+	ctx := context.Background()
+	s, mr := newTestService(t)
+	user := newEmailChangeUser(t, s)
+
+	first := requestEmailChange(t, s, mr, user, "attacker@example.com")
+	second := requestEmailChange(t, s, mr, user, "new@example.com")
+
+	if err := s.ConfirmEmail(ctx, first); err == nil {
+		t.Error("the link of an earlier change was accepted")
+	}
+	if got := currentEmail(t, s, user); got != "old@example.com" {
+		t.Fatalf("email = %q, want old@example.com", got)
+	}
+
+	if err := s.ConfirmEmail(ctx, second); err != nil {
+		t.Fatalf("ConfirmEmail: %v", err)
+	}
+	if got := currentEmail(t, s, user); got != "new@example.com" {
+		t.Errorf("email = %q, want new@example.com", got)
+	}
+}
+
+func TestPendingEmailChangeIsDropped(t *testing.T) {
+	tests := []struct {
+		name   string
+		action func(t *testing.T, s *service, user *User)
+	}{
+		{"password reset", func(t *testing.T, s *service, user *User) {
+			if err := s.redis.StorePasswordReset(context.Background(), user.ID.String(), "reset-token"); err != nil {
+				t.Fatal(err)
+			}
+			err := s.ResetPassword(context.Background(), &ResetPasswordRequest{Token: "reset-token", Password: "password-2"})
+			if err != nil {
+				t.Fatalf("ResetPassword: %v", err)
+			}
+		}},
+		{"password change", func(t *testing.T, s *service, user *User) {
+			err := s.ChangePassword(context.Background(), user.ID.String(), "", &ChangePasswordRequest{
+				OldPassword: "password-1",
+				NewPassword: "password-2",
+			})
+			if err != nil {
+				t.Fatalf("ChangePassword: %v", err)
+			}
+		}},
+		{"email revert", func(t *testing.T, s *service, user *User) {
+			revert := EmailRevert{UserID: user.ID.String(), Email: "first@example.com", NewEmail: user.Email}
+			if err := s.redis.StoreEmailRevert(context.Background(), "revert-token", revert, time.Hour); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.RevertEmailChange(context.Background(), &RevertEmailChangeRequest{Token: "revert-token"}); err != nil {
+				t.Fatalf("RevertEmailChange: %v", err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, mr := newTestService(t)
+			user := newEmailChangeUser(t, s)
+			token := requestEmailChange(t, s, mr, user, "attacker@example.com")
+
+			tt.action(t, s, user)
+			email := currentEmail(t, s, user)
+
+			if err := s.ConfirmEmail(context.Background(), token); err == nil {
+				t.Error("the pending email change was confirmed")
+			}
+			if got := currentEmail(t, s, user); got != email {
+				t.Errorf("email = %q, want %q", got, email)
+			}
+		})
+	}
+}
+
+func TestDeleteEmailChangeKeepsSignUpConfirmation(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+	user := s.repo.(*fakeRepository).add(&User{Email: "user@example.com"})
+	userID := user.ID.String()
+
+	if err := s.redis.StoreEmailConfirmation(ctx, userID, user.Email, "signup-token"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.redis.DeleteEmailChange(ctx, userID); err != nil {
+		t.Fatalf("DeleteEmailChange: %v", err)
+	}
+
+	if got, err := s.redis.GetEmailConfirmationUserID(ctx, "signup-token"); err != nil || got != userID {
+		t.Errorf("sign-up confirmation = %q, %v, want %s", got, err, userID)
+	}
+}
+
+func TestRevertEmailChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		newEmail  string
+		wantErr   bool
+		wantEmail string
+	}{
+		{"latest change", "new@example.com", false, "first@example.com"},
+		{"changed again since", "between@example.com", true, "new@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, _ := newTestService(t)
+			user := s.repo.(*fakeRepository).add(&User{Email: "new@example.com", EmailConfirmed: true})
+
+			revert := EmailRevert{UserID: user.ID.String(), Email: "first@example.com", NewEmail: tt.newEmail}
+			if err := s.redis.StoreEmailRevert(ctx, "revert-token", revert, time.Hour); err != nil {
+				t.Fatal(err)
+			}
+
+			err := s.RevertEmailChange(ctx, &RevertEmailChangeRequest{Token: "revert-token"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RevertEmailChange() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := currentEmail(t, s, user); got != tt.wantEmail {
+				t.Errorf("email = %q, want %q", got, tt.wantEmail)
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "sportmates-test"
+	testClientSecret = "client-secret"
+	testRedirectURL  = "https://sportmates.test/auth/google/callback"
+	testKeyID        = "test-key"
+)
+
+// fakeIssuer is a minimal OpenID provider: discovery, JWKS and a token
+// endpoint that checks the PKCE verifier against the recorded challenge.
+type fakeIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+type authorization struct {
+	challenge string
+	nonce     string
+	subject   string
+	email     string
+	verified  bool
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := &fakeIssuer{key: key, codes: make(map[string]authorization)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("/jwks", issuer.jwks)
+	mux.HandleFunc("/token", issuer.token)
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+// authorize plays the user consenting on the provider's page for the request
+// in authURL and returns the code the provider redirects back with.
+func (i *fakeIssuer) authorize(t *testing.T, authURL string, auth authorization) (state, code string) {
+	t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("auth URL has no S256 code challenge: %s", authURL)
+	}
+	if query.Get("nonce") == "" || query.Get("state") == "" {
+		t.Fatalf("auth URL has no state or nonce: %s", authURL)
+	}
+
+	if auth.challenge == "" {
+		auth.challenge = query.Get("code_challenge")
+	}
+	if auth.nonce == "" {
+		auth.nonce = query.Get("nonce")
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		t.Fatal(err)
+	}
+	code = hex.EncodeToString(raw)
+
+	i.mu.Lock()
+	i.codes[code] = auth
+	i.mu.Unlock()
+
+	return query.Get("state"), code
+}
+
+func (i *fakeIssuer) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                i.server.URL,
+		"authorization_endpoint":                i.server.URL + "/authorize",
+		"token_endpoint":                        i.server.URL + "/token",
+		"jwks_uri":                              i.server.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (i *fakeIssuer) jwks(w http.ResponseWriter, r *http.Request) {
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": testKeyID,
+			"n":   encode(i.key.N.Bytes()),
+			"e":   encode(big.NewInt(int64(i.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (i *fakeIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != testClientID || clientSecret != testClientSecret {
+		writeTokenError(w, "invalid_client")
+		return
+	}
+
+	i.mu.Lock()
+	auth, ok := i.codes[r.PostForm.Get("code")]
+	delete(i.codes, r.PostForm.Get("code"))
+	i.mu.Unlock()
+	if !ok {
+		writeTokenError(w, "invalid_grant")
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge {
+		writeTokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            i.server.URL,
+		"aud":            testClientID,
+		"sub":            auth.subject,
+		"email":          auth.email,
+		"email_verified": auth.verified,
+		"nonce":          auth.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	})
+	idToken.Header["kid"] = testKeyID
+
+	signed, err := idToken.SignedString(i.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+func writeTokenError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+func newGoogleTestService(t *testing.T) (*service, *fakeRepository, *fakeIssuer) {
+	t.Helper()
+
+	issuer := newFakeIssuer(t)
+	s, _ := newTestService(t)
+
+	google, err := NewGoogleAuthenticator(context.Background(), GoogleOAuthConfig{
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		IssuerURL:    issuer.server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewGoogleAuthenticator: %v", err)
+	}
+	s.google = google
+
+	return s, s.repo.(*fakeRepository), issuer
+}
+
+func TestGoogleCallbackCreatesAccount(t *testing.T) {
+	ctx := context.Background()
+	s, repo, issuer := newGoogleTestService(t)
+
+	authURL, err := s.GetGoogleAuthURL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, code := issuer.authorize(t, authURL, authorization{subject: "google-1", email: "new@example.com", verified: true})
+
+	response, challenge, err := s.GoogleCallback(ctx, state, code, DeviceInfo{})
+	if err != nil {
+		t.Fatalf("GoogleCallback: %v", err)
+	}
+	if challenge != nil || response == nil || response.AccessToken == "" {
+		t.Fatalf("expected tokens, got response %+v, challenge %+v", response, challenge)
+	}
+
+	user, err := repo.GetByProviderSubject(ctx, GoogleProvider, "google-1")
+	if err != nil {
+		t.Fatalf("account was not created: %v", err)
+	}
+	if user.ID.String() != response.UserID || user.Email != "new@example.com" || !user.EmailConfirmed {
+		t.Errorf("unexpected account %+v for response %+v", user, response)
+	}
+
+	// Signing in again finds the account by its subject.
+	authURL, err = s.GetGoogleAuthURL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, code = issuer.authorize(t, authURL, authorization{subject: "google-1", email: "new@example.com", verified: true})
+
+	again, _, err := s.GoogleCallback(ctx, state, code, DeviceInfo{})
+	if err != nil {
+		t.Fatalf("second GoogleCallback: %v", err)
+	}
+	if again.UserID != response.UserID || repo.count() != 1 {
+		t.Errorf("second sign-in created another account")
+	}
+}
+
+func TestGoogleCallbackLinksExistingAccount(t *testing.T) {
+	ctx := context.Background()
+	s, repo, issuer := newGoogleTestService(t)
+
+	existing := repo.add(&User{Email: "linked@example.com", Provider: GoogleProvider})
+
+	authURL, err := s.GetGoogleAuthURL(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, code := issuer.authorize(t, authURL, authorization{subject: "google-2", email: "linked@example.com", verified: true})
+
+	response, _, err := s.GoogleCallback(ctx, state, code, DeviceInfo{})
+	if err != nil {
+		t.Fatalf("GoogleCallback: %v", err)
+	}
+	if response.UserID != existing.ID.String() {
+		t.Fatalf("signed in as %s, want existing account %s", response.UserID, existing.ID)
+	}
+
+	user, err := repo.GetByID(ctx, existing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ProviderSubject == nil || *user.ProviderSubject != "google-2" || !user.EmailConfirmed {
+		t.Errorf("account was not linked: %+v", user)
+	}
+	if repo.count() != 1 {
+		t.Errorf("got %d accounts, want 1", repo.count())
+	}
+}
+
+func TestGoogleCallbackRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare returns the state and code passed to the callback.
+		prepare func(t *testing.T, s *service, issuer *fakeIssuer) (string, string)
+		// wantErr is checked when set; any error passes otherwise.
+		wantErr error
+	}{
+		{
+			name: "state mismatch",
+			prepare: func(t *testing.T, s *service, issuer *fakeIssuer) (string, string) {
+				authURL, _ := s.GetGoogleAuthURL(context.Background())
+				_, code := issuer.authorize(t, authURL, authorization{subject: "google-3", email: "a@example.com", verified: true})
+				return "forged-state", code
+			},
+		},
+		{
+			name: "state replay",
+			prepare: func(t *testing.T, s *service, issuer *fakeIssuer) (string, string) {
+				authURL, _ := s.GetGoogleAuthURL(context.Background())
+				state, code := issuer.authorize(t, authURL, authorization{subject: "google-3", email: "a@example.com", verified: true})
+				if _, _, err := s.GoogleCallback(context.Background(), state, code, DeviceInfo{}); err != nil {
+					t.Fatalf("first callback: %v", err)
+				}
+				_, code = issuer.authorize(t, authURL, authorization{subject: "google-3", email: "a@example.com", verified: true})
+				return state, code
+			},
+		},
+		{
+			name: "nonce mismatch",
+			prepare: func(t *testing.T, s *service, issuer *fakeIssuer) (string, string) {
+				authURL, _ := s.GetGoogleAuthURL(context.Background())
+				return issuer.authorize(t, authURL, authorization{nonce: "other-nonce", subject: "google-3", email: "a@example.com", verified: true})
+			},
+			wantErr: ErrOAuthFailed,
+		},
+		{
+			// The code was issued for another authorization request, so the
+			// stored verifier does not match its challenge.
+			name: "PKCE verifier mismatch",
+			prepare: func(t *testing.T, s *service, issuer *fakeIssuer) (string, string) {
+				authURL, _ := s.GetGoogleAuthURL(context.Background())
+				otherURL, _ := s.GetGoogleAuthURL(context.Background())
+				state, _ := issuer.authorize(t, authURL, authorization{})
+
+				other, _ := url.Parse(otherURL)
+				_, code := issuer.authorize(t, authURL, authorization{
+					challenge: other.Query().Get("code_challenge"),
+					subject:   "google-3",
+					email:     "a@example.com",
+					verified:  true,
+				})
+				return state, code
+			},
+			wantErr: ErrOAuthFailed,
+		},
+		{
+			name: "unknown code",
+			prepare: func(t *testing.T, s *service, issuer *fakeIssuer) (string, string) {
+				authURL, _ := s.GetGoogleAuthURL(context.Background())
+				state, _ := issuer.authorize(t, authURL, authorization{})
+				return state, "unknown-code"
+			},
+			wantErr: ErrOAuthFailed,
+		},
+		{
+			name: "unverified email",
+			prepare: func(t *testing.T, s *service, issuer *fakeIssuer) (string, string) {
+				authURL, _ := s.GetGoogleAuthURL(context.Background())
+				return issuer.authorize(t, authURL, authorization{subject: "google-4", email: "b@example.com", verified: false})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, issuer := newGoogleTestService(t)
+
+			state, code := tt.prepare(t, s, issuer)
+			before := repo.count()
+
+			response, challenge, err := s.GoogleCallback(context.Background(), state, code, DeviceInfo{})
+			if err == nil {
+				t.Fatalf("GoogleCallback succeeded: %+v %+v", response, challenge)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if repo.count() != before {
+				t.Errorf("a rejected callback created an account")
+			}
+		})
+	}
+}
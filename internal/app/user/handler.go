@@ -2,10 +2,15 @@ package user
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 
 	validation "github.com/RuLap/sportmates-api/internal/pkg/validator"
 	"github.com/darahayes/go-boom"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
@@ -29,7 +34,7 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.service.Register(r.Context(), req)
+	response, err := h.service.Register(r.Context(), req, deviceFromRequest(r))
 	if err != nil {
 		boom.BadRequest(w, err.Error())
 		return
@@ -50,7 +55,190 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.service.Login(r.Context(), req)
+	response, challenge, err := h.service.Login(r.Context(), req, deviceFromRequest(r))
+	if err != nil {
+		var lockedErr *LoginLockedError
+		if errors.As(err, &lockedErr) {
+			retryAfter := int(math.Ceil(lockedErr.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			boom.TooManyRequests(w, err.Error())
+			return
+		}
+		if errors.Is(err, ErrUserBanned) {
+			boom.Forbidden(w, err.Error())
+			return
+		}
+		boom.BadRequest(w, err.Error())
+		return
+	}
+
+	h.sendLoginResult(w, response, challenge)
+}
+
+func (h *Handler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req LoginTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	response, err := h.service.LoginTwoFactor(r.Context(), &req, deviceFromRequest(r))
+	if err != nil {
+		if errors.Is(err, ErrUserBanned) {
+			boom.Forbidden(w, err.Error())
+			return
+		}
+		boom.Unathorized(w, err.Error())
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) SendMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req MagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	if err := h.service.SendMagicLink(r.Context(), &req, deviceFromRequest(r)); err != nil {
+		boom.BadRequest(w, err.Error())
+		return
+	}
+
+	h.sendJSON(w, map[string]interface{}{
+		"success": true,
+		"message": "Если аккаунт с таким email существует, мы отправили на него ссылку для входа",
+	}, http.StatusOK)
+}
+
+func (h *Handler) VerifyMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req VerifyMagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	response, challenge, err := h.service.VerifyMagicLink(r.Context(), &req, deviceFromRequest(r))
+	if err != nil {
+		if errors.Is(err, ErrUserBanned) {
+			boom.Forbidden(w, err.Error())
+			return
+		}
+		boom.Unathorized(w, err.Error())
+		return
+	}
+
+	h.sendLoginResult(w, response, challenge)
+}
+
+func (h *Handler) SendPhoneCode(w http.ResponseWriter, r *http.Request) {
+	var req SendPhoneCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	response, err := h.service.SendPhoneCode(r.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPhoneCodeCooldown), errors.Is(err, ErrPhoneCodeLimit):
+			boom.TooManyRequests(w, err.Error())
+		case errors.Is(err, ErrPhoneUnavailable):
+			boom.ServerUnavailable(w, err.Error())
+		default:
+			boom.BadRequest(w, err.Error())
+		}
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) VerifyPhoneCode(w http.ResponseWriter, r *http.Request) {
+	var req VerifyPhoneCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	response, challenge, err := h.service.VerifyPhoneCode(r.Context(), &req, deviceFromRequest(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidPhone):
+			boom.BadRequest(w, err.Error())
+		case errors.Is(err, ErrUserBanned):
+			boom.Forbidden(w, err.Error())
+		default:
+			boom.Unathorized(w, err.Error())
+		}
+		return
+	}
+
+	h.sendLoginResult(w, response, challenge)
+}
+
+func (h *Handler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		boom.Unathorized(w, "требуется аутентификация")
+		return
+	}
+
+	response, err := h.service.EnrollTwoFactor(r.Context(), userID)
+	if err != nil {
+		boom.BadRequest(w, err.Error())
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
+func (h *Handler) VerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		boom.Unathorized(w, "требуется аутентификация")
+		return
+	}
+
+	var req VerifyTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	response, err := h.service.VerifyTwoFactor(r.Context(), userID, &req)
 	if err != nil {
 		boom.BadRequest(w, err.Error())
 		return
@@ -59,6 +247,35 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	h.sendJSON(w, response, http.StatusOK)
 }
 
+func (h *Handler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		boom.Unathorized(w, "требуется аутентификация")
+		return
+	}
+
+	var req DisableTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	if err := h.service.DisableTwoFactor(r.Context(), userID, &req); err != nil {
+		boom.BadRequest(w, err.Error())
+		return
+	}
+
+	h.sendJSON(w, map[string]interface{}{
+		"success": true,
+		"message": "Двухфакторная аутентификация отключена",
+	}, http.StatusOK)
+}
+
 func (h *Handler) SendConfirmationLink(w http.ResponseWriter, r *http.Request) {
 	var req SendConfirmationEmailRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -111,6 +328,179 @@ func (h *Handler) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
 	}, http.StatusOK)
 }
 
+func (h *Handler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		boom.Unathorized(w, "требуется аутентификация")
+		return
+	}
+
+	var req ChangeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	if err := h.service.ChangeEmail(r.Context(), userID, &req); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidPassword):
+			boom.Forbidden(w, err.Error())
+		case errors.Is(err, ErrUserAlreadyExists):
+			boom.Conflict(w, err.Error())
+		default:
+			boom.BadRequest(w, err.Error())
+		}
+		return
+	}
+
+	h.sendJSON(w, map[string]interface{}{
+		"success": true,
+		"message": "Ссылка для подтверждения отправлена на новый email",
+	}, http.StatusOK)
+}
+
+func (h *Handler) RevertEmailChange(w http.ResponseWriter, r *http.Request) {
+	var req RevertEmailChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	if err := h.service.RevertEmailChange(r.Context(), &req); err != nil {
+		boom.BadRequest(w, err.Error())
+		return
+	}
+
+	h.sendJSON(w, map[string]interface{}{
+		"success": true,
+		"message": "Прежний email восстановлен, войдите в аккаунт заново",
+	}, http.StatusOK)
+}
+
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	if err := h.service.ForgotPassword(r.Context(), &req); err != nil {
+		boom.BadRequest(w, err.Error())
+		return
+	}
+
+	h.sendJSON(w, map[string]interface{}{
+		"success": true,
+		"message": "Если аккаунт с таким email существует, мы отправили на него ссылку для сброса пароля",
+	}, http.StatusOK)
+}
+
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	if err := h.service.ResetPassword(r.Context(), &req); err != nil {
+		boom.BadRequest(w, err.Error())
+		return
+	}
+
+	h.sendJSON(w, map[string]interface{}{
+		"success": true,
+		"message": "Пароль успешно изменен",
+	}, http.StatusOK)
+}
+
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		boom.Unathorized(w, "требуется аутентификация")
+		return
+	}
+	sessionID, _ := r.Context().Value("session_id").(string)
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	if err := h.service.ChangePassword(r.Context(), userID, sessionID, &req); err != nil {
+		if errors.Is(err, ErrInvalidPassword) {
+			boom.Forbidden(w, err.Error())
+			return
+		}
+		boom.BadRequest(w, err.Error())
+		return
+	}
+
+	h.sendJSON(w, map[string]interface{}{
+		"success": true,
+		"message": "Пароль успешно изменен",
+	}, http.StatusOK)
+}
+
+func (h *Handler) GoogleStart(w http.ResponseWriter, r *http.Request) {
+	url, err := h.service.GetGoogleAuthURL(r.Context())
+	if err != nil {
+		boom.BadRequest(w, err.Error())
+		return
+	}
+
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+func (h *Handler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("error") != "" {
+		boom.BadRequest(w, "вход через Google отменен")
+		return
+	}
+
+	state := query.Get("state")
+	code := query.Get("code")
+	if state == "" || code == "" {
+		boom.BadRequest(w, "state и code обязательны")
+		return
+	}
+
+	response, challenge, err := h.service.GoogleCallback(r.Context(), state, code, deviceFromRequest(r))
+	if err != nil {
+		boom.BadRequest(w, err.Error())
+		return
+	}
+
+	h.sendLoginResult(w, response, challenge)
+}
+
 func (h *Handler) CheckEmailConfirmed(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("user_id").(string)
 	if !ok {
@@ -149,7 +539,7 @@ func (h *Handler) RefreshTokens(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := h.service.RefreshTokens(r.Context(), req.RefreshToken)
+	tokens, err := h.service.RefreshTokens(r.Context(), req.RefreshToken, deviceFromRequest(r))
 	if err != nil {
 		boom.Unathorized(w, "Не удалось обновить токены")
 		return
@@ -166,7 +556,9 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.service.Logout(r.Context(), userID)
+	sessionID, _ := r.Context().Value("session_id").(string)
+
+	err := h.service.Logout(r.Context(), userID, sessionID)
 	if err != nil {
 		boom.Internal(w, "Не удалось выполнить выход")
 		return
@@ -175,6 +567,178 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+func (h *Handler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		boom.Unathorized(w, "требуется аутентификация")
+		return
+	}
+
+	sessionID, _ := r.Context().Value("session_id").(string)
+
+	sessions, err := h.service.GetSessions(r.Context(), userID, sessionID)
+	if err != nil {
+		boom.Internal(w, err.Error())
+		return
+	}
+
+	h.sendJSON(w, sessions, http.StatusOK)
+}
+
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		boom.Unathorized(w, "требуется аутентификация")
+		return
+	}
+
+	err := h.service.RevokeSession(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			boom.NotFound(w, err.Error())
+			return
+		}
+		boom.Internal(w, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		boom.Unathorized(w, "требуется аутентификация")
+		return
+	}
+
+	if err := h.service.RevokeSessions(r.Context(), userID); err != nil {
+		boom.Internal(w, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetUserForAdmin(w http.ResponseWriter, r *http.Request) {
+	user, err := h.service.GetUserForAdmin(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleAdminError(w, err)
+		return
+	}
+
+	h.sendJSON(w, user, http.StatusOK)
+}
+
+func (h *Handler) BanUser(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		boom.Unathorized(w, "требуется аутентификация")
+		return
+	}
+
+	moderatorRoles, _ := r.Context().Value("user_roles").([]string)
+
+	var req BanUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	err := h.service.BanUser(r.Context(), moderatorID, moderatorRoles, chi.URLParam(r, "id"), &req)
+	if err != nil {
+		h.handleAdminError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) UnbanUser(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		boom.Unathorized(w, "требуется аутентификация")
+		return
+	}
+
+	moderatorRoles, _ := r.Context().Value("user_roles").([]string)
+
+	if err := h.service.UnbanUser(r.Context(), moderatorID, moderatorRoles, chi.URLParam(r, "id")); err != nil {
+		h.handleAdminError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) SetRoles(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value("user_id").(string)
+	if !ok {
+		boom.Unathorized(w, "требуется аутентификация")
+		return
+	}
+
+	var req SetRolesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		boom.BadRequest(w, "неверный формат JSON")
+		return
+	}
+
+	if errors := validation.ValidateStruct(req); errors != nil {
+		boom.BadRequest(w, "Ошибки валидации", errors)
+		return
+	}
+
+	user, err := h.service.SetRoles(r.Context(), adminID, chi.URLParam(r, "id"), &req)
+	if err != nil {
+		h.handleAdminError(w, err)
+		return
+	}
+
+	h.sendJSON(w, user, http.StatusOK)
+}
+
+func (h *Handler) handleAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		boom.NotFound(w, err.Error())
+	case errors.Is(err, ErrForbidden):
+		boom.Forbidden(w, err.Error())
+	default:
+		boom.BadRequest(w, err.Error())
+	}
+}
+
+// deviceFromRequest relies on the RealIP middleware, which replaces RemoteAddr
+// with the forwarded client address only for requests from trusted proxies.
+func deviceFromRequest(r *http.Request) DeviceInfo {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return DeviceInfo{
+		UserAgent: r.UserAgent(),
+		IP:        ip,
+	}
+}
+
+// sendLoginResult writes either the tokens or, for users with 2FA, the
+// challenge to be completed at /users/login/2fa.
+func (h *Handler) sendLoginResult(w http.ResponseWriter, response *AuthResponse, challenge *TwoFactorChallengeResponse) {
+	if challenge != nil {
+		h.sendJSON(w, challenge, http.StatusOK)
+		return
+	}
+
+	h.sendJSON(w, response, http.StatusOK)
+}
+
 func (h *Handler) sendJSON(w http.ResponseWriter, data interface{}, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -0,0 +1,159 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/events"
+)
+
+const (
+	loginFailureWindow     = time.Hour
+	emailFreeLoginAttempts = 5
+	ipFreeLoginAttempts    = 20
+	loginBackoffBase       = 30 * time.Second
+	loginBackoffMax        = time.Hour
+	forgotPasswordPath     = "/forgot-password"
+)
+
+// LoginLockedError is returned while logins are blocked for the email or the
+// client address.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return "слишком много неудачных попыток входа, попробуйте позже"
+}
+
+// loginBackoff doubles the lock for every failure past the free attempts.
+func loginBackoff(failures, freeAttempts int64) time.Duration {
+	if failures < freeAttempts {
+		return 0
+	}
+
+	backoff := loginBackoffBase
+	for i := freeAttempts; i < failures && backoff < loginBackoffMax; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, loginBackoffMax)
+}
+
+func emailLoginSubject(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+
+func ipLoginSubject(ip string) string {
+	return "ip:" + ip
+}
+
+func (s *service) checkLoginLock(ctx context.Context, email string, device DeviceInfo) error {
+	subjects := []string{emailLoginSubject(email)}
+	if device.IP != "" {
+		subjects = append(subjects, ipLoginSubject(device.IP))
+	}
+
+	var retryAfter time.Duration
+	for _, subject := range subjects {
+		ttl, err := s.redis.GetLoginLock(ctx, subject)
+		if err != nil {
+			s.log.Error("failed to check login lock", "error", err, "subject", subject)
+			return fmt.Errorf("произошла ошибка")
+		}
+		retryAfter = max(retryAfter, ttl)
+	}
+
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// registerLoginFailure counts the failure for both the email and the address
+// and returns a LoginLockedError if this failure starts a lock. Failures are
+// counted for unknown emails too, so responses do not reveal which accounts
+// exist. The owner is notified when a lock is placed on an existing account.
+func (s *service) registerLoginFailure(ctx context.Context, email string, user *User, device DeviceInfo) error {
+	emailFailures, emailBackoff, err := s.recordLoginFailure(ctx, emailLoginSubject(email), emailFreeLoginAttempts)
+	if err != nil {
+		s.log.Error("failed to record login failure", "error", err, "email", email)
+		return nil
+	}
+
+	var ipBackoff time.Duration
+	if device.IP != "" {
+		_, ipBackoff, err = s.recordLoginFailure(ctx, ipLoginSubject(device.IP), ipFreeLoginAttempts)
+		if err != nil {
+			s.log.Error("failed to record login failure", "error", err, "ip", device.IP)
+		}
+	}
+
+	if emailBackoff > 0 {
+		s.log.Warn("login locked for email", "email", email, "retry_after", emailBackoff)
+		// Only the first lock in a window is reported to avoid flooding the
+		// owner's inbox while an attack goes on.
+		if user != nil && emailFailures == emailFreeLoginAttempts {
+			s.sendAccountLockedEmail(user, device, time.Now().Add(emailBackoff))
+		}
+	}
+	if ipBackoff > 0 {
+		s.log.Warn("login locked for address", "ip", device.IP, "retry_after", ipBackoff)
+	}
+
+	if retryAfter := max(emailBackoff, ipBackoff); retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+func (s *service) recordLoginFailure(ctx context.Context, subject string, freeAttempts int64) (int64, time.Duration, error) {
+	failures, err := s.redis.RecordLoginFailure(ctx, subject, loginFailureWindow)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	backoff := loginBackoff(failures, freeAttempts)
+	if backoff == 0 {
+		return failures, 0, nil
+	}
+
+	if err := s.redis.LockLogin(ctx, subject, backoff); err != nil {
+		return 0, 0, err
+	}
+
+	return failures, backoff, nil
+}
+
+func (s *service) resetLoginFailures(ctx context.Context, email string) {
+	if err := s.redis.ResetLoginFailures(ctx, emailLoginSubject(email)); err != nil {
+		s.log.Warn("failed to reset login failures", "error", err, "email", email)
+	}
+}
+
+func (s *service) sendAccountLockedEmail(user *User, device DeviceInfo, lockedUntil time.Time) {
+	if s.rabbitmq == nil {
+		s.log.Warn("event service not available - email not sent")
+		return
+	}
+
+	event := events.EmailEvent{
+		To:       user.Email,
+		Template: "account_locked",
+		Subject:  "Вход в аккаунт временно заблокирован",
+		Data: map[string]interface{}{
+			"user_email":   user.Email,
+			"locked_until": lockedUntil.UTC().Format("02.01.2006 15:04"),
+			"ip":           device.IP,
+			"reset_url":    s.publicURL + forgotPasswordPath,
+		},
+	}
+
+	if err := s.rabbitmq.PublishEmail(event); err != nil {
+		s.log.Error("failed to publish email event", "error", err)
+	}
+}
@@ -0,0 +1,122 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/events"
+	"github.com/google/uuid"
+)
+
+const magicLinkTTL = 15 * time.Minute
+
+// SendMagicLink emails a one-time sign-in link. Like ForgotPassword it never
+// reports whether the email is registered.
+func (s *service) SendMagicLink(ctx context.Context, req *MagicLinkRequest, device DeviceInfo) error {
+	user, err := s.repo.GetByEmailProvider(ctx, req.Email, LocalProvider)
+	if err != nil {
+		s.log.Info("magic link requested for unknown email", "email", req.Email)
+		return nil
+	}
+
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		s.log.Error("failed to generate token", "error", err)
+		return nil
+	}
+	token := hex.EncodeToString(rawToken)
+
+	userID := user.ID.String()
+	link := MagicLink{
+		UserID:      userID,
+		Fingerprint: deviceFingerprint(req.DeviceID, device),
+	}
+	if err := s.redis.StoreMagicLink(ctx, userID, token, link, magicLinkTTL); err != nil {
+		s.log.Error("failed to store magic link in redis", "error", err, "user_id", userID)
+		return nil
+	}
+
+	loginURL := fmt.Sprintf("%s/login/magic-link?token=%s", s.publicURL, token)
+
+	if s.rabbitmq != nil {
+		event := events.EmailEvent{
+			To:       user.Email,
+			Template: "magic_link",
+			Subject:  "Вход в Sportmates",
+			Data: map[string]interface{}{
+				"login_url":          loginURL,
+				"user_email":         user.Email,
+				"expires_in_minutes": int(magicLinkTTL.Minutes()),
+			},
+		}
+
+		if err := s.rabbitmq.PublishEmail(event); err != nil {
+			s.log.Error("failed to publish email event", "error", err)
+		}
+	} else {
+		s.log.Warn("event service not available - email not sent")
+	}
+
+	s.log.Info("magic link generated and sent", "user_id", userID)
+	return nil
+}
+
+// VerifyMagicLink signs the user in. The link is consumed before the device
+// check, so a link opened on another device cannot be retried. Following the
+// link proves access to the mailbox, so the email is confirmed as well.
+func (s *service) VerifyMagicLink(ctx context.Context, req *VerifyMagicLinkRequest, device DeviceInfo) (*AuthResponse, *TwoFactorChallengeResponse, error) {
+	var link MagicLink
+	if err := s.redis.ConsumeMagicLink(ctx, req.Token, &link); err != nil {
+		s.log.Warn("invalid or expired magic link", "error", err)
+		return nil, nil, fmt.Errorf("неверная или устаревшая ссылка для входа")
+	}
+
+	fingerprint := deviceFingerprint(req.DeviceID, device)
+	if link.Fingerprint != "" && subtle.ConstantTimeCompare([]byte(link.Fingerprint), []byte(fingerprint)) != 1 {
+		s.log.Warn("magic link used from another device", "user_id", link.UserID, "ip", device.IP)
+		return nil, nil, fmt.Errorf("ссылку для входа нужно открыть на устройстве, с которого она запрошена")
+	}
+
+	id, err := uuid.Parse(link.UserID)
+	if err != nil {
+		s.log.Error("failed to parse user id", "error", err, "user_id", link.UserID)
+		return nil, nil, fmt.Errorf("произошла ошибка")
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get user", "error", err, "user_id", link.UserID)
+		return nil, nil, fmt.Errorf("неверная или устаревшая ссылка для входа")
+	}
+
+	if !user.EmailConfirmed {
+		if err := s.repo.MakeEmailConfirmed(ctx, link.UserID); err != nil {
+			s.log.Warn("failed to confirm email on magic link login", "error", err, "user_id", link.UserID)
+		}
+	}
+
+	response, challenge, err := s.completeLogin(ctx, link.UserID, user.Email, device)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.log.Info("user logged in with magic link", "user_id", link.UserID)
+	return response, challenge, nil
+}
+
+// deviceFingerprint identifies the client by the device ID it sent, if any,
+// and its user agent. The IP is left out: it often changes between
+// requesting the link and opening it.
+func deviceFingerprint(deviceID string, device DeviceInfo) string {
+	if deviceID == "" && device.UserAgent == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(deviceID + "\n" + device.UserAgent))
+	return hex.EncodeToString(sum[:])
+}
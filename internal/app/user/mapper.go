@@ -1,5 +1,7 @@
 package user
 
+import "time"
+
 func LoginRequestToUser(dto *LoginRequest, hashedPassword string) *User {
 	return &User{
 		Email:    dto.Email,
@@ -14,3 +16,33 @@ func RegisterRequestToUser(dto *RegisterRequest, hashedPassword string) *User {
 		EmailConfirmed: false,
 	}
 }
+
+func SessionToResponse(session *Session, currentSessionID string) *SessionResponse {
+	return &SessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+		LastUsedAt: session.LastUsedAt.Format(time.RFC3339),
+		Current:    session.ID == currentSessionID,
+	}
+}
+
+func UserToAdminResponse(user *User, roles []string) *AdminUserResponse {
+	result := &AdminUserResponse{
+		ID:             user.ID.String(),
+		Email:          user.Email,
+		EmailConfirmed: user.EmailConfirmed,
+		Phone:          user.Phone,
+		Provider:       string(user.Provider),
+		Roles:          roles,
+		BanReason:      user.BanReason,
+	}
+
+	if user.BannedAt != nil {
+		bannedAt := user.BannedAt.Format(time.RFC3339)
+		result.BannedAt = &bannedAt
+	}
+
+	return result
+}
@@ -1,21 +1,96 @@
 package user
 
-import uuid "github.com/google/uuid"
+import (
+	"time"
+
+	uuid "github.com/google/uuid"
+)
 
 type Provider string
 
 const (
 	LocalProvider  Provider = "local"
 	GoogleProvider Provider = "google"
+	PhoneProvider  Provider = "phone"
 )
 
 func (p Provider) IsValid() bool {
-	return p == LocalProvider || p == GoogleProvider
+	return p == LocalProvider || p == GoogleProvider || p == PhoneProvider
 }
 
+const (
+	AdminRole     = "admin"
+	ModeratorRole = "moderator"
+)
+
 type User struct {
-	ID             uuid.UUID `db:"id"`
-	Email          string    `db:"email"`
-	EmailConfirmed bool      `db:"email_confirmed"`
-	Password       *string   `db:"password"`
+	ID              uuid.UUID  `db:"id"`
+	Email           string     `db:"email"`
+	EmailConfirmed  bool       `db:"email_confirmed"`
+	Password        *string    `db:"password"`
+	Provider        Provider   `db:"provider"`
+	ProviderSubject *string    `db:"provider_subject"`
+	Phone           *string    `db:"phone"`
+	PhoneConfirmed  bool       `db:"phone_confirmed"`
+	BannedAt        *time.Time `db:"banned_at"`
+	BanReason       *string    `db:"ban_reason"`
+}
+
+func (u *User) IsBanned() bool {
+	return u.BannedAt != nil
+}
+
+type OAuthState struct {
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// PhoneCode is a pending SMS code. Only its hash is kept.
+type PhoneCode struct {
+	CodeHash string `json:"code_hash"`
+}
+
+// MagicLink is a pending passwordless sign-in. The fingerprint is empty when
+// the requesting device could not be identified.
+type MagicLink struct {
+	UserID      string `json:"user_id"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// EmailRevert lets the owner of the previous address undo an email change.
+type EmailRevert struct {
+	UserID   string `json:"user_id"`
+	Email    string `json:"email"`
+	NewEmail string `json:"new_email"`
+}
+
+type Session struct {
+	ID                   string    `json:"id"`
+	UserID               string    `json:"user_id"`
+	RefreshToken         string    `json:"refresh_token"`
+	AccessTokenID        string    `json:"access_token_id"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	UserAgent            string    `json:"user_agent"`
+	IP                   string    `json:"ip"`
+	CreatedAt            time.Time `json:"created_at"`
+	LastUsedAt           time.Time `json:"last_used_at"`
+}
+
+type DeviceInfo struct {
+	UserAgent string
+	IP        string
+}
+
+type TwoFactor struct {
+	Secret    *string    `db:"totp_secret"`
+	EnabledAt *time.Time `db:"totp_enabled_at"`
+}
+
+func (t *TwoFactor) IsEnabled() bool {
+	return t.EnabledAt != nil && t.Secret != nil
+}
+
+type LoginChallenge struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
 }
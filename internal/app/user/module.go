@@ -6,6 +6,7 @@ import (
 	"github.com/RuLap/sportmates-api/internal/pkg/jwthelper"
 	"github.com/RuLap/sportmates-api/internal/pkg/rabbitmq"
 	"github.com/RuLap/sportmates-api/internal/pkg/redis"
+	"github.com/RuLap/sportmates-api/internal/pkg/sms"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -21,9 +22,15 @@ func NewModule(
 	jwtHelper *jwthelper.JWTHelper,
 	redis *redis.Service,
 	rabbitmq *rabbitmq.Service,
+	google *GoogleAuthenticator,
+	sms sms.Sender,
+	passwords PasswordPolicy,
+	publicURL string,
 ) *Module {
 	repo := NewRepository(pool)
-	service := NewService(log, jwtHelper, redis, rabbitmq, repo)
+	twoFactorRepo := NewTwoFactorRepository(pool)
+	roleRepo := NewRoleRepository(pool)
+	service := NewService(log, jwtHelper, redis, rabbitmq, google, sms, passwords, publicURL, repo, twoFactorRepo, roleRepo)
 	handler := NewHandler(service)
 
 	return &Module{
@@ -0,0 +1,81 @@
+package user
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPasswordBytes is the length bcrypt takes into account. Longer passwords
+// are rejected rather than silently truncated.
+const maxPasswordBytes = 72
+
+const (
+	defaultPasswordMinLength = 8
+	defaultPasswordMaxLength = 32
+)
+
+// PasswordPolicy is checked whenever a password is set: on registration,
+// reset and change. Login only compares against the stored hash, so tightening
+// the policy does not lock out existing users.
+type PasswordPolicy struct {
+	MinLength      int
+	MaxLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSpecial bool
+}
+
+func (p PasswordPolicy) withDefaults() PasswordPolicy {
+	if p.MinLength <= 0 {
+		p.MinLength = defaultPasswordMinLength
+	}
+	if p.MaxLength <= 0 {
+		p.MaxLength = defaultPasswordMaxLength
+	}
+	if p.MaxLength > maxPasswordBytes {
+		p.MaxLength = maxPasswordBytes
+	}
+	if p.MinLength > p.MaxLength {
+		p.MinLength = p.MaxLength
+	}
+	return p
+}
+
+func (p PasswordPolicy) Validate(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("пароль должен содержать не менее %d символов", p.MinLength)
+	}
+	if length > p.MaxLength || len(password) > maxPasswordBytes {
+		return fmt.Errorf("пароль должен содержать не более %d символов", p.MaxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSpecial = true
+		}
+	}
+
+	switch {
+	case p.RequireUpper && !hasUpper:
+		return fmt.Errorf("пароль должен содержать заглавную букву")
+	case p.RequireLower && !hasLower:
+		return fmt.Errorf("пароль должен содержать строчную букву")
+	case p.RequireDigit && !hasDigit:
+		return fmt.Errorf("пароль должен содержать цифру")
+	case p.RequireSpecial && !hasSpecial:
+		return fmt.Errorf("пароль должен содержать специальный символ")
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/redis"
+)
+
+const (
+	phoneCodeLength     = 6
+	phoneCodeTTL        = 5 * time.Minute
+	phoneCodeAttempts   = 5
+	phoneCodeCooldown   = time.Minute
+	phoneCodeSendLimit  = 5
+	phoneCodeSendWindow = time.Hour
+)
+
+var (
+	ErrPhoneUnavailable        = stderrors.New("вход по телефону недоступен")
+	ErrInvalidPhone            = stderrors.New("неверный номер телефона")
+	ErrPhoneCodeCooldown       = stderrors.New("код уже отправлен, запросить новый можно через минуту")
+	ErrPhoneCodeLimit          = stderrors.New("превышено число отправок кода, попробуйте позже")
+	ErrInvalidPhoneCode        = stderrors.New("неверный или устаревший код")
+	ErrTooManyPhoneCodeAttempt = stderrors.New("слишком много попыток, запросите новый код")
+)
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{9,14}$`)
+
+// normalizePhone brings a number to E.164. Russian numbers are often typed
+// with a leading 8 instead of +7.
+func normalizePhone(phone string) (string, error) {
+	phone = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')':
+			return -1
+		}
+		return r
+	}, phone)
+
+	if len(phone) == 11 && strings.HasPrefix(phone, "8") {
+		phone = "+7" + phone[1:]
+	}
+
+	if !e164Pattern.MatchString(phone) {
+		return "", ErrInvalidPhone
+	}
+
+	return phone, nil
+}
+
+// SendPhoneCode texts a sign-in code. The same code signs up a new user, so
+// the response does not depend on whether the phone is registered.
+func (s *service) SendPhoneCode(ctx context.Context, req *SendPhoneCodeRequest) (*PhoneCodeResponse, error) {
+	if s.sms == nil {
+		return nil, ErrPhoneUnavailable
+	}
+
+	phone, err := normalizePhone(req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.redis.StartPhoneCodeCooldown(ctx, phone, phoneCodeCooldown)
+	if err != nil {
+		s.log.Error("failed to check phone code cooldown", "error", err)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+	if !allowed {
+		return nil, ErrPhoneCodeCooldown
+	}
+
+	sends, err := s.redis.CountPhoneCodeSend(ctx, phone, phoneCodeSendWindow)
+	if err != nil {
+		s.log.Error("failed to count phone code sends", "error", err)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+	if sends > phoneCodeSendLimit {
+		s.log.Warn("phone code send limit reached", "phone", phone)
+		return nil, ErrPhoneCodeLimit
+	}
+
+	code, err := generatePhoneCode()
+	if err != nil {
+		s.log.Error("failed to generate phone code", "error", err)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	if err := s.redis.StorePhoneCode(ctx, phone, PhoneCode{CodeHash: hashPhoneCode(phone, code)}, phoneCodeTTL); err != nil {
+		s.log.Error("failed to store phone code in redis", "error", err)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	text := fmt.Sprintf("Код для входа в Sportmates: %s. Никому не сообщайте его.", code)
+	if err := s.sms.Send(ctx, phone, text); err != nil {
+		s.log.Error("failed to send sms", "error", err, "phone", phone)
+		if err := s.redis.DeletePhoneCode(ctx, phone); err != nil {
+			s.log.Warn("failed to delete phone code", "error", err)
+		}
+		return nil, fmt.Errorf("не удалось отправить SMS")
+	}
+
+	s.log.Info("phone code sent", "phone", phone)
+
+	return &PhoneCodeResponse{
+		ExpiresIn:  int64(phoneCodeTTL.Seconds()),
+		RetryAfter: int64(phoneCodeCooldown.Seconds()),
+	}, nil
+}
+
+// VerifyPhoneCode signs the user in by a code, creating the account on first
+// sign-in. Each code allows a few attempts; after that a new one is needed.
+func (s *service) VerifyPhoneCode(ctx context.Context, req *VerifyPhoneCodeRequest, device DeviceInfo) (*AuthResponse, *TwoFactorChallengeResponse, error) {
+	phone, err := normalizePhone(req.Phone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pending PhoneCode
+	if err := s.redis.GetPhoneCode(ctx, phone, &pending); err != nil {
+		if !stderrors.Is(err, redis.ErrNil) {
+			s.log.Error("failed to get phone code", "error", err)
+		}
+		return nil, nil, ErrInvalidPhoneCode
+	}
+
+	attempts, err := s.redis.CountPhoneCodeAttempt(ctx, phone, phoneCodeTTL)
+	if err != nil {
+		s.log.Error("failed to count phone code attempts", "error", err)
+		return nil, nil, fmt.Errorf("произошла ошибка")
+	}
+	if attempts > phoneCodeAttempts {
+		if err := s.redis.DeletePhoneCode(ctx, phone); err != nil {
+			s.log.Warn("failed to delete phone code", "error", err)
+		}
+		s.log.Warn("too many phone code attempts", "phone", phone)
+		return nil, nil, ErrTooManyPhoneCodeAttempt
+	}
+
+	if subtle.ConstantTimeCompare([]byte(pending.CodeHash), []byte(hashPhoneCode(phone, req.Code))) != 1 {
+		return nil, nil, ErrInvalidPhoneCode
+	}
+
+	if err := s.redis.DeletePhoneCode(ctx, phone); err != nil {
+		s.log.Warn("failed to delete phone code", "error", err)
+	}
+
+	userID, email, err := s.findOrCreatePhoneUser(ctx, phone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, challenge, err := s.completeLogin(ctx, userID, email, device)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.log.Info("user logged in by phone", "user_id", userID)
+	return response, challenge, nil
+}
+
+func (s *service) findOrCreatePhoneUser(ctx context.Context, phone string) (string, string, error) {
+	user, err := s.repo.GetByPhone(ctx, phone)
+	if err == nil {
+		return user.ID.String(), user.Email, nil
+	}
+	if !stderrors.Is(err, ErrUserNotFound) {
+		s.log.Error("failed to get user by phone", "error", err)
+		return "", "", fmt.Errorf("произошла ошибка")
+	}
+
+	userID, err := s.repo.CreatePhoneUser(ctx, phone)
+	if err != nil {
+		s.log.Error("failed to create phone user", "error", err)
+		return "", "", fmt.Errorf("произошла ошибка")
+	}
+
+	s.log.Info("user registered by phone", "user_id", *userID)
+	return *userID, "", nil
+}
+
+func generatePhoneCode() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < phoneCodeLength; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", phoneCodeLength, n), nil
+}
+
+func hashPhoneCode(phone, code string) string {
+	sum := sha256.Sum256([]byte(phone + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,279 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testPhone = "+79123456789"
+
+var codePattern = regexp.MustCompile(`\d{6}`)
+
+// fakeSMSSender records messages instead of sending them.
+type fakeSMSSender struct {
+	mu       sync.Mutex
+	messages map[string][]string
+	err      error
+}
+
+func (f *fakeSMSSender) Send(ctx context.Context, phone, text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+	if f.messages == nil {
+		f.messages = make(map[string][]string)
+	}
+	f.messages[phone] = append(f.messages[phone], text)
+	return nil
+}
+
+// lastCode returns the code from the latest message sent to the phone.
+func (f *fakeSMSSender) lastCode(t *testing.T, phone string) string {
+	t.Helper()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	messages := f.messages[phone]
+	if len(messages) == 0 {
+		t.Fatalf("no sms sent to %s", phone)
+	}
+	code := codePattern.FindString(messages[len(messages)-1])
+	if code == "" {
+		t.Fatalf("no code in sms %q", messages[len(messages)-1])
+	}
+	return code
+}
+
+func newPhoneTestService(t *testing.T) (*service, *fakeRepository, *fakeSMSSender, func(time.Duration)) {
+	t.Helper()
+
+	s, mr := newTestService(t)
+	sender := &fakeSMSSender{}
+	s.sms = sender
+
+	return s, s.repo.(*fakeRepository), sender, mr.FastForward
+}
+
+// wrongCode differs from code in every digit.
+func wrongCode(code string) string {
+	wrong := []byte(code)
+	for i, c := range wrong {
+		wrong[i] = '0' + (c-'0'+1)%10
+	}
+	return string(wrong)
+}
+
+func TestPhoneSignUpAndSignIn(t *testing.T) {
+	ctx := context.Background()
+	s, repo, sender, fastForward := newPhoneTestService(t)
+
+	response, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: "8 (912) 345-67-89"})
+	if err != nil {
+		t.Fatalf("SendPhoneCode: %v", err)
+	}
+	if response.ExpiresIn != int64(phoneCodeTTL.Seconds()) || response.RetryAfter != int64(phoneCodeCooldown.Seconds()) {
+		t.Errorf("unexpected response %+v", response)
+	}
+
+	auth, challenge, err := s.VerifyPhoneCode(ctx, &VerifyPhoneCodeRequest{Phone: testPhone, Code: sender.lastCode(t, testPhone)}, DeviceInfo{})
+	if err != nil {
+		t.Fatalf("VerifyPhoneCode: %v", err)
+	}
+	if challenge != nil || auth == nil || auth.AccessToken == "" {
+		t.Fatalf("expected tokens, got %+v, %+v", auth, challenge)
+	}
+
+	user, err := repo.GetByPhone(ctx, testPhone)
+	if err != nil {
+		t.Fatalf("account was not created: %v", err)
+	}
+	if user.ID.String() != auth.UserID || user.Provider != PhoneProvider || !user.PhoneConfirmed {
+		t.Errorf("unexpected account %+v for %+v", user, auth)
+	}
+
+	// The next code signs in to the same account.
+	fastForward(phoneCodeCooldown)
+	if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: testPhone}); err != nil {
+		t.Fatalf("second SendPhoneCode: %v", err)
+	}
+
+	again, _, err := s.VerifyPhoneCode(ctx, &VerifyPhoneCodeRequest{Phone: testPhone, Code: sender.lastCode(t, testPhone)}, DeviceInfo{})
+	if err != nil {
+		t.Fatalf("second VerifyPhoneCode: %v", err)
+	}
+	if again.UserID != auth.UserID || repo.count() != 1 {
+		t.Errorf("sign-in created another account")
+	}
+}
+
+func TestVerifyPhoneCodeIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	s, _, sender, _ := newPhoneTestService(t)
+
+	if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: testPhone}); err != nil {
+		t.Fatal(err)
+	}
+	req := &VerifyPhoneCodeRequest{Phone: testPhone, Code: sender.lastCode(t, testPhone)}
+
+	if _, _, err := s.VerifyPhoneCode(ctx, req, DeviceInfo{}); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if _, _, err := s.VerifyPhoneCode(ctx, req, DeviceInfo{}); !errors.Is(err, ErrInvalidPhoneCode) {
+		t.Fatalf("second use: got %v, want %v", err, ErrInvalidPhoneCode)
+	}
+}
+
+func TestVerifyPhoneCodeExpires(t *testing.T) {
+	ctx := context.Background()
+	s, repo, sender, fastForward := newPhoneTestService(t)
+
+	if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: testPhone}); err != nil {
+		t.Fatal(err)
+	}
+	code := sender.lastCode(t, testPhone)
+
+	fastForward(phoneCodeTTL + time.Second)
+
+	_, _, err := s.VerifyPhoneCode(ctx, &VerifyPhoneCodeRequest{Phone: testPhone, Code: code}, DeviceInfo{})
+	if !errors.Is(err, ErrInvalidPhoneCode) {
+		t.Fatalf("got %v, want %v", err, ErrInvalidPhoneCode)
+	}
+	if repo.count() != 0 {
+		t.Errorf("an expired code created an account")
+	}
+}
+
+func TestVerifyPhoneCodeAttemptLimit(t *testing.T) {
+	ctx := context.Background()
+	s, repo, sender, fastForward := newPhoneTestService(t)
+
+	if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: testPhone}); err != nil {
+		t.Fatal(err)
+	}
+	code := sender.lastCode(t, testPhone)
+
+	for i := 0; i < phoneCodeAttempts; i++ {
+		_, _, err := s.VerifyPhoneCode(ctx, &VerifyPhoneCodeRequest{Phone: testPhone, Code: wrongCode(code)}, DeviceInfo{})
+		if !errors.Is(err, ErrInvalidPhoneCode) {
+			t.Fatalf("attempt %d: got %v, want %v", i+1, err, ErrInvalidPhoneCode)
+		}
+	}
+
+	// Once the attempts are used up even the right code is refused, and the
+	// code is discarded.
+	_, _, err := s.VerifyPhoneCode(ctx, &VerifyPhoneCodeRequest{Phone: testPhone, Code: code}, DeviceInfo{})
+	if !errors.Is(err, ErrTooManyPhoneCodeAttempt) {
+		t.Fatalf("got %v, want %v", err, ErrTooManyPhoneCodeAttempt)
+	}
+	_, _, err = s.VerifyPhoneCode(ctx, &VerifyPhoneCodeRequest{Phone: testPhone, Code: code}, DeviceInfo{})
+	if !errors.Is(err, ErrInvalidPhoneCode) {
+		t.Fatalf("after the limit: got %v, want %v", err, ErrInvalidPhoneCode)
+	}
+	if repo.count() != 0 {
+		t.Errorf("account created after the attempt limit")
+	}
+
+	// A new code starts with fresh attempts.
+	fastForward(phoneCodeCooldown)
+	if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: testPhone}); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = s.VerifyPhoneCode(ctx, &VerifyPhoneCodeRequest{Phone: testPhone, Code: sender.lastCode(t, testPhone)}, DeviceInfo{})
+	if err != nil {
+		t.Fatalf("new code: %v", err)
+	}
+}
+
+func TestSendPhoneCodeLimits(t *testing.T) {
+	ctx := context.Background()
+	s, _, _, fastForward := newPhoneTestService(t)
+
+	if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: testPhone}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: testPhone}); !errors.Is(err, ErrPhoneCodeCooldown) {
+		t.Fatalf("within cooldown: got %v, want %v", err, ErrPhoneCodeCooldown)
+	}
+
+	for i := 1; i < phoneCodeSendLimit; i++ {
+		fastForward(phoneCodeCooldown)
+		if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: testPhone}); err != nil {
+			t.Fatalf("send %d: %v", i+1, err)
+		}
+	}
+
+	fastForward(phoneCodeCooldown)
+	if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: testPhone}); !errors.Is(err, ErrPhoneCodeLimit) {
+		t.Fatalf("over the limit: got %v, want %v", err, ErrPhoneCodeLimit)
+	}
+
+	fastForward(phoneCodeSendWindow)
+	if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: testPhone}); err != nil {
+		t.Fatalf("after the window: %v", err)
+	}
+}
+
+func TestSendPhoneCodeFailures(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("invalid phone", func(t *testing.T) {
+		s, _, _, _ := newPhoneTestService(t)
+		if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: "12345"}); !errors.Is(err, ErrInvalidPhone) {
+			t.Fatalf("got %v, want %v", err, ErrInvalidPhone)
+		}
+	})
+
+	t.Run("no sender", func(t *testing.T) {
+		s, _, _, _ := newPhoneTestService(t)
+		s.sms = nil
+		if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: testPhone}); !errors.Is(err, ErrPhoneUnavailable) {
+			t.Fatalf("got %v, want %v", err, ErrPhoneUnavailable)
+		}
+	})
+
+	t.Run("sender error discards the code", func(t *testing.T) {
+		s, _, sender, _ := newPhoneTestService(t)
+		sender.err = errors.New("provider is down")
+
+		if _, err := s.SendPhoneCode(ctx, &SendPhoneCodeRequest{Phone: testPhone}); err == nil {
+			t.Fatal("SendPhoneCode succeeded")
+		}
+
+		var pending PhoneCode
+		if err := s.redis.GetPhoneCode(ctx, testPhone, &pending); err == nil {
+			t.Error("the code of an unsent sms is still stored")
+		}
+	})
+}
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+		err   error
+	}{
+		{"+79123456789", "+79123456789", nil},
+		{"89123456789", "+79123456789", nil},
+		{"+7 (912) 345-67-89", "+79123456789", nil},
+		{"+442071838750", "+442071838750", nil},
+		{"9123456789", "", ErrInvalidPhone},
+		{"+0123456789", "", ErrInvalidPhone},
+		{"+7912345678901234", "", ErrInvalidPhone},
+		{"+7912abc6789", "", ErrInvalidPhone},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizePhone(tt.phone)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("normalizePhone(%q) = %q, %v, want %q, %v", tt.phone, got, err, tt.want, tt.err)
+		}
+	}
+}
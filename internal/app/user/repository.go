@@ -2,18 +2,20 @@ package user
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 
 	uuid "github.com/google/uuid"
-	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var (
 	ErrUserAlreadyExists   = errors.New("пользователь с таким email существует")
 	InvalidEmailOrPassword = errors.New("неверный email или пароль")
+	ErrUserNotFound        = errors.New("пользователь не найден")
+	ErrPhoneAlreadyExists  = errors.New("пользователь с таким телефоном существует")
 )
 
 type Repository interface {
@@ -21,7 +23,15 @@ type Repository interface {
 	CreateUser(ctx context.Context, user *User) (*string, error)
 	MakeEmailConfirmed(ctx context.Context, userID string) error
 	GetByEmailProvider(ctx context.Context, email string, provider Provider) (*User, error)
-	GetPasswordHashByEmail(ctx context.Context, email string) (*string, error)
+	UpdatePassword(ctx context.Context, userID string, passwordHash string) error
+	UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error
+	GetByProviderSubject(ctx context.Context, provider Provider, subject string) (*User, error)
+	CreateOAuthUser(ctx context.Context, user *User) (*string, error)
+	LinkProviderSubject(ctx context.Context, userID uuid.UUID, subject string) error
+	GetByPhone(ctx context.Context, phone string) (*User, error)
+	CreatePhoneUser(ctx context.Context, phone string) (*string, error)
+	Ban(ctx context.Context, userID uuid.UUID, reason string) error
+	Unban(ctx context.Context, userID uuid.UUID) error
 	Close()
 }
 
@@ -35,7 +45,8 @@ func NewRepository(pool *pgxpool.Pool) Repository {
 
 func (r *repository) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
 	query := `
-		SELECT id, email, email_confirmed
+		SELECT id, COALESCE(email, ''), email_confirmed, password, provider,
+			phone, phone_confirmed, banned_at, ban_reason
 		FROM users
 		WHERE id = $1
 	`
@@ -49,8 +60,17 @@ func (r *repository) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
 		&user.ID,
 		&user.Email,
 		&user.EmailConfirmed,
+		&user.Password,
+		&user.Provider,
+		&user.Phone,
+		&user.PhoneConfirmed,
+		&user.BannedAt,
+		&user.BanReason,
 	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
 		return nil, fmt.Errorf("не удалось получить пользователя по email provider: %w", err)
 	}
 
@@ -60,7 +80,7 @@ func (r *repository) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
 func (r *repository) CreateUser(ctx context.Context, user *User) (*string, error) {
 	query := `
 		INSERT INTO users (email, password)
-		VALUES ($1, $2, $3, $4)
+		VALUES ($1, $2)
 		RETURNING id
 	`
 
@@ -103,7 +123,7 @@ func (r *repository) MakeEmailConfirmed(ctx context.Context, userID string) erro
 
 func (r *repository) GetByEmailProvider(ctx context.Context, email string, provider Provider) (*User, error) {
 	query := `
-		SELECT id, email
+		SELECT id, email, password
 		FROM users
 		WHERE email = $1 AND provider = $2
 	`
@@ -117,6 +137,7 @@ func (r *repository) GetByEmailProvider(ctx context.Context, email string, provi
 	).Scan(
 		&user.ID,
 		&user.Email,
+		&user.Password,
 	)
 
 	if err != nil {
@@ -126,21 +147,201 @@ func (r *repository) GetByEmailProvider(ctx context.Context, email string, provi
 	return &user, nil
 }
 
-func (r *repository) GetPasswordHashByEmail(ctx context.Context, email string) (*string, error) {
+func (r *repository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
+	query := `
+		UPDATE users
+		SET password = $2, updated_at = now()
+		WHERE id = $1 AND provider = $3
+	`
+
+	result, err := r.pool.Exec(ctx, query, userID, passwordHash, LocalProvider)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить пароль: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdateEmail switches the address of a local account. The new address has
+// already been proven by the caller, so it is stored as confirmed.
+func (r *repository) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	query := `
+		UPDATE users
+		SET email = $2, email_confirmed = TRUE, updated_at = now()
+		WHERE id = $1 AND provider = $3
+	`
+
+	result, err := r.pool.Exec(ctx, query, userID, email, LocalProvider)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return ErrUserAlreadyExists
+		}
+		return fmt.Errorf("не удалось обновить email: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) GetByProviderSubject(ctx context.Context, provider Provider, subject string) (*User, error) {
+	query := `
+		SELECT id, email, email_confirmed, provider, provider_subject
+		FROM users
+		WHERE provider = $1 AND provider_subject = $2
+	`
+
+	var user User
+	err := r.pool.QueryRow(ctx, query, provider, subject).Scan(
+		&user.ID,
+		&user.Email,
+		&user.EmailConfirmed,
+		&user.Provider,
+		&user.ProviderSubject,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("не удалось получить пользователя по provider subject: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (r *repository) CreateOAuthUser(ctx context.Context, user *User) (*string, error) {
+	query := `
+		INSERT INTO users (email, email_confirmed, provider, provider_subject)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	var userID string
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		user.Email,
+		user.EmailConfirmed,
+		user.Provider,
+		user.ProviderSubject,
+	).Scan(&userID)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, ErrUserAlreadyExists
+		}
+		return nil, fmt.Errorf("не удалось создать пользователя: %w", err)
+	}
+
+	return &userID, nil
+}
+
+func (r *repository) LinkProviderSubject(ctx context.Context, userID uuid.UUID, subject string) error {
+	query := `
+		UPDATE users
+		SET provider_subject = $2, email_confirmed = TRUE, updated_at = now()
+		WHERE id = $1
+	`
+
+	result, err := r.pool.Exec(ctx, query, userID, subject)
+	if err != nil {
+		return fmt.Errorf("не удалось привязать аккаунт: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *repository) GetByPhone(ctx context.Context, phone string) (*User, error) {
+	query := `
+		SELECT id, COALESCE(email, ''), phone, phone_confirmed, provider
+		FROM users
+		WHERE phone = $1
+	`
+
+	var user User
+	err := r.pool.QueryRow(ctx, query, phone).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Phone,
+		&user.PhoneConfirmed,
+		&user.Provider,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("не удалось получить пользователя по телефону: %w", err)
+	}
+
+	return &user, nil
+}
+
+// CreatePhoneUser signs up a user by a phone number that has just been
+// confirmed with a code. Such accounts have no email and no password.
+func (r *repository) CreatePhoneUser(ctx context.Context, phone string) (*string, error) {
 	query := `
-		SELECT password FROM users WHERE email = $1
+		INSERT INTO users (phone, phone_confirmed, provider)
+		VALUES ($1, TRUE, $2)
+		RETURNING id
 	`
 
-	var password *string
-	err := r.pool.QueryRow(ctx, query, email).Scan(&password)
+	var userID string
+	err := r.pool.QueryRow(ctx, query, phone, PhoneProvider).Scan(&userID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("не удалось найти пользователя: %w", err)
+		if isUniqueConstraintError(err) {
+			return nil, ErrPhoneAlreadyExists
 		}
-		return nil, fmt.Errorf("не удалось получить хэш пароля: %w", err)
+		return nil, fmt.Errorf("не удалось создать пользователя: %w", err)
+	}
+
+	return &userID, nil
+}
+
+func (r *repository) Ban(ctx context.Context, userID uuid.UUID, reason string) error {
+	query := `
+		UPDATE users
+		SET banned_at = now(), ban_reason = $2, updated_at = now()
+		WHERE id = $1
+	`
+
+	result, err := r.pool.Exec(ctx, query, userID, reason)
+	if err != nil {
+		return fmt.Errorf("не удалось заблокировать пользователя: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
 	}
 
-	return password, nil
+	return nil
+}
+
+func (r *repository) Unban(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET banned_at = NULL, ban_reason = NULL, updated_at = now()
+		WHERE id = $1
+	`
+
+	result, err := r.pool.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось разблокировать пользователя: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
 }
 
 func (r *repository) Close() {
@@ -150,7 +351,8 @@ func (r *repository) Close() {
 }
 
 func isUniqueConstraintError(err error) bool {
-	if pgErr, ok := err.(*pgx.PgError); ok {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
 		return pgErr.Code == "23505"
 	}
 	return false
@@ -0,0 +1,112 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	uuid "github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrUnknownRole = errors.New("неизвестная роль")
+)
+
+type RoleRepository interface {
+	GetRoles(ctx context.Context, userID uuid.UUID) ([]string, error)
+	SetRoles(ctx context.Context, userID uuid.UUID, roles []string, grantedBy uuid.UUID) error
+}
+
+type roleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRoleRepository(pool *pgxpool.Pool) RoleRepository {
+	return &roleRepository{pool: pool}
+}
+
+func (r *roleRepository) GetRoles(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT role
+		FROM user_roles
+		WHERE user_id = $1
+		ORDER BY role
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить роли пользователя: %w", err)
+	}
+	defer rows.Close()
+
+	roles := make([]string, 0)
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, fmt.Errorf("не удалось получить роли пользователя: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("не удалось получить роли пользователя: %w", err)
+	}
+
+	return roles, nil
+}
+
+// SetRoles replaces the user's roles. Roles the user already had keep their
+// original grant time and grantor.
+func (r *roleRepository) SetRoles(ctx context.Context, userID uuid.UUID, roles []string, grantedBy uuid.UUID) error {
+	// A nil slice is sent as NULL, which would make the delete below a no-op.
+	if roles == nil {
+		roles = []string{}
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	deleteQuery := `
+		DELETE FROM user_roles
+		WHERE user_id = $1 AND role <> ALL($2::text[])
+	`
+
+	if _, err := tx.Exec(ctx, deleteQuery, userID, roles); err != nil {
+		return fmt.Errorf("не удалось обновить роли пользователя: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO user_roles (user_id, role, granted_by)
+		SELECT $1, unnest($2::text[]), $3
+		ON CONFLICT (user_id, role) DO NOTHING
+	`
+
+	if _, err := tx.Exec(ctx, insertQuery, userID, roles, grantedBy); err != nil {
+		if isForeignKeyError(err, "user_roles_role_fkey") {
+			return ErrUnknownRole
+		}
+		if isForeignKeyError(err, "user_roles_user_id_fkey") {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("не удалось обновить роли пользователя: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("не удалось сохранить роли пользователя: %w", err)
+	}
+
+	return nil
+}
+
+func isForeignKeyError(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23503" && pgErr.ConstraintName == constraint
+	}
+	return false
+}
@@ -4,41 +4,95 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	stderrors "errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/RuLap/sportmates-api/internal/pkg/errors"
 	"github.com/RuLap/sportmates-api/internal/pkg/events"
 	"github.com/RuLap/sportmates-api/internal/pkg/jwthelper"
 	"github.com/RuLap/sportmates-api/internal/pkg/rabbitmq"
 	"github.com/RuLap/sportmates-api/internal/pkg/redis"
+	"github.com/RuLap/sportmates-api/internal/pkg/sms"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
 )
 
 type Service interface {
-	Register(ctx context.Context, req RegisterRequest) (*AuthResponse, error)
-	Login(ctx context.Context, req LoginRequest) (*AuthResponse, error)
-	RefreshTokens(ctx context.Context, refreshToken string) (*AuthResponse, error)
-	Logout(ctx context.Context, userID string) error
+	Register(ctx context.Context, req RegisterRequest, device DeviceInfo) (*AuthResponse, error)
+	Login(ctx context.Context, req LoginRequest, device DeviceInfo) (*AuthResponse, *TwoFactorChallengeResponse, error)
+	LoginTwoFactor(ctx context.Context, req *LoginTwoFactorRequest, device DeviceInfo) (*AuthResponse, error)
+	SendMagicLink(ctx context.Context, req *MagicLinkRequest, device DeviceInfo) error
+	VerifyMagicLink(ctx context.Context, req *VerifyMagicLinkRequest, device DeviceInfo) (*AuthResponse, *TwoFactorChallengeResponse, error)
+	SendPhoneCode(ctx context.Context, req *SendPhoneCodeRequest) (*PhoneCodeResponse, error)
+	VerifyPhoneCode(ctx context.Context, req *VerifyPhoneCodeRequest, device DeviceInfo) (*AuthResponse, *TwoFactorChallengeResponse, error)
+	RefreshTokens(ctx context.Context, refreshToken string, device DeviceInfo) (*AuthResponse, error)
+	Logout(ctx context.Context, userID, sessionID string) error
+
+	GetSessions(ctx context.Context, userID, currentSessionID string) ([]*SessionResponse, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeSessions(ctx context.Context, userID string) error
 
 	SendConfirmationLink(ctx context.Context, req *SendConfirmationEmailRequest, userID string) error
 	ConfirmEmail(ctx context.Context, token string) error
 	IsEmailConfirmed(ctx context.Context, userID uuid.UUID) (bool, error)
+	ChangeEmail(ctx context.Context, userID string, req *ChangeEmailRequest) error
+	RevertEmailChange(ctx context.Context, req *RevertEmailChangeRequest) error
+
+	ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *ResetPasswordRequest) error
+	ChangePassword(ctx context.Context, userID, sessionID string, req *ChangePasswordRequest) error
+
+	GetGoogleAuthURL(ctx context.Context) (string, error)
+	GoogleCallback(ctx context.Context, state, code string, device DeviceInfo) (*AuthResponse, *TwoFactorChallengeResponse, error)
+
+	EnrollTwoFactor(ctx context.Context, userID string) (*EnrollTwoFactorResponse, error)
+	VerifyTwoFactor(ctx context.Context, userID string, req *VerifyTwoFactorRequest) (*VerifyTwoFactorResponse, error)
+	DisableTwoFactor(ctx context.Context, userID string, req *DisableTwoFactorRequest) error
+
+	VerifyPassword(ctx context.Context, userID string, password string) error
+	Reauthenticate(ctx context.Context, userID, sessionID, password string) error
+
+	GetUserForAdmin(ctx context.Context, userID string) (*AdminUserResponse, error)
+	BanUser(ctx context.Context, moderatorID string, moderatorRoles []string, userID string, req *BanUserRequest) error
+	UnbanUser(ctx context.Context, moderatorID string, moderatorRoles []string, userID string) error
+	SetRoles(ctx context.Context, adminID string, userID string, req *SetRolesRequest) (*AdminUserResponse, error)
 }
 
+var (
+	ErrOAuthUnavailable = stderrors.New("вход через Google недоступен")
+	ErrOAuthFailed      = stderrors.New("не удалось войти через Google")
+	ErrInvalidPassword  = stderrors.New("неверный пароль")
+	ErrNoPassword       = stderrors.New("для аккаунта не задан пароль")
+	ErrSamePassword     = stderrors.New("новый пароль совпадает с текущим")
+	ErrReauthRequired   = stderrors.New("войдите в аккаунт заново, чтобы подтвердить действие")
+)
+
+// reauthWindow is how recently an account without a password must have
+// signed in to confirm a sensitive action.
+const reauthWindow = 10 * time.Minute
+
 type GoogleOAuthConfig struct {
 	ClientID     string
 	ClientSecret string
 	RedirectURL  string
+	IssuerURL    string
 }
 
 type service struct {
-	log       *slog.Logger
-	jwtHelper *jwthelper.JWTHelper
-	redis     *redis.Service
-	rabbitmq  *rabbitmq.Service
-	repo      Repository
+	log           *slog.Logger
+	jwtHelper     *jwthelper.JWTHelper
+	redis         *redis.Service
+	rabbitmq      *rabbitmq.Service
+	google        *GoogleAuthenticator
+	sms           sms.Sender
+	passwords     PasswordPolicy
+	publicURL     string
+	repo          Repository
+	twoFactorRepo TwoFactorRepository
+	roleRepo      RoleRepository
 }
 
 func NewService(
@@ -46,14 +100,26 @@ func NewService(
 	jwtHelper *jwthelper.JWTHelper,
 	redis *redis.Service,
 	rabbitmq *rabbitmq.Service,
+	google *GoogleAuthenticator,
+	sms sms.Sender,
+	passwords PasswordPolicy,
+	publicURL string,
 	repo Repository,
+	twoFactorRepo TwoFactorRepository,
+	roleRepo RoleRepository,
 ) Service {
 	return &service{
-		log:       log,
-		jwtHelper: jwtHelper,
-		redis:     redis,
-		rabbitmq:  rabbitmq,
-		repo:      repo,
+		log:           log,
+		jwtHelper:     jwtHelper,
+		redis:         redis,
+		rabbitmq:      rabbitmq,
+		google:        google,
+		sms:           sms,
+		passwords:     passwords.withDefaults(),
+		publicURL:     publicURL,
+		repo:          repo,
+		twoFactorRepo: twoFactorRepo,
+		roleRepo:      roleRepo,
 	}
 }
 
@@ -75,7 +141,7 @@ func (s *service) SendConfirmationLink(ctx context.Context, req *SendConfirmatio
 		return fmt.Errorf("не удалось сохранить токен")
 	}
 
-	confirmationURL := fmt.Sprintf("https://sportmates.ru/confirm?token=%s", token)
+	confirmationURL := fmt.Sprintf("%s/confirm?token=%s", s.publicURL, token)
 
 	if s.rabbitmq != nil {
 		event := events.EmailEvent{
@@ -110,8 +176,19 @@ func (s *service) ConfirmEmail(ctx context.Context, token string) error {
 		return fmt.Errorf("неверная или устаревшая ссылка подтверждения")
 	}
 
-	if err := s.repo.MakeEmailConfirmed(ctx, userID); err != nil {
-		s.log.Error("failed to confirm email in database", "error", err, "user_id", userID)
+	newEmail, err := s.redis.GetEmailChange(ctx, token)
+	switch {
+	case err == nil:
+		if err := s.applyEmailChange(ctx, userID, newEmail); err != nil {
+			return err
+		}
+	case stderrors.Is(err, redis.ErrNil):
+		if err := s.repo.MakeEmailConfirmed(ctx, userID); err != nil {
+			s.log.Error("failed to confirm email in database", "error", err, "user_id", userID)
+			return fmt.Errorf("не удалось подтвердить email")
+		}
+	default:
+		s.log.Error("failed to get email change", "error", err, "user_id", userID)
 		return fmt.Errorf("не удалось подтвердить email")
 	}
 
@@ -123,6 +200,265 @@ func (s *service) ConfirmEmail(ctx context.Context, token string) error {
 	return nil
 }
 
+// ForgotPassword never reports whether the email is registered: failures are
+// only logged so that the response is the same for every address.
+func (s *service) ForgotPassword(ctx context.Context, req *ForgotPasswordRequest) error {
+	user, err := s.repo.GetByEmailProvider(ctx, req.Email, LocalProvider)
+	if err != nil {
+		s.log.Info("password reset requested for unknown email", "email", req.Email)
+		return nil
+	}
+
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		s.log.Error("failed to generate token", "error", err)
+		return nil
+	}
+	token := hex.EncodeToString(rawToken)
+
+	userID := user.ID.String()
+	if err := s.redis.StorePasswordReset(ctx, userID, token); err != nil {
+		s.log.Error("failed to store password reset token in redis", "error", err, "user_id", userID)
+		return nil
+	}
+
+	resetURL := fmt.Sprintf("%s/reset-password?token=%s", s.publicURL, token)
+
+	if s.rabbitmq != nil {
+		event := events.EmailEvent{
+			To:       user.Email,
+			Template: "password_reset",
+			Subject:  "Сброс пароля",
+			Data: map[string]interface{}{
+				"reset_url":  resetURL,
+				"user_email": user.Email,
+			},
+		}
+
+		if err := s.rabbitmq.PublishEmail(event); err != nil {
+			s.log.Error("failed to publish email event", "error", err)
+		}
+	} else {
+		s.log.Warn("event service not available - email not sent")
+	}
+
+	s.log.Info("password reset link generated and sent", "user_id", userID)
+	return nil
+}
+
+func (s *service) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	if err := s.passwords.Validate(req.Password); err != nil {
+		return err
+	}
+
+	userID, err := s.redis.ConsumePasswordReset(ctx, req.Token)
+	if err != nil {
+		s.log.Warn("invalid or expired password reset token", "error", err)
+		return fmt.Errorf("неверная или устаревшая ссылка для сброса пароля")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		s.log.Error("failed to hash password", "error", err)
+		return fmt.Errorf("произошла ошибка")
+	}
+
+	if err := s.repo.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+		s.log.Error("failed to update password", "error", err, "user_id", userID)
+		return fmt.Errorf("не удалось изменить пароль")
+	}
+
+	if err := s.revokeAllTokens(ctx, userID); err != nil {
+		s.log.Error("failed to revoke tokens", "error", err, "user_id", userID)
+	}
+
+	if err := s.redis.DeleteEmailChange(ctx, userID); err != nil {
+		s.log.Error("failed to drop pending email change", "error", err, "user_id", userID)
+	}
+
+	s.log.Info("password reset successfully", "user_id", userID)
+	return nil
+}
+
+// ChangePassword sets a new password after checking the current one and
+// drops a pending email change requested with the old one. Other sessions
+// are ended only on request; the current one always stays. Unlike a
+// password reset, it does not move the revocation watermark: that would sign
+// out the current session as well. Each other session is ended instead, which
+// denies its access token and makes its refresh token unusable.
+func (s *service) ChangePassword(ctx context.Context, userID, sessionID string, req *ChangePasswordRequest) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("неверный ID пользователя")
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get user", "error", err, "user_id", userID)
+		return fmt.Errorf("произошла ошибка")
+	}
+
+	if user.Provider != LocalProvider || user.Password == nil {
+		return ErrNoPassword
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(req.OldPassword)); err != nil {
+		s.log.Warn("invalid current password on password change", "user_id", userID)
+		return ErrInvalidPassword
+	}
+
+	if req.NewPassword == req.OldPassword {
+		return ErrSamePassword
+	}
+
+	if err := s.passwords.Validate(req.NewPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		s.log.Error("failed to hash password", "error", err)
+		return fmt.Errorf("произошла ошибка")
+	}
+
+	if err := s.repo.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+		s.log.Error("failed to update password", "error", err, "user_id", userID)
+		return fmt.Errorf("не удалось изменить пароль")
+	}
+
+	if err := s.redis.DeleteEmailChange(ctx, userID); err != nil {
+		s.log.Error("failed to drop pending email change", "error", err, "user_id", userID)
+	}
+
+	if req.LogoutOtherSessions {
+		if err := s.endOtherSessions(ctx, userID, sessionID); err != nil {
+			s.log.Error("failed to end other sessions", "error", err, "user_id", userID)
+			return fmt.Errorf("пароль изменен, но не удалось завершить другие сессии")
+		}
+	}
+
+	s.log.Info("password changed", "user_id", userID, "other_sessions_ended", req.LogoutOtherSessions)
+	return nil
+}
+
+func (s *service) GetGoogleAuthURL(ctx context.Context) (string, error) {
+	if s.google == nil {
+		return "", ErrOAuthUnavailable
+	}
+
+	state, err := generateOAuthValue()
+	if err != nil {
+		s.log.Error("failed to generate oauth state", "error", err)
+		return "", fmt.Errorf("произошла ошибка")
+	}
+
+	nonce, err := generateOAuthValue()
+	if err != nil {
+		s.log.Error("failed to generate oauth nonce", "error", err)
+		return "", fmt.Errorf("произошла ошибка")
+	}
+
+	oauthState := OAuthState{
+		Nonce:        nonce,
+		CodeVerifier: oauth2.GenerateVerifier(),
+	}
+
+	if err := s.redis.StoreOAuthState(ctx, state, oauthState); err != nil {
+		s.log.Error("failed to store oauth state in redis", "error", err)
+		return "", fmt.Errorf("произошла ошибка")
+	}
+
+	return s.google.AuthCodeURL(state, oauthState.Nonce, oauthState.CodeVerifier), nil
+}
+
+// GoogleCallback completes the authorization code flow. The state is consumed
+// on first use, so a callback URL cannot be replayed.
+func (s *service) GoogleCallback(ctx context.Context, state, code string, device DeviceInfo) (*AuthResponse, *TwoFactorChallengeResponse, error) {
+	if s.google == nil {
+		return nil, nil, ErrOAuthUnavailable
+	}
+
+	var oauthState OAuthState
+	if err := s.redis.ConsumeOAuthState(ctx, state, &oauthState); err != nil {
+		s.log.Warn("invalid or expired oauth state", "error", err)
+		return nil, nil, fmt.Errorf("неверная или устаревшая попытка входа")
+	}
+
+	identity, err := s.google.Exchange(ctx, code, oauthState.Nonce, oauthState.CodeVerifier)
+	if err != nil {
+		s.log.Warn("failed to exchange google authorization code", "error", err)
+		return nil, nil, ErrOAuthFailed
+	}
+
+	user, err := s.findOrCreateGoogleUser(ctx, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, challenge, err := s.completeLogin(ctx, user.ID.String(), user.Email, device)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.log.Info("user logged in with google successfully", "user_id", user.ID, "email", user.Email)
+	return response, challenge, nil
+}
+
+func (s *service) findOrCreateGoogleUser(ctx context.Context, identity *GoogleIdentity) (*User, error) {
+	user, err := s.repo.GetByProviderSubject(ctx, GoogleProvider, identity.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !stderrors.Is(err, ErrUserNotFound) {
+		s.log.Error("failed to get user by google subject", "error", err)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		s.log.Warn("google account email is not verified", "subject", identity.Subject)
+		return nil, fmt.Errorf("email аккаунта Google не подтвержден")
+	}
+
+	user, err = s.repo.GetByEmailProvider(ctx, identity.Email, GoogleProvider)
+	if err == nil {
+		if err := s.repo.LinkProviderSubject(ctx, user.ID, identity.Subject); err != nil {
+			s.log.Error("failed to link google account", "error", err, "user_id", user.ID)
+			return nil, fmt.Errorf("произошла ошибка")
+		}
+		return user, nil
+	}
+
+	user = &User{
+		Email:           identity.Email,
+		EmailConfirmed:  true,
+		Provider:        GoogleProvider,
+		ProviderSubject: &identity.Subject,
+	}
+
+	userID, err := s.repo.CreateOAuthUser(ctx, user)
+	if err != nil {
+		s.log.Error("failed to create google user", "error", err, "email", identity.Email)
+		return nil, err
+	}
+
+	user.ID, err = uuid.Parse(*userID)
+	if err != nil {
+		s.log.Error("failed to parse user id", "error", err, "user_id", *userID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	s.log.Info("user registered with google successfully", "user_id", *userID, "email", identity.Email)
+	return user, nil
+}
+
+func generateOAuthValue() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
 func (s *service) IsEmailConfirmed(ctx context.Context, userID uuid.UUID) (bool, error) {
 	user, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
@@ -132,7 +468,11 @@ func (s *service) IsEmailConfirmed(ctx context.Context, userID uuid.UUID) (bool,
 	return user.EmailConfirmed, nil
 }
 
-func (s *service) Register(ctx context.Context, req RegisterRequest) (*AuthResponse, error) {
+func (s *service) Register(ctx context.Context, req RegisterRequest, device DeviceInfo) (*AuthResponse, error) {
+	if err := s.passwords.Validate(req.Password); err != nil {
+		return nil, err
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		s.log.Error("failed to hash password", "error", err)
@@ -149,16 +489,9 @@ func (s *service) Register(ctx context.Context, req RegisterRequest) (*AuthRespo
 		return nil, err
 	}
 
-	tokenPair, err := s.jwtHelper.GenerateTokenPair(*userID, req.Email)
-	if err != nil {
-		s.log.Error("failed to generate JWT tokens", "error", err)
-		return nil, fmt.Errorf("произошла ошибка")
-	}
-
-	err = s.storeRefreshToken(ctx, *userID, tokenPair.RefreshToken)
+	tokenPair, err := s.createSession(ctx, *userID, req.Email, device)
 	if err != nil {
-		s.log.Error("failed to store refresh token", "error", err, "user_id", *userID)
-		return nil, fmt.Errorf("произошла ошибка")
+		return nil, err
 	}
 
 	s.log.Info("user registered successfully", "user_id", *userID, "email", req.Email)
@@ -172,110 +505,108 @@ func (s *service) Register(ctx context.Context, req RegisterRequest) (*AuthRespo
 	}, nil
 }
 
-func (s *service) Login(ctx context.Context, req LoginRequest) (*AuthResponse, error) {
+func (s *service) Login(ctx context.Context, req LoginRequest, device DeviceInfo) (*AuthResponse, *TwoFactorChallengeResponse, error) {
+	if err := s.checkLoginLock(ctx, req.Email, device); err != nil {
+		s.log.Warn("login attempt while locked", "email", req.Email, "ip", device.IP)
+		return nil, nil, err
+	}
+
 	user, err := s.repo.GetByEmailProvider(ctx, req.Email, LocalProvider)
 	if err != nil {
 		s.log.Warn("user not found", "email", req.Email)
-		return nil, fmt.Errorf("неверный email или пароль")
+		return nil, nil, s.loginFailed(ctx, req.Email, nil, device)
 	}
 
-	passwordHash, err := s.repo.GetPasswordHashByEmail(ctx, req.Email)
-	if err != nil {
-		s.log.Warn("failed to get password hash", "email", req.Email, "error", err)
-		return nil, fmt.Errorf("неверный email или пароль")
+	if user.Password == nil {
+		s.log.Warn("user has no password set", "email", req.Email)
+		return nil, nil, s.loginFailed(ctx, req.Email, user, device)
 	}
 
 	if req.Password == "" {
 		s.log.Error("user entered empty password", "email", req.Email)
-		return nil, fmt.Errorf("неверный email или пароль")
+		return nil, nil, s.loginFailed(ctx, req.Email, user, device)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(*passwordHash), []byte(req.Password)); err != nil {
+	if err := bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(req.Password)); err != nil {
 		s.log.Error("user entered invalid password", "email", req.Email)
-		return nil, fmt.Errorf("неверный email или пароль")
+		return nil, nil, s.loginFailed(ctx, req.Email, user, device)
 	}
 
-	tokenPair, err := s.jwtHelper.GenerateTokenPair(user.ID.String(), user.Email)
-	if err != nil {
-		s.log.Error("failed to generate JWT tokens", "error", err)
-		return nil, fmt.Errorf("произошла ошибка")
-	}
+	s.resetLoginFailures(ctx, req.Email)
 
-	err = s.storeRefreshToken(ctx, user.ID.String(), tokenPair.RefreshToken)
+	response, challenge, err := s.completeLogin(ctx, user.ID.String(), user.Email, device)
 	if err != nil {
-		s.log.Error("failed to store refresh token", "error", err, "user_id", user.ID)
-		return nil, fmt.Errorf("произошла ошибка")
+		return nil, nil, err
 	}
 
 	s.log.Info("user logged in successfully", "user_id", user.ID, "email", req.Email)
-
-	return &AuthResponse{
-		AccessToken:  tokenPair.AccessToken,
-		RefreshToken: tokenPair.RefreshToken,
-		ExpiresIn:    tokenPair.ExpiresIn,
-		UserID:       user.ID.String(),
-		Email:        user.Email,
-	}, nil
+	return response, challenge, nil
 }
 
-func (s *service) RefreshTokens(ctx context.Context, refreshToken string) (*AuthResponse, error) {
-	if refreshToken == "" {
-		return nil, fmt.Errorf("refresh token обязателен")
+func (s *service) loginFailed(ctx context.Context, email string, user *User, device DeviceInfo) error {
+	if err := s.registerLoginFailure(ctx, email, user, device); err != nil {
+		return err
 	}
+	return fmt.Errorf("неверный email или пароль")
+}
 
-	claims, err := s.jwtHelper.ParseJWT(refreshToken)
+// VerifyPassword confirms a sensitive action with the current password.
+// Accounts without a password never pass it, see Reauthenticate.
+func (s *service) VerifyPassword(ctx context.Context, userID string, password string) error {
+	id, err := uuid.Parse(userID)
 	if err != nil {
-		s.log.Warn("invalid refresh token format", "error", err)
-		return nil, fmt.Errorf("неверный refresh token")
+		return fmt.Errorf("неверный ID пользователя")
 	}
 
-	if claims.Type != "refresh" {
-		s.log.Warn("attempt to use non-refresh token for refresh", "token_type", claims.Type)
-		return nil, fmt.Errorf("неверный тип токена")
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get user", "error", err, "user_id", userID)
+		return fmt.Errorf("произошла ошибка")
 	}
 
-	storedToken, err := s.redis.GetRefreshToken(ctx, claims.UserID)
-	if err != nil {
-		s.log.Warn("refresh token not found in storage", "user_id", claims.UserID, "error", err)
-		return nil, fmt.Errorf("refresh token не найден или истек")
+	if user.Password == nil {
+		return ErrNoPassword
 	}
 
-	if storedToken != refreshToken {
-		s.log.Warn("refresh token mismatch", "user_id", claims.UserID)
-		return nil, fmt.Errorf("неверный refresh token")
+	if err := bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(password)); err != nil {
+		s.log.Warn("invalid password confirmation", "user_id", userID)
+		return ErrInvalidPassword
 	}
 
-	newTokenPair, err := s.jwtHelper.GenerateTokenPair(claims.UserID, claims.Email)
+	return nil
+}
+
+// Reauthenticate confirms a sensitive action. Accounts with a password enter
+// it again. Google and phone accounts have nothing to enter, so the current
+// session must have been started by a sign-in within reauthWindow; a token
+// refreshed from an older session is not enough.
+func (s *service) Reauthenticate(ctx context.Context, userID, sessionID, password string) error {
+	id, err := uuid.Parse(userID)
 	if err != nil {
-		s.log.Error("failed to generate new token pair", "error", err, "user_id", claims.UserID)
-		return nil, fmt.Errorf("произошла ошибка")
+		return fmt.Errorf("неверный ID пользователя")
 	}
 
-	err = s.storeRefreshToken(ctx, claims.UserID, newTokenPair.RefreshToken)
+	user, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		s.log.Error("failed to store new refresh token", "error", err, "user_id", claims.UserID)
-		return nil, fmt.Errorf("произошла ошибка")
+		s.log.Error("failed to get user", "error", err, "user_id", userID)
+		return fmt.Errorf("произошла ошибка")
 	}
 
-	s.log.Info("tokens refreshed successfully", "user_id", claims.UserID)
+	if user.Password != nil {
+		return s.VerifyPassword(ctx, userID, password)
+	}
 
-	return &AuthResponse{
-		AccessToken:  newTokenPair.AccessToken,
-		RefreshToken: newTokenPair.RefreshToken,
-		ExpiresIn:    newTokenPair.ExpiresIn,
-		UserID:       claims.UserID,
-		Email:        claims.Email,
-	}, nil
-}
+	var session Session
+	if err := s.redis.GetSession(ctx, sessionID, &session); err != nil || session.UserID != userID {
+		s.log.Warn("session not found for reauthentication", "user_id", userID, "session_id", sessionID)
+		return ErrReauthRequired
+	}
 
-func (s *service) Logout(ctx context.Context, userID string) error {
-	err := s.redis.DeleteRefreshToken(ctx, userID)
-	if err != nil {
-		s.log.Error("failed to delete refresh token", "error", err, "user_id", userID)
-		return fmt.Errorf("не удалось выполнить выход")
+	if time.Since(session.CreatedAt) > reauthWindow {
+		s.log.Warn("reauthentication required", "user_id", userID, "session_id", sessionID)
+		return ErrReauthRequired
 	}
 
-	s.log.Info("user logged out successfully", "user_id", userID)
 	return nil
 }
 
@@ -287,11 +618,3 @@ func (s *service) ValidateToken(token string) (bool, error) {
 	}
 	return valid, nil
 }
-
-func (s *service) storeRefreshToken(ctx context.Context, userID, refreshToken string) error {
-	return s.redis.StoreRefreshToken(ctx, userID, refreshToken)
-}
-
-func (s *service) getRefreshTokenKey(userID string) string {
-	return fmt.Sprintf("refresh_token:%s", userID)
-}
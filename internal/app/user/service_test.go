@@ -0,0 +1,234 @@
+package user
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/config"
+	"github.com/RuLap/sportmates-api/internal/pkg/jwthelper"
+	"github.com/RuLap/sportmates-api/internal/pkg/redis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+)
+
+// newTestService builds the service on top of an in-memory Redis and the
+// fakes below. Tests set the optional dependencies they need on the result.
+func newTestService(t *testing.T) (*service, *miniredis.Miniredis) {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mr := miniredis.RunT(t)
+	client, err := redis.NewClient(config.RedisConfig{Address: mr.Addr()}, log)
+	if err != nil {
+		t.Fatalf("connect to miniredis: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	jwtHelper, err := jwthelper.NewJwtHelper("test-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewService(
+		log,
+		jwtHelper,
+		redis.NewService(client),
+		nil,
+		nil,
+		nil,
+		PasswordPolicy{},
+		"https://sportmates.test",
+		newFakeRepository(),
+		newFakeTwoFactorRepository(),
+		fakeRoleRepository{},
+	).(*service)
+
+	return s, mr
+}
+
+// fakeRepository keeps users in memory. Methods the tests do not reach are
+// left to the embedded interface and panic if called.
+type fakeRepository struct {
+	Repository
+
+	mu    sync.Mutex
+	users map[uuid.UUID]*User
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{users: make(map[uuid.UUID]*User)}
+}
+
+func (r *fakeRepository) add(user *User) *User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	if user.Provider == "" {
+		user.Provider = LocalProvider
+	}
+	r.users[user.ID] = user
+	return user
+}
+
+func (r *fakeRepository) find(match func(*User) bool) (*User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if match(user) {
+			result := *user
+			return &result, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *fakeRepository) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.users)
+}
+
+func (r *fakeRepository) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
+	return r.find(func(user *User) bool { return user.ID == id })
+}
+
+func (r *fakeRepository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.ID.String() == userID {
+			user.Password = &passwordHash
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
+
+func (r *fakeRepository) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.Email = email
+	user.EmailConfirmed = true
+	return nil
+}
+
+func (r *fakeRepository) GetByEmailProvider(ctx context.Context, email string, provider Provider) (*User, error) {
+	return r.find(func(user *User) bool { return user.Email == email && user.Provider == provider })
+}
+
+func (r *fakeRepository) GetByProviderSubject(ctx context.Context, provider Provider, subject string) (*User, error) {
+	return r.find(func(user *User) bool {
+		return user.Provider == provider && user.ProviderSubject != nil && *user.ProviderSubject == subject
+	})
+}
+
+func (r *fakeRepository) LinkProviderSubject(ctx context.Context, userID uuid.UUID, subject string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.ProviderSubject = &subject
+	user.EmailConfirmed = true
+	return nil
+}
+
+func (r *fakeRepository) CreateOAuthUser(ctx context.Context, user *User) (*string, error) {
+	if _, err := r.GetByEmailProvider(ctx, user.Email, user.Provider); err == nil {
+		return nil, ErrUserAlreadyExists
+	}
+
+	created := *user
+	created.ID = uuid.Nil
+	userID := r.add(&created).ID.String()
+	return &userID, nil
+}
+
+func (r *fakeRepository) GetByPhone(ctx context.Context, phone string) (*User, error) {
+	return r.find(func(user *User) bool { return user.Phone != nil && *user.Phone == phone })
+}
+
+func (r *fakeRepository) CreatePhoneUser(ctx context.Context, phone string) (*string, error) {
+	if _, err := r.GetByPhone(ctx, phone); err == nil {
+		return nil, ErrUserAlreadyExists
+	}
+
+	userID := r.add(&User{Phone: &phone, PhoneConfirmed: true, Provider: PhoneProvider}).ID.String()
+	return &userID, nil
+}
+
+type fakeTwoFactorRepository struct {
+	TwoFactorRepository
+
+	mu            sync.Mutex
+	twoFactor     map[uuid.UUID]*TwoFactor
+	recoveryCodes map[uuid.UUID]map[string]bool
+}
+
+func newFakeTwoFactorRepository() *fakeTwoFactorRepository {
+	return &fakeTwoFactorRepository{
+		twoFactor:     make(map[uuid.UUID]*TwoFactor),
+		recoveryCodes: make(map[uuid.UUID]map[string]bool),
+	}
+}
+
+func (r *fakeTwoFactorRepository) GetTwoFactor(ctx context.Context, userID uuid.UUID) (*TwoFactor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	twoFactor, ok := r.twoFactor[userID]
+	if !ok {
+		return &TwoFactor{}, nil
+	}
+	return twoFactor, nil
+}
+
+func (r *fakeTwoFactorRepository) Enable(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	codes := make(map[string]bool, len(recoveryCodeHashes))
+	for _, hash := range recoveryCodeHashes {
+		codes[hash] = false
+	}
+	r.recoveryCodes[userID] = codes
+	return nil
+}
+
+// UseRecoveryCode mirrors the conditional update of the real repository.
+func (r *fakeTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	used, ok := r.recoveryCodes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.recoveryCodes[userID][codeHash] = true
+	return true, nil
+}
+
+type fakeRoleRepository struct {
+	RoleRepository
+}
+
+func (fakeRoleRepository) GetRoles(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	return []string{}, nil
+}
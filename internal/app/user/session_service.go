@@ -0,0 +1,363 @@
+package user
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/events"
+	"github.com/RuLap/sportmates-api/internal/pkg/jwthelper"
+	"github.com/RuLap/sportmates-api/internal/pkg/redis"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSessionNotFound = stderrors.New("сессия не найдена")
+	ErrUserBanned      = stderrors.New("аккаунт заблокирован")
+)
+
+// createSession starts a new device session. Every session has its own
+// refresh token, so signing in on one device does not sign out the others.
+// The session is also the refresh token family: each refresh replaces its
+// token and marks the previous one as used.
+func (s *service) createSession(ctx context.Context, userID, email string, device DeviceInfo) (*jwthelper.TokenPair, error) {
+	roles, err := s.loadRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID := uuid.New().String()
+
+	tokenPair, err := s.jwtHelper.GenerateTokenPair(userID, email, sessionID, roles)
+	if err != nil {
+		s.log.Error("failed to generate JWT tokens", "error", err)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	now := time.Now()
+	session := Session{
+		ID:                   sessionID,
+		UserID:               userID,
+		RefreshToken:         tokenPair.RefreshToken,
+		AccessTokenID:        tokenPair.AccessTokenID,
+		AccessTokenExpiresAt: now.Add(time.Duration(tokenPair.ExpiresIn) * time.Second),
+		UserAgent:            device.UserAgent,
+		IP:                   device.IP,
+		CreatedAt:            now,
+		LastUsedAt:           now,
+	}
+
+	if err := s.redis.StoreSession(ctx, userID, sessionID, session); err != nil {
+		s.log.Error("failed to store session", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	return tokenPair, nil
+}
+
+// loadRoles is called whenever tokens are issued, so bans and role changes
+// apply from the next sign-in or refresh at the latest.
+func (s *service) loadRoles(ctx context.Context, userID string) ([]string, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		s.log.Error("failed to parse user id", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get user", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	if user.IsBanned() {
+		s.log.Warn("banned user attempted to sign in", "user_id", userID)
+		return nil, ErrUserBanned
+	}
+
+	roles, err := s.roleRepo.GetRoles(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get user roles", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	return roles, nil
+}
+
+func (s *service) RefreshTokens(ctx context.Context, refreshToken string, device DeviceInfo) (*AuthResponse, error) {
+	if refreshToken == "" {
+		return nil, fmt.Errorf("refresh token обязателен")
+	}
+
+	claims, err := s.jwtHelper.ParseJWT(refreshToken)
+	if err != nil {
+		s.log.Warn("invalid refresh token format", "error", err)
+		return nil, fmt.Errorf("неверный refresh token")
+	}
+
+	if claims.Type != "refresh" {
+		s.log.Warn("attempt to use non-refresh token for refresh", "token_type", claims.Type)
+		return nil, fmt.Errorf("неверный тип токена")
+	}
+
+	var session Session
+	if err := s.redis.GetSession(ctx, claims.SessionID, &session); err != nil {
+		s.log.Warn("session not found in storage", "user_id", claims.UserID, "session_id", claims.SessionID, "error", err)
+		return nil, fmt.Errorf("refresh token не найден или истек")
+	}
+
+	if session.UserID != claims.UserID {
+		s.log.Warn("refresh token does not belong to session owner", "user_id", claims.UserID, "session_id", claims.SessionID)
+		return nil, fmt.Errorf("неверный refresh token")
+	}
+
+	// A signed token of this family that is not the current one can only be
+	// an older one that was already rotated, so it is treated as reuse.
+	if session.RefreshToken != refreshToken {
+		s.revokeTokenFamily(ctx, claims, device)
+		return nil, fmt.Errorf("неверный refresh token")
+	}
+
+	roles, err := s.loadRoles(ctx, claims.UserID)
+	if err != nil {
+		if stderrors.Is(err, ErrUserBanned) {
+			if err := s.endSession(ctx, claims.UserID, session.ID); err != nil {
+				s.log.Error("failed to end session of banned user", "error", err, "user_id", claims.UserID)
+			}
+		}
+		return nil, err
+	}
+
+	newTokenPair, err := s.jwtHelper.GenerateTokenPair(claims.UserID, claims.Email, session.ID, roles)
+	if err != nil {
+		s.log.Error("failed to generate new token pair", "error", err, "user_id", claims.UserID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	// The token is marked as used only once every check has passed, so a
+	// transient failure above lets the client retry instead of looking like
+	// reuse.
+	firstUse, err := s.redis.MarkRefreshTokenUsed(ctx, claims.ID, claims.SessionID, time.Until(claims.ExpiresAt.Time))
+	if err != nil {
+		s.log.Error("failed to mark refresh token as used", "error", err, "user_id", claims.UserID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	if !firstUse {
+		s.revokeTokenFamily(ctx, claims, device)
+		return nil, fmt.Errorf("неверный refresh token")
+	}
+
+	// Only the latest access token of a session stays valid, so revoking the
+	// session only ever has to deny a single token.
+	if err := s.denyAccessToken(ctx, &session); err != nil {
+		s.log.Error("failed to deny access token", "error", err, "session_id", session.ID)
+	}
+
+	now := time.Now()
+	session.RefreshToken = newTokenPair.RefreshToken
+	session.AccessTokenID = newTokenPair.AccessTokenID
+	session.AccessTokenExpiresAt = now.Add(time.Duration(newTokenPair.ExpiresIn) * time.Second)
+	session.LastUsedAt = now
+	if device.UserAgent != "" {
+		session.UserAgent = device.UserAgent
+	}
+	if device.IP != "" {
+		session.IP = device.IP
+	}
+
+	if err := s.redis.StoreSession(ctx, claims.UserID, session.ID, session); err != nil {
+		s.log.Error("failed to store session", "error", err, "user_id", claims.UserID)
+		if err := s.redis.ReleaseRefreshToken(ctx, claims.ID); err != nil {
+			s.log.Error("failed to release refresh token", "error", err, "user_id", claims.UserID)
+		}
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	s.log.Info("tokens refreshed successfully", "user_id", claims.UserID, "session_id", session.ID)
+
+	return &AuthResponse{
+		AccessToken:  newTokenPair.AccessToken,
+		RefreshToken: newTokenPair.RefreshToken,
+		ExpiresIn:    newTokenPair.ExpiresIn,
+		UserID:       claims.UserID,
+		Email:        claims.Email,
+	}, nil
+}
+
+// Logout ends the current session. Tokens issued before sessions were
+// introduced carry no session ID, so for them every session is revoked.
+func (s *service) Logout(ctx context.Context, userID, sessionID string) error {
+	var err error
+	if sessionID == "" {
+		err = s.revokeAllTokens(ctx, userID)
+	} else {
+		err = s.endSession(ctx, userID, sessionID)
+	}
+	if err != nil {
+		s.log.Error("failed to delete session", "error", err, "user_id", userID)
+		return fmt.Errorf("не удалось выполнить выход")
+	}
+
+	s.log.Info("user logged out successfully", "user_id", userID, "session_id", sessionID)
+	return nil
+}
+
+// revokeTokenFamily is called when a used refresh token is presented again.
+// Either the legitimate client or an attacker holds a stolen copy, and there is
+// no way to tell which, so the whole session is terminated.
+func (s *service) revokeTokenFamily(ctx context.Context, claims *jwthelper.Claims, device DeviceInfo) {
+	if err := s.endSession(ctx, claims.UserID, claims.SessionID); err != nil {
+		s.log.Error("failed to revoke session after refresh token reuse", "error", err, "user_id", claims.UserID, "session_id", claims.SessionID)
+	}
+
+	s.log.Warn("refresh token reuse detected, session revoked",
+		"user_id", claims.UserID,
+		"session_id", claims.SessionID,
+		"ip", device.IP,
+		"user_agent", device.UserAgent,
+	)
+
+	if s.rabbitmq != nil {
+		event := events.SecurityEvent{
+			Kind:       events.RefreshTokenReuseKind,
+			UserID:     claims.UserID,
+			SessionID:  claims.SessionID,
+			IP:         device.IP,
+			UserAgent:  device.UserAgent,
+			OccurredAt: time.Now(),
+		}
+
+		if err := s.rabbitmq.Publish(event); err != nil {
+			s.log.Error("failed to publish security event", "error", err)
+		}
+	}
+}
+
+func (s *service) GetSessions(ctx context.Context, userID, currentSessionID string) ([]*SessionResponse, error) {
+	sessionIDs, err := s.redis.GetSessionIDs(ctx, userID)
+	if err != nil {
+		s.log.Error("failed to get session ids", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("не удалось получить список сессий")
+	}
+
+	sessions := make([]*Session, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		var session Session
+		err := s.redis.GetSession(ctx, sessionID, &session)
+		if stderrors.Is(err, redis.ErrNil) {
+			if err := s.redis.DeleteSession(ctx, userID, sessionID); err != nil {
+				s.log.Warn("failed to clean up expired session", "error", err, "session_id", sessionID)
+			}
+			continue
+		}
+		if err != nil {
+			s.log.Error("failed to get session", "error", err, "session_id", sessionID)
+			return nil, fmt.Errorf("не удалось получить список сессий")
+		}
+		sessions = append(sessions, &session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	result := make([]*SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, SessionToResponse(session, currentSessionID))
+	}
+
+	return result, nil
+}
+
+func (s *service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	var session Session
+	if err := s.redis.GetSession(ctx, sessionID, &session); err != nil || session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	if err := s.endSession(ctx, userID, sessionID); err != nil {
+		s.log.Error("failed to end session", "error", err, "user_id", userID, "session_id", sessionID)
+		return fmt.Errorf("не удалось завершить сессию")
+	}
+
+	s.log.Info("session revoked", "user_id", userID, "session_id", sessionID)
+	return nil
+}
+
+func (s *service) RevokeSessions(ctx context.Context, userID string) error {
+	if err := s.revokeAllTokens(ctx, userID); err != nil {
+		s.log.Error("failed to delete sessions", "error", err, "user_id", userID)
+		return fmt.Errorf("не удалось завершить сессии")
+	}
+
+	s.log.Info("all sessions revoked", "user_id", userID)
+	return nil
+}
+
+// endOtherSessions signs the user out everywhere except the given session.
+// Tokens issued before sessions were introduced carry no session ID, so for
+// them every session is ended.
+func (s *service) endOtherSessions(ctx context.Context, userID, currentSessionID string) error {
+	if currentSessionID == "" {
+		return s.revokeAllTokens(ctx, userID)
+	}
+
+	sessionIDs, err := s.redis.GetSessionIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		if sessionID == currentSessionID {
+			continue
+		}
+		if err := s.endSession(ctx, userID, sessionID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// endSession terminates the session together with its current access token.
+// Once the session is gone its refresh token cannot be used either: refresh
+// needs the stored session and AuthMiddleware takes access tokens only.
+// A missing session is not an error: it has already expired or been revoked.
+// The session is kept if its access token could not be denied, so that the
+// call can be retried.
+func (s *service) endSession(ctx context.Context, userID, sessionID string) error {
+	var session Session
+	err := s.redis.GetSession(ctx, sessionID, &session)
+	if err != nil && !stderrors.Is(err, redis.ErrNil) {
+		return err
+	}
+	if err == nil {
+		if err := s.denyAccessToken(ctx, &session); err != nil {
+			return err
+		}
+	}
+
+	return s.redis.DeleteSession(ctx, userID, sessionID)
+}
+
+func (s *service) denyAccessToken(ctx context.Context, session *Session) error {
+	if session.AccessTokenID == "" {
+		return nil
+	}
+
+	return s.redis.DenyToken(ctx, session.AccessTokenID, time.Until(session.AccessTokenExpiresAt))
+}
+
+// revokeAllTokens invalidates every token the user holds, on every device. It
+// is used for logout everywhere, password resets, email changes and their
+// revert, role removals and account bans.
+func (s *service) revokeAllTokens(ctx context.Context, userID string) error {
+	if err := s.redis.RevokeTokensIssuedBefore(ctx, userID, time.Now()); err != nil {
+		return err
+	}
+
+	return s.redis.DeleteSessions(ctx, userID)
+}
@@ -0,0 +1,209 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/middleware"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestRevokeAllTokensWatermark(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+	user := s.repo.(*fakeRepository).add(&User{Email: "user@example.com"})
+	userID := user.ID.String()
+
+	before, err := s.createSession(ctx, userID, user.Email, DeviceInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.revokeAllTokens(ctx, userID); err != nil {
+		t.Fatalf("revokeAllTokens: %v", err)
+	}
+
+	// Signing in again right away, within the same second, e.g. after a
+	// password reset, must give working tokens.
+	time.Sleep(2 * time.Millisecond)
+	after, err := s.createSession(ctx, userID, user.Email, DeviceInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := newTestAuthHandler(s)
+	if got := authStatus(handler, before.AccessToken); got != http.StatusUnauthorized {
+		t.Errorf("token issued before the revocation: status %d, want 401", got)
+	}
+	if got := authStatus(handler, after.AccessToken); got != http.StatusOK {
+		t.Errorf("token issued after the revocation: status %d, want 200", got)
+	}
+}
+
+func TestEndedSessionTokensAreRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		end  func(s *service, userID, sessionID string) error
+	}{
+		{"logout", func(s *service, userID, sessionID string) error {
+			return s.Logout(context.Background(), userID, sessionID)
+		}},
+		{"revoke session", func(s *service, userID, sessionID string) error {
+			return s.RevokeSession(context.Background(), userID, sessionID)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, _ := newTestService(t)
+			user := s.repo.(*fakeRepository).add(&User{Email: "user@example.com"})
+			userID := user.ID.String()
+
+			ended, err := s.createSession(ctx, userID, user.Email, DeviceInfo{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			other, err := s.createSession(ctx, userID, user.Email, DeviceInfo{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, err := s.jwtHelper.ParseJWT(ended.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.end(s, userID, claims.SessionID); err != nil {
+				t.Fatalf("end session: %v", err)
+			}
+
+			handler := newTestAuthHandler(s)
+			status := func(token string) int { return authStatus(handler, token) }
+
+			if got := status(ended.AccessToken); got != http.StatusUnauthorized {
+				t.Errorf("access token of the ended session: status %d, want 401", got)
+			}
+			if got := status(ended.RefreshToken); got != http.StatusUnauthorized {
+				t.Errorf("refresh token of the ended session: status %d, want 401", got)
+			}
+			if _, err := s.RefreshTokens(ctx, ended.RefreshToken, DeviceInfo{}); err == nil {
+				t.Error("refresh token of the ended session can still be refreshed")
+			}
+			if got := status(other.AccessToken); got != http.StatusOK {
+				t.Errorf("access token of another session: status %d, want 200", got)
+			}
+		})
+	}
+}
+
+func TestChangePasswordEndsOtherSessions(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("old-password-1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	password := string(hash)
+	user := s.repo.(*fakeRepository).add(&User{Email: "user@example.com", Password: &password})
+	userID := user.ID.String()
+
+	current, err := s.createSession(ctx, userID, user.Email, DeviceInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stolen, err := s.createSession(ctx, userID, user.Email, DeviceInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := s.jwtHelper.ParseJWT(current.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.ChangePassword(ctx, userID, claims.SessionID, &ChangePasswordRequest{
+		OldPassword:         "old-password-1",
+		NewPassword:         "new-password-2",
+		LogoutOtherSessions: true,
+	})
+	if err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+
+	handler := newTestAuthHandler(s)
+	if got := authStatus(handler, current.AccessToken); got != http.StatusOK {
+		t.Errorf("access token of the current session: status %d, want 200", got)
+	}
+	if got := authStatus(handler, stolen.AccessToken); got != http.StatusUnauthorized {
+		t.Errorf("access token of another session: status %d, want 401", got)
+	}
+	if got := authStatus(handler, stolen.RefreshToken); got != http.StatusUnauthorized {
+		t.Errorf("refresh token of another session: status %d, want 401", got)
+	}
+	if _, err := s.RefreshTokens(ctx, stolen.RefreshToken, DeviceInfo{}); err == nil {
+		t.Error("refresh token of another session can still be refreshed")
+	}
+	if _, err := s.RefreshTokens(ctx, current.RefreshToken, DeviceInfo{}); err != nil {
+		t.Errorf("refresh token of the current session: %v", err)
+	}
+}
+
+// newTestAuthHandler puts a handler that always succeeds behind the auth
+// middleware, wired to the service's JWT helper and Redis.
+func newTestAuthHandler(s *service) http.Handler {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	return middleware.AuthMiddleware(s.jwtHelper, s.redis)(ok)
+}
+
+func authStatus(handler http.Handler, token string) int {
+	r := httptest.NewRequest("GET", "/users/me", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Code
+}
+
+type failingRoleRepository struct {
+	RoleRepository
+}
+
+func (failingRoleRepository) GetRoles(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	return nil, errors.New("connection reset")
+}
+
+func TestRefreshTokensRetryAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+
+	user := s.repo.(*fakeRepository).add(&User{Email: "user@example.com"})
+	pair, err := s.createSession(ctx, user.ID.String(), user.Email, DeviceInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.roleRepo = failingRoleRepository{}
+	if _, err := s.RefreshTokens(ctx, pair.RefreshToken, DeviceInfo{}); err == nil {
+		t.Fatal("RefreshTokens succeeded while roles could not be loaded")
+	}
+
+	s.roleRepo = fakeRoleRepository{}
+	refreshed, err := s.RefreshTokens(ctx, pair.RefreshToken, DeviceInfo{})
+	if err != nil {
+		t.Fatalf("retry after a transient failure: %v", err)
+	}
+
+	// The retry rotated the token, so the old one is now reuse.
+	if _, err := s.RefreshTokens(ctx, pair.RefreshToken, DeviceInfo{}); err == nil {
+		t.Error("a rotated refresh token was accepted")
+	}
+	if _, err := s.RefreshTokens(ctx, refreshed.RefreshToken, DeviceInfo{}); err == nil {
+		t.Error("the session survived refresh token reuse")
+	}
+}
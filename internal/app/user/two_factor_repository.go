@@ -0,0 +1,157 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	uuid "github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("двухфакторная аутентификация уже включена")
+)
+
+type TwoFactorRepository interface {
+	GetTwoFactor(ctx context.Context, userID uuid.UUID) (*TwoFactor, error)
+	SetSecret(ctx context.Context, userID uuid.UUID, secret string) error
+	Enable(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error
+	Disable(ctx context.Context, userID uuid.UUID) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+}
+
+type twoFactorRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTwoFactorRepository(pool *pgxpool.Pool) TwoFactorRepository {
+	return &twoFactorRepository{pool: pool}
+}
+
+func (r *twoFactorRepository) GetTwoFactor(ctx context.Context, userID uuid.UUID) (*TwoFactor, error) {
+	query := `
+		SELECT totp_secret, totp_enabled_at
+		FROM users
+		WHERE id = $1
+	`
+
+	var twoFactor TwoFactor
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&twoFactor.Secret, &twoFactor.EnabledAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("не удалось получить настройки 2FA: %w", err)
+	}
+
+	return &twoFactor, nil
+}
+
+// SetSecret stores a pending secret. It is not used for sign-in until Enable
+// is called, and cannot replace the secret of an already enabled 2FA.
+func (r *twoFactorRepository) SetSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	query := `
+		UPDATE users
+		SET totp_secret = $2, updated_at = now()
+		WHERE id = $1 AND totp_enabled_at IS NULL
+	`
+
+	result, err := r.pool.Exec(ctx, query, userID, secret)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить секрет 2FA: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrTwoFactorAlreadyEnabled
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) Enable(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE users
+		SET totp_enabled_at = now(), updated_at = now()
+		WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось включить 2FA: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrTwoFactorAlreadyEnabled
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *twoFactorRepository) Disable(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled_at = NULL, updated_at = now()
+		WHERE id = $1
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось отключить 2FA: %w", err)
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseRecoveryCode marks the code as used and reports whether it was valid.
+// The conditional update makes every code single-use.
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := r.pool.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("не удалось проверить код восстановления: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID uuid.UUID, codeHashes []string) error {
+	_, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить коды восстановления: %w", err)
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO user_recovery_codes (user_id, code_hash)
+			VALUES ($1, $2)
+		`, userID, codeHash)
+		if err != nil {
+			return fmt.Errorf("не удалось сохранить код восстановления: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,329 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/totp"
+	"github.com/google/uuid"
+)
+
+const (
+	totpIssuer              = "SportMates"
+	totpSkew                = 1
+	loginChallengeTTL       = 5 * time.Minute
+	loginChallengeAttempts  = 5
+	recoveryCodesCount      = 10
+	recoveryCodeLength      = 10
+	recoveryCodeAlphabet    = "abcdefghijkmnpqrstuvwxyz23456789"
+	recoveryCodeGroupLength = 5
+)
+
+var (
+	ErrTwoFactorNotEnabled = stderrors.New("двухфакторная аутентификация не включена")
+	ErrInvalidTwoFactor    = stderrors.New("неверный код подтверждения")
+)
+
+// completeLogin is called once the first factor has been checked. Users with
+// 2FA get a short-lived challenge instead of tokens.
+func (s *service) completeLogin(ctx context.Context, userID, email string, device DeviceInfo) (*AuthResponse, *TwoFactorChallengeResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		s.log.Error("failed to parse user id", "error", err, "user_id", userID)
+		return nil, nil, fmt.Errorf("произошла ошибка")
+	}
+
+	twoFactor, err := s.twoFactorRepo.GetTwoFactor(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get 2fa settings", "error", err, "user_id", userID)
+		return nil, nil, fmt.Errorf("произошла ошибка")
+	}
+
+	if twoFactor.IsEnabled() {
+		challenge, err := s.createLoginChallenge(ctx, userID, email)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, challenge, nil
+	}
+
+	tokenPair, err := s.createSession(ctx, userID, email, device)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &AuthResponse{
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		ExpiresIn:    tokenPair.ExpiresIn,
+		UserID:       userID,
+		Email:        email,
+	}, nil, nil
+}
+
+func (s *service) createLoginChallenge(ctx context.Context, userID, email string) (*TwoFactorChallengeResponse, error) {
+	rawToken := make([]byte, 32)
+	if _, err := rand.Read(rawToken); err != nil {
+		s.log.Error("failed to generate token", "error", err)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+	token := hex.EncodeToString(rawToken)
+
+	challenge := LoginChallenge{UserID: userID, Email: email}
+	if err := s.redis.StoreLoginChallenge(ctx, token, challenge, loginChallengeTTL); err != nil {
+		s.log.Error("failed to store login challenge", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	s.log.Info("second factor requested", "user_id", userID)
+
+	return &TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresIn:         int64(loginChallengeTTL / time.Second),
+	}, nil
+}
+
+func (s *service) LoginTwoFactor(ctx context.Context, req *LoginTwoFactorRequest, device DeviceInfo) (*AuthResponse, error) {
+	var challenge LoginChallenge
+	if err := s.redis.GetLoginChallenge(ctx, req.ChallengeToken, &challenge); err != nil {
+		s.log.Warn("invalid or expired login challenge", "error", err)
+		return nil, fmt.Errorf("неверная или устаревшая попытка входа")
+	}
+
+	attempts, err := s.redis.CountLoginChallengeAttempt(ctx, req.ChallengeToken, loginChallengeTTL)
+	if err != nil {
+		s.log.Error("failed to count login challenge attempts", "error", err)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+	if attempts > loginChallengeAttempts {
+		if err := s.redis.DeleteLoginChallenge(ctx, req.ChallengeToken); err != nil {
+			s.log.Warn("failed to delete login challenge", "error", err)
+		}
+		s.log.Warn("too many second factor attempts", "user_id", challenge.UserID)
+		return nil, fmt.Errorf("слишком много попыток, войдите заново")
+	}
+
+	userID, err := uuid.Parse(challenge.UserID)
+	if err != nil {
+		s.log.Error("failed to parse user id", "error", err, "user_id", challenge.UserID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	twoFactor, err := s.twoFactorRepo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		s.log.Error("failed to get 2fa settings", "error", err, "user_id", challenge.UserID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	if err := s.checkSecondFactor(ctx, userID, twoFactor, req.Code, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.redis.DeleteLoginChallenge(ctx, req.ChallengeToken); err != nil {
+		s.log.Warn("failed to delete login challenge", "error", err)
+	}
+
+	tokenPair, err := s.createSession(ctx, challenge.UserID, challenge.Email, device)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("user logged in with second factor successfully", "user_id", challenge.UserID)
+
+	return &AuthResponse{
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		ExpiresIn:    tokenPair.ExpiresIn,
+		UserID:       challenge.UserID,
+		Email:        challenge.Email,
+	}, nil
+}
+
+// EnrollTwoFactor generates a new secret. It only takes effect after
+// VerifyTwoFactor, so an abandoned enrollment does not lock the user out.
+func (s *service) EnrollTwoFactor(ctx context.Context, userID string) (*EnrollTwoFactorResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("неверный ID пользователя")
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get user", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		s.log.Error("failed to generate totp secret", "error", err)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	if err := s.twoFactorRepo.SetSecret(ctx, id, secret); err != nil {
+		if stderrors.Is(err, ErrTwoFactorAlreadyEnabled) {
+			return nil, err
+		}
+		s.log.Error("failed to save totp secret", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	s.log.Info("2fa enrollment started", "user_id", userID)
+
+	return &EnrollTwoFactorResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.KeyURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// VerifyTwoFactor activates 2FA and returns the recovery codes. They are
+// stored hashed and shown to the user only this once.
+func (s *service) VerifyTwoFactor(ctx context.Context, userID string, req *VerifyTwoFactorRequest) (*VerifyTwoFactorResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("неверный ID пользователя")
+	}
+
+	twoFactor, err := s.twoFactorRepo.GetTwoFactor(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get 2fa settings", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	if twoFactor.IsEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if twoFactor.Secret == nil {
+		return nil, fmt.Errorf("сначала начните подключение 2FA")
+	}
+
+	if _, ok := totp.Validate(*twoFactor.Secret, req.Code, time.Now(), totpSkew); !ok {
+		return nil, ErrInvalidTwoFactor
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		s.log.Error("failed to generate recovery codes", "error", err)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	if err := s.twoFactorRepo.Enable(ctx, id, hashes); err != nil {
+		if stderrors.Is(err, ErrTwoFactorAlreadyEnabled) {
+			return nil, err
+		}
+		s.log.Error("failed to enable 2fa", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("произошла ошибка")
+	}
+
+	s.log.Info("2fa enabled", "user_id", userID)
+
+	return &VerifyTwoFactorResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *service) DisableTwoFactor(ctx context.Context, userID string, req *DisableTwoFactorRequest) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("неверный ID пользователя")
+	}
+
+	twoFactor, err := s.twoFactorRepo.GetTwoFactor(ctx, id)
+	if err != nil {
+		s.log.Error("failed to get 2fa settings", "error", err, "user_id", userID)
+		return fmt.Errorf("произошла ошибка")
+	}
+
+	if !twoFactor.IsEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if err := s.checkSecondFactor(ctx, id, twoFactor, req.Code, time.Now()); err != nil {
+		return err
+	}
+
+	if err := s.twoFactorRepo.Disable(ctx, id); err != nil {
+		s.log.Error("failed to disable 2fa", "error", err, "user_id", userID)
+		return fmt.Errorf("произошла ошибка")
+	}
+
+	s.log.Info("2fa disabled", "user_id", userID)
+	return nil
+}
+
+// checkSecondFactor accepts either a TOTP code or an unused recovery code.
+func (s *service) checkSecondFactor(ctx context.Context, userID uuid.UUID, twoFactor *TwoFactor, code string, now time.Time) error {
+	if !twoFactor.IsEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(*twoFactor.Secret, code, now, totpSkew)
+		if !ok {
+			return ErrInvalidTwoFactor
+		}
+
+		firstUse, err := s.redis.MarkTOTPStepUsed(ctx, userID.String(), step)
+		if err != nil {
+			s.log.Error("failed to mark totp code as used", "error", err, "user_id", userID)
+			return fmt.Errorf("произошла ошибка")
+		}
+		if !firstUse {
+			s.log.Warn("totp code reused", "user_id", userID)
+			return ErrInvalidTwoFactor
+		}
+
+		return nil
+	}
+
+	valid, err := s.twoFactorRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		s.log.Error("failed to use recovery code", "error", err, "user_id", userID)
+		return fmt.Errorf("произошла ошибка")
+	}
+	if !valid {
+		return ErrInvalidTwoFactor
+	}
+
+	s.log.Info("recovery code used", "user_id", userID)
+	return nil
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+
+	for i := 0; i < recoveryCodesCount; i++ {
+		raw := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+
+		var code strings.Builder
+		for j, b := range raw {
+			if j > 0 && j%recoveryCodeGroupLength == 0 {
+				code.WriteByte('-')
+			}
+			code.WriteByte(recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+		}
+
+		codes = append(codes, code.String())
+		hashes = append(hashes, hashRecoveryCode(code.String()))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so that codes typed by hand
+// still match. Codes are random enough for a plain SHA-256 to be sufficient.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
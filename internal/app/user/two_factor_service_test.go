@@ -0,0 +1,137 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/totp"
+	"github.com/google/uuid"
+)
+
+func enabledTwoFactor(t *testing.T) *TwoFactor {
+	t.Helper()
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabledAt := time.Now()
+	return &TwoFactor{Secret: &secret, EnabledAt: &enabledAt}
+}
+
+func TestCheckSecondFactorTOTP(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		issued  time.Time
+		wantErr error
+	}{
+		{"current step", now, nil},
+		{"previous step", now.Add(-totp.Period), nil},
+		{"next step", now.Add(totp.Period), nil},
+		{"outside skew", now.Add(-2 * totp.Period), ErrInvalidTwoFactor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t)
+			twoFactor := enabledTwoFactor(t)
+
+			code, err := totp.GenerateCode(*twoFactor.Secret, tt.issued)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = s.checkSecondFactor(ctx, uuid.New(), twoFactor, code, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("checkSecondFactor() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckSecondFactorRejectsReplay(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+	userID := uuid.New()
+	twoFactor := enabledTwoFactor(t)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	code, err := totp.GenerateCode(*twoFactor.Secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.checkSecondFactor(ctx, userID, twoFactor, code, now); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+
+	// The code is still within the skew window a step later, but its step
+	// has been used.
+	if err := s.checkSecondFactor(ctx, userID, twoFactor, code, now.Add(totp.Period)); !errors.Is(err, ErrInvalidTwoFactor) {
+		t.Fatalf("replay: got %v, want %v", err, ErrInvalidTwoFactor)
+	}
+
+	// Steps are tracked per user.
+	if err := s.checkSecondFactor(ctx, uuid.New(), twoFactor, code, now); err != nil {
+		t.Fatalf("other user: %v", err)
+	}
+
+	next, err := totp.GenerateCode(*twoFactor.Secret, now.Add(totp.Period))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.checkSecondFactor(ctx, userID, twoFactor, next, now.Add(totp.Period)); err != nil {
+		t.Fatalf("next step: %v", err)
+	}
+}
+
+func TestCheckSecondFactorRecoveryCodes(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+	userID := uuid.New()
+	twoFactor := enabledTwoFactor(t)
+	now := time.Now()
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodesCount {
+		t.Fatalf("got %d recovery codes, want %d", len(codes), recoveryCodesCount)
+	}
+	if err := s.twoFactorRepo.Enable(ctx, userID, hashes); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.checkSecondFactor(ctx, userID, twoFactor, codes[0], now); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := s.checkSecondFactor(ctx, userID, twoFactor, codes[0], now); !errors.Is(err, ErrInvalidTwoFactor) {
+		t.Fatalf("second use: got %v, want %v", err, ErrInvalidTwoFactor)
+	}
+
+	// Codes typed by hand may differ in case and separators.
+	typed := strings.ToUpper(strings.ReplaceAll(codes[1], "-", " "))
+	if err := s.checkSecondFactor(ctx, userID, twoFactor, typed, now); err != nil {
+		t.Fatalf("typed code: %v", err)
+	}
+
+	if err := s.checkSecondFactor(ctx, userID, twoFactor, "aaaaa-bbbbb", now); !errors.Is(err, ErrInvalidTwoFactor) {
+		t.Fatalf("unknown code: got %v, want %v", err, ErrInvalidTwoFactor)
+	}
+}
+
+func TestCheckSecondFactorNotEnabled(t *testing.T) {
+	s, _ := newTestService(t)
+
+	err := s.checkSecondFactor(context.Background(), uuid.New(), &TwoFactor{}, "123456", time.Now())
+	if !errors.Is(err, ErrTwoFactorNotEnabled) {
+		t.Fatalf("got %v, want %v", err, ErrTwoFactorNotEnabled)
+	}
+}
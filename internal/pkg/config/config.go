@@ -3,29 +3,39 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/drone/envsubst"
 	"gopkg.in/yaml.v3"
 )
 
+// LocalEnv is the environment of a developer machine. Features that are
+// unsafe elsewhere, such as writing SMS codes to the log, are limited to it.
+const LocalEnv = "local"
+
 type Config struct {
 	Env                string         `yaml:"env"`
+	PublicURL          string         `yaml:"public_url"`
 	PostgresConnString string         `yaml:"postgres_conn_string"`
 	HTTPServer         HTTPServer     `yaml:"http_server"`
 	Log                Log            `yaml:"log"`
 	JWT                JWT            `yaml:"jwt"`
 	GoogleOAuth        GoogleOAuth    `yaml:"google_oauth"`
+	PasswordPolicy     PasswordPolicy `yaml:"password_policy"`
 	SMTP               SMTP           `yaml:"smtp"`
+	SMS                SMSConfig      `yaml:"sms"`
+	Chat               ChatConfig     `yaml:"chat"`
 	Redis              RedisConfig    `yaml:"redis"`
 	RabbitMQ           RabbitMQConfig `yaml:"rabbitmq"`
 	MinioConfig        MinioConfig    `yaml:"minio"`
 }
 
 type HTTPServer struct {
-	Address     string        `yaml:"address"`
-	Timeout     time.Duration `yaml:"timeout"`
-	IdleTimeout time.Duration `yaml:"idle_timeout"`
+	Address        string        `yaml:"address"`
+	Timeout        time.Duration `yaml:"timeout"`
+	IdleTimeout    time.Duration `yaml:"idle_timeout"`
+	TrustedProxies []string      `yaml:"trusted_proxies"`
 }
 
 type Log struct {
@@ -36,13 +46,34 @@ type Log struct {
 }
 
 type JWT struct {
-	Secret string `yaml:"secret"`
+	Secret            string    `yaml:"secret"`
+	SecretVerifyUntil time.Time `yaml:"secret_verify_until"`
+	SigningKeyID      string    `yaml:"signing_key_id"`
+	Keys              []JWTKey  `yaml:"keys"`
+}
+
+type JWTKey struct {
+	ID             string    `yaml:"id"`
+	Algorithm      string    `yaml:"algorithm"`
+	PrivateKeyFile string    `yaml:"private_key_file"`
+	PublicKeyFile  string    `yaml:"public_key_file"`
+	VerifyUntil    time.Time `yaml:"verify_until"`
 }
 
 type GoogleOAuth struct {
 	ClientID     string `yaml:"client_id"`
 	ClientSecret string `yaml:"client_secret"`
 	RedirectURL  string `yaml:"redirect_url"`
+	IssuerURL    string `yaml:"issuer_url"`
+}
+
+type PasswordPolicy struct {
+	MinLength      int  `yaml:"min_length"`
+	MaxLength      int  `yaml:"max_length"`
+	RequireUpper   bool `yaml:"require_upper"`
+	RequireLower   bool `yaml:"require_lower"`
+	RequireDigit   bool `yaml:"require_digit"`
+	RequireSpecial bool `yaml:"require_special"`
 }
 
 type SMTP struct {
@@ -54,6 +85,14 @@ type SMTP struct {
 	FromAddress string `yaml:"from_address"`
 }
 
+type SMSConfig struct {
+	Provider string `yaml:"provider"`
+}
+
+type ChatConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
+
 type RedisConfig struct {
 	Address  string `yaml:"address"`
 	Password string `yaml:"password"`
@@ -94,5 +133,7 @@ func MustLoad() *Config {
 		log.Fatalf("cannot parse config: %s", err)
 	}
 
+	cfg.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+
 	return &cfg
 }
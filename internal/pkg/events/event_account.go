@@ -0,0 +1,19 @@
+package events
+
+import "time"
+
+const (
+	AccountExportJob   = "export"
+	AccountDeletionJob = "deletion"
+)
+
+type AccountJobEvent struct {
+	JobID       string    `json:"job_id"`
+	Kind        string    `json:"kind"`
+	UserID      string    `json:"user_id"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+func (e AccountJobEvent) GetType() string {
+	return "account_job"
+}
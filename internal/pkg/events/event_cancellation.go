@@ -0,0 +1,17 @@
+package events
+
+import "time"
+
+// EventCanceledEvent tells participants that an event was canceled. When a
+// series is canceled from an event onwards, SeriesID is set and every
+// occurrence starting at or after From is canceled as well.
+type EventCanceledEvent struct {
+	EventID    string     `json:"event_id"`
+	SeriesID   *string    `json:"series_id,omitempty"`
+	From       *time.Time `json:"from,omitempty"`
+	CanceledAt time.Time  `json:"canceled_at"`
+}
+
+func (e EventCanceledEvent) GetType() string {
+	return "event_canceled"
+}
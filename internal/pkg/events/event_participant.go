@@ -0,0 +1,13 @@
+package events
+
+import "time"
+
+type ParticipantPromotedEvent struct {
+	EventID    string    `json:"event_id"`
+	UserID     string    `json:"user_id"`
+	PromotedAt time.Time `json:"promoted_at"`
+}
+
+func (e ParticipantPromotedEvent) GetType() string {
+	return "participant_promoted"
+}
@@ -0,0 +1,20 @@
+package events
+
+import "time"
+
+const (
+	RefreshTokenReuseKind = "refresh_token_reuse"
+)
+
+type SecurityEvent struct {
+	Kind       string    `json:"kind"`
+	UserID     string    `json:"user_id"`
+	SessionID  string    `json:"session_id,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (e SecurityEvent) GetType() string {
+	return "security"
+}
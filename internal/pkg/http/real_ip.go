@@ -0,0 +1,109 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP replaces RemoteAddr with the client address from X-Forwarded-For or
+// X-Real-IP, but only for requests that come from one of the trusted proxies.
+// Anyone else could send these headers to pose as any address, so for them
+// RemoteAddr is left as is. Entries are addresses or CIDR ranges and may hold
+// several comma-separated values.
+func RealIP(trustedProxies []string) (func(http.Handler) http.Handler, error) {
+	trusted, err := parseTrustedProxies(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	isTrusted := func(addr netip.Addr) bool {
+		for _, prefix := range trusted {
+			if prefix.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if peer, ok := remoteAddr(r.RemoteAddr); ok && isTrusted(peer) {
+				if client, ok := forwardedClient(r, isTrusted); ok {
+					r.RemoteAddr = client.String()
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+func parseTrustedProxies(entries []string) ([]netip.Prefix, error) {
+	var result []netip.Prefix
+	for _, entry := range entries {
+		for _, value := range strings.Split(entry, ",") {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+
+			if strings.Contains(value, "/") {
+				prefix, err := netip.ParsePrefix(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+				}
+				result = append(result, prefix.Masked())
+				continue
+			}
+
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+			}
+			addr = addr.Unmap()
+			result = append(result, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+
+	return result, nil
+}
+
+func remoteAddr(value string) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(value)
+	if err != nil {
+		host = value
+	}
+
+	addr, err := netip.ParseAddr(host)
+	return addr.Unmap(), err == nil
+}
+
+// forwardedClient walks X-Forwarded-For from the nearest hop and returns the
+// first address that is not a trusted proxy. Hops further left were added by
+// the client itself and cannot be trusted.
+func forwardedClient(r *http.Request, isTrusted func(netip.Addr) bool) (netip.Addr, bool) {
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !isTrusted(client) {
+			return client, true
+		}
+	}
+	if client.IsValid() {
+		return client, true
+	}
+
+	addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+	return addr.Unmap(), err == nil
+}
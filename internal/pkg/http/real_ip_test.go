@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	realIP, err := RealIP([]string{"10.0.0.0/8, 192.168.1.10", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	handler := realIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	}))
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		realIP       string
+		wantRemoteIP string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "", "203.0.113.7:5000"},
+		{"direct client forging headers", "203.0.113.7:5000", []string{"198.51.100.1"}, "198.51.100.2", "203.0.113.7:5000"},
+		{"trusted proxy", "10.1.2.3:5000", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"trusted single address", "192.168.1.10:5000", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"untrusted neighbour of a trusted address", "192.168.1.11:5000", []string{"198.51.100.1"}, "", "192.168.1.11:5000"},
+		{"client prepends a forged hop", "10.1.2.3:5000", []string{"198.51.100.9, 198.51.100.1"}, "", "198.51.100.1"},
+		{"chain of trusted proxies", "10.1.2.3:5000", []string{"198.51.100.1, 10.0.0.5", "10.0.0.6"}, "", "198.51.100.1"},
+		{"only trusted hops", "10.1.2.3:5000", []string{"10.0.0.5"}, "", "10.0.0.5"},
+		{"garbage hop stops the walk", "10.1.2.3:5000", []string{"198.51.100.1, not-an-ip"}, "", "10.1.2.3:5000"},
+		{"X-Real-IP from a trusted proxy", "10.1.2.3:5000", nil, "198.51.100.2", "198.51.100.2"},
+		{"IPv4-mapped proxy address", "[::ffff:10.1.2.3]:5000", []string{"198.51.100.1"}, "", "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/users/login", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+			if got != tt.wantRemoteIP {
+				t.Errorf("RemoteAddr = %q, want %q", got, tt.wantRemoteIP)
+			}
+		})
+	}
+}
+
+func TestRealIPWithoutTrustedProxies(t *testing.T) {
+	realIP, err := RealIP(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	handler := realIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/users/login", nil)
+	r.RemoteAddr = "127.0.0.1:5000"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	r.Header.Set("X-Real-IP", "198.51.100.1")
+
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	if got != "127.0.0.1:5000" {
+		t.Errorf("RemoteAddr = %q, want the peer address", got)
+	}
+}
+
+func TestRealIPInvalidConfig(t *testing.T) {
+	for _, entry := range []string{"10.0.0.0/33", "proxy.local"} {
+		if _, err := RealIP([]string{entry}); err == nil {
+			t.Errorf("RealIP(%q) succeeded", entry)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dateTimeFormat = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+type Status string
+
+const (
+	StatusConfirmed Status = "CONFIRMED"
+	StatusTentative Status = "TENTATIVE"
+	StatusCancelled Status = "CANCELLED"
+)
+
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+type Event struct {
+	UID          string
+	Sequence     int
+	Stamp        time.Time
+	LastModified time.Time
+	Start        time.Time
+	End          *time.Time
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Latitude     *float64
+	Longitude    *float64
+	Status       Status
+}
+
+// Encode renders the calendar in RFC 5545 format. All date-times are written
+// in UTC, so no VTIMEZONE components are needed.
+func (c *Calendar) Encode() []byte {
+	var buf bytes.Buffer
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:"+escapeText(c.ProdID))
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+
+	for _, event := range c.Events {
+		event.encode(&buf)
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+
+	return buf.Bytes()
+}
+
+func (e *Event) encode(buf *bytes.Buffer) {
+	writeLine(buf, "BEGIN:VEVENT")
+	writeLine(buf, "UID:"+escapeText(e.UID))
+	writeLine(buf, "SEQUENCE:"+strconv.Itoa(e.Sequence))
+	writeLine(buf, "DTSTAMP:"+formatTime(e.Stamp))
+	if !e.LastModified.IsZero() {
+		writeLine(buf, "LAST-MODIFIED:"+formatTime(e.LastModified))
+	}
+	writeLine(buf, "DTSTART:"+formatTime(e.Start))
+	if e.End != nil {
+		writeLine(buf, "DTEND:"+formatTime(*e.End))
+	}
+	writeLine(buf, "SUMMARY:"+escapeText(e.Summary))
+	if e.Description != "" {
+		writeLine(buf, "DESCRIPTION:"+escapeText(e.Description))
+	}
+	if e.Location != "" {
+		writeLine(buf, "LOCATION:"+escapeText(e.Location))
+	}
+	if e.Latitude != nil && e.Longitude != nil {
+		writeLine(buf, fmt.Sprintf("GEO:%s;%s",
+			strconv.FormatFloat(*e.Latitude, 'f', 6, 64),
+			strconv.FormatFloat(*e.Longitude, 'f', 6, 64),
+		))
+	}
+	if e.URL != "" {
+		writeLine(buf, "URL:"+e.URL)
+	}
+	if e.Status != "" {
+		writeLine(buf, "STATUS:"+string(e.Status))
+	}
+	writeLine(buf, "END:VEVENT")
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
+
+func escapeText(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`;`, `\;`,
+		`,`, `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return replacer.Replace(value)
+}
+
+// writeLine folds content lines longer than 75 octets without splitting
+// multi-byte UTF-8 characters, as required by RFC 5545 section 3.1.
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
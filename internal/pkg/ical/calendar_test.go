@@ -0,0 +1,125 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain", "Evening run", "Evening run"},
+		{"backslash", `C:\games`, `C:\\games`},
+		{"semicolon and comma", "Football; 5v5, indoor", `Football\; 5v5\, indoor`},
+		{"newlines", "line 1\nline 2\r\nline 3\rline 4", `line 1\nline 2\nline 3\nline 4`},
+		{"escaped sequence is not unescaped", `\n`, `\\n`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeText(tt.value); got != tt.want {
+				t.Errorf("escapeText(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteLineFolds(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Evening run"},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"76 octets", "SUMMARY:" + strings.Repeat("a", 68)},
+		{"several folds", "DESCRIPTION:" + strings.Repeat("abcdefghij", 30)},
+		{"two-byte runes", "SUMMARY:" + strings.Repeat("Футбол ", 30)},
+		{"four-byte runes", "SUMMARY:" + strings.Repeat("⚽🏀", 40)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeLine(&buf, tt.line)
+
+			out := buf.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("line %q does not end with CRLF", out)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, line := range lines {
+				if len(line) > maxLineOctets {
+					t.Errorf("line %d is %d octets long", i, len(line))
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i, line)
+				}
+			}
+
+			// Unfolding as described in RFC 5545 section 3.1 restores the line.
+			if got := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); got != tt.line {
+				t.Errorf("unfolded line = %q, want %q", got, tt.line)
+			}
+		})
+	}
+}
+
+func TestCalendarEncode(t *testing.T) {
+	start := time.Date(2025, 6, 1, 18, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+	end := start.Add(90 * time.Minute)
+	latitude, longitude := 55.751244, 37.618423
+
+	calendar := &Calendar{
+		ProdID: "-//SportMates//Events//RU",
+		Name:   "SportMates",
+		Events: []Event{{
+			UID:       "event-1@sportmates",
+			Sequence:  2,
+			Stamp:     start,
+			Start:     start,
+			End:       &end,
+			Summary:   "Football, 5v5",
+			Location:  "Luzhniki; field 3",
+			URL:       "https://sportmates.test/events/event-1",
+			Latitude:  &latitude,
+			Longitude: &longitude,
+			Status:    StatusConfirmed,
+		}},
+	}
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//SportMates//Events//RU",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:SportMates",
+		"BEGIN:VEVENT",
+		"UID:event-1@sportmates",
+		"SEQUENCE:2",
+		"DTSTAMP:20250601T153000Z",
+		"DTSTART:20250601T153000Z",
+		"DTEND:20250601T170000Z",
+		`SUMMARY:Football\, 5v5`,
+		`LOCATION:Luzhniki\; field 3`,
+		"GEO:55.751244;37.618423",
+		"URL:https://sportmates.test/events/event-1",
+		"STATUS:CONFIRMED",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n") + "\r\n"
+
+	if got := string(calendar.Encode()); got != want {
+		t.Errorf("Encode() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package jwthelper
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestJWKS(t *testing.T) {
+	rsaKey := newRSAKey(t, "b-rsa")
+	edKey := newEdDSAKey(t, "a-ed")
+	retired := newRSAKey(t, "c-retired")
+
+	h := newHelper(t, testSecret, time.Now().Add(time.Hour), rsaKey.ID,
+		rsaKey, verifyOnly(edKey, time.Now().Add(time.Hour)), verifyOnly(retired, time.Now().Add(-time.Minute)))
+
+	set := h.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2: %+v", len(set.Keys), set.Keys)
+	}
+
+	tests := []struct {
+		name  string
+		jwk   JWK
+		check func(t *testing.T, jwk JWK)
+	}{
+		{"Ed25519 key", set.Keys[0], func(t *testing.T, jwk JWK) {
+			if jwk.KeyID != edKey.ID || jwk.KeyType != "OKP" || jwk.Curve != "Ed25519" || jwk.Algorithm != AlgorithmEdDSA {
+				t.Errorf("jwk = %+v", jwk)
+			}
+			x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ed25519.PublicKey(x).Equal(edKey.PublicKey) {
+				t.Error("x does not match the public key")
+			}
+		}},
+		{"RSA key", set.Keys[1], func(t *testing.T, jwk JWK) {
+			if jwk.KeyID != rsaKey.ID || jwk.KeyType != "RSA" || jwk.Algorithm != AlgorithmRS256 || jwk.Curve != "" {
+				t.Errorf("jwk = %+v", jwk)
+			}
+			n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+			if err != nil {
+				t.Fatal(err)
+			}
+			publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+			if !publicKey.Equal(rsaKey.PublicKey) {
+				t.Error("n and e do not match the public key")
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.jwk.Use != "sig" {
+				t.Errorf("use = %q, want sig", tt.jwk.Use)
+			}
+			tt.check(t, tt.jwk)
+		})
+	}
+}
+
+func TestJWKSHandler(t *testing.T) {
+	key := newEdDSAKey(t, "ed")
+	h := newHelper(t, "", time.Time{}, key.ID, key)
+
+	w := httptest.NewRecorder()
+	h.JWKSHandler(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	var body map[string][]map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body["keys"]) != 1 || body["keys"][0]["kid"] != key.ID {
+		t.Fatalf("body = %v", body)
+	}
+	if _, ok := body["keys"][0]["d"]; ok {
+		t.Error("JWKS exposes the private key")
+	}
+}
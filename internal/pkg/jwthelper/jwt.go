@@ -5,23 +5,45 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type JWTHelper struct {
-	secret []byte
+	secret            []byte
+	secretVerifyUntil time.Time
+	keys              map[string]*Key
+	signingKey        *Key
 }
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Type   string `json:"type"`
+	UserID    string   `json:"user_id"`
+	Email     string   `json:"email"`
+	Type      string   `json:"type"`
+	SessionID string   `json:"sid,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	// IssuedAtMs repeats iat with millisecond precision, so a token issued
+	// right after a revocation is not mistaken for one issued before it.
+	IssuedAtMs int64 `json:"iat_ms,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// IssuedAtTime returns when the token was issued. Tokens without iat_ms fall
+// back to the whole-second iat.
+func (c *Claims) IssuedAtTime() time.Time {
+	if c.IssuedAtMs != 0 {
+		return time.UnixMilli(c.IssuedAtMs)
+	}
+	if c.IssuedAt != nil {
+		return c.IssuedAt.Time
+	}
+	return time.Time{}
+}
+
 type TokenPair struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	ExpiresIn    int64  `json:"expires_in"`
+	AccessToken   string `json:"access_token"`
+	RefreshToken  string `json:"refresh_token"`
+	ExpiresIn     int64  `json:"expires_in"`
+	AccessTokenID string `json:"-"`
 }
 
 func NewJwtHelper(secret string) (*JWTHelper, error) {
@@ -31,44 +53,63 @@ func NewJwtHelper(secret string) (*JWTHelper, error) {
 	return &JWTHelper{secret: []byte(secret)}, nil
 }
 
-func (h *JWTHelper) GenerateJWT(userID, email, tokenType string, expiresIn time.Duration) (string, error) {
-	expirationTime := time.Now().Add(expiresIn)
+func (h *JWTHelper) GenerateJWT(userID, email, sessionID, tokenType string, expiresIn time.Duration) (string, error) {
+	return h.generateJWT(uuid.NewString(), userID, email, sessionID, nil, tokenType, expiresIn)
+}
+
+func (h *JWTHelper) generateJWT(tokenID, userID, email, sessionID string, roles []string, tokenType string, expiresIn time.Duration) (string, error) {
+	now := time.Now()
 
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Type:   tokenType,
+		UserID:     userID,
+		Email:      email,
+		Type:       tokenType,
+		SessionID:  sessionID,
+		Roles:      roles,
+		IssuedAtMs: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "sportmates-api",
+			ID:        tokenID,
 		},
 	}
 
+	if h.signingKey != nil {
+		token := jwt.NewWithClaims(h.signingKey.Method, claims)
+		token.Header["kid"] = h.signingKey.ID
+		return token.SignedString(h.signingKey.PrivateKey)
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(h.secret)
 }
 
-func (h *JWTHelper) GenerateTokenPair(userID, email string) (*TokenPair, error) {
-	accessToken, err := h.GenerateJWT(userID, email, "access", 15*time.Minute)
+// GenerateTokenPair puts the roles into the access token only. The refresh
+// token does not carry them, so role changes take effect on the next refresh.
+func (h *JWTHelper) GenerateTokenPair(userID, email, sessionID string, roles []string) (*TokenPair, error) {
+	accessTokenID := uuid.NewString()
+
+	accessToken, err := h.generateJWT(accessTokenID, userID, email, sessionID, roles, "access", 15*time.Minute)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := h.GenerateJWT(userID, email, "refresh", 7*24*time.Hour)
+	refreshToken, err := h.GenerateJWT(userID, email, sessionID, "refresh", 7*24*time.Hour)
 	if err != nil {
 		return nil, err
 	}
 
 	return &TokenPair{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    int64(15 * time.Minute / time.Second),
+		AccessToken:   accessToken,
+		RefreshToken:  refreshToken,
+		ExpiresIn:     int64(15 * time.Minute / time.Second),
+		AccessTokenID: accessTokenID,
 	}, nil
 }
 
 func (h *JWTHelper) GenerateDefaultToken(userID, email string) (string, error) {
-	return h.GenerateJWT(userID, email, "access", 24*time.Hour)
+	return h.GenerateJWT(userID, email, "", "access", 24*time.Hour)
 }
 
 func (h *JWTHelper) ParseJWT(tokenString string) (*Claims, error) {
@@ -76,16 +117,7 @@ func (h *JWTHelper) ParseJWT(tokenString string) (*Claims, error) {
 		return nil, errors.New("empty token string")
 	}
 
-	token, err := jwt.ParseWithClaims(
-		tokenString,
-		&Claims{},
-		func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return h.secret, nil
-		},
-	)
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, h.verificationKey)
 
 	if err != nil {
 		return nil, errors.New("invalid token")
@@ -103,14 +135,25 @@ func (h *JWTHelper) ParseJWT(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
-func (h *JWTHelper) ValidateAccessToken(tokenString string) (bool, error) {
+// ParseAccessToken is ParseJWT for bearer tokens: refresh tokens live much
+// longer and are not tracked on the denylist, so they must not authenticate
+// requests.
+func (h *JWTHelper) ParseAccessToken(tokenString string) (*Claims, error) {
 	claims, err := h.ParseJWT(tokenString)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	if claims.Type != "access" {
-		return false, errors.New("not an access token")
+		return nil, errors.New("not an access token")
+	}
+
+	return claims, nil
+}
+
+func (h *JWTHelper) ValidateAccessToken(tokenString string) (bool, error) {
+	if _, err := h.ParseAccessToken(tokenString); err != nil {
+		return false, err
 	}
 
 	return true, nil
@@ -0,0 +1,206 @@
+package jwthelper
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Key is an asymmetric key identified by kid. Keys without a private part can
+// only verify tokens; they are kept during rotation until VerifyUntil passes.
+type Key struct {
+	ID          string
+	Method      jwt.SigningMethod
+	PrivateKey  crypto.PrivateKey
+	PublicKey   crypto.PublicKey
+	VerifyUntil time.Time
+}
+
+func (k *Key) IsActive(now time.Time) bool {
+	return k.VerifyUntil.IsZero() || now.Before(k.VerifyUntil)
+}
+
+// NewFromConfig uses asymmetric keys when a signing key is configured and
+// falls back to the HS256 secret otherwise. When both are set, tokens signed
+// with the secret are accepted until SecretVerifyUntil, which allows migrating
+// off HS256 without logging everyone out. Without it the secret is not used.
+func NewFromConfig(cfg *config.JWT) (*JWTHelper, error) {
+	if cfg.SigningKeyID == "" {
+		return NewJwtHelper(cfg.Secret)
+	}
+
+	keys := make([]*Key, 0, len(cfg.Keys))
+	for _, keyCfg := range cfg.Keys {
+		key, err := LoadKey(keyCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT key %q: %w", keyCfg.ID, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return NewJwtHelperWithKeys(cfg.Secret, cfg.SecretVerifyUntil, keys, cfg.SigningKeyID)
+}
+
+func NewJwtHelperWithKeys(secret string, secretVerifyUntil time.Time, keys []*Key, signingKeyID string) (*JWTHelper, error) {
+	h := &JWTHelper{keys: make(map[string]*Key, len(keys))}
+	if secret != "" && time.Now().Before(secretVerifyUntil) {
+		h.secret = []byte(secret)
+		h.secretVerifyUntil = secretVerifyUntil
+	}
+
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("JWT key without id")
+		}
+		if _, exists := h.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate JWT key id %q", key.ID)
+		}
+		h.keys[key.ID] = key
+	}
+
+	signingKey, ok := h.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q is not configured", signingKeyID)
+	}
+	if signingKey.PrivateKey == nil {
+		return nil, fmt.Errorf("signing key %q has no private key", signingKeyID)
+	}
+	if !signingKey.IsActive(time.Now()) {
+		return nil, fmt.Errorf("signing key %q has expired", signingKeyID)
+	}
+	h.signingKey = signingKey
+
+	return h, nil
+}
+
+func LoadKey(cfg config.JWTKey) (*Key, error) {
+	key := &Key{
+		ID:          cfg.ID,
+		VerifyUntil: cfg.VerifyUntil,
+	}
+
+	switch cfg.Algorithm {
+	case AlgorithmRS256:
+		key.Method = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", cfg.Algorithm)
+	}
+
+	switch {
+	case cfg.PrivateKeyFile != "":
+		data, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := key.setPrivateKey(data); err != nil {
+			return nil, err
+		}
+	case cfg.PublicKeyFile != "":
+		data, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := key.setPublicKey(data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("either private_key_file or public_key_file is required")
+	}
+
+	return key, nil
+}
+
+func (k *Key) setPrivateKey(data []byte) error {
+	switch k.Method {
+	case jwt.SigningMethodRS256:
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return err
+		}
+		k.PrivateKey = privateKey
+		k.PublicKey = &privateKey.PublicKey
+	default:
+		privateKey, err := jwt.ParseEdPrivateKeyFromPEM(data)
+		if err != nil {
+			return err
+		}
+		edKey, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return errors.New("not an Ed25519 private key")
+		}
+		k.PrivateKey = edKey
+		k.PublicKey = edKey.Public()
+	}
+
+	return nil
+}
+
+func (k *Key) setPublicKey(data []byte) error {
+	switch k.Method {
+	case jwt.SigningMethodRS256:
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return err
+		}
+		k.PublicKey = publicKey
+	default:
+		publicKey, err := jwt.ParseEdPublicKeyFromPEM(data)
+		if err != nil {
+			return err
+		}
+		if _, ok := publicKey.(ed25519.PublicKey); !ok {
+			return errors.New("not an Ed25519 public key")
+		}
+		k.PublicKey = publicKey
+	}
+
+	return nil
+}
+
+func (h *JWTHelper) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || h.secret == nil {
+			return nil, errors.New("unexpected signing method")
+		}
+		if !h.secretVerifyUntil.IsZero() && !time.Now().Before(h.secretVerifyUntil) {
+			return nil, errors.New("key has expired")
+		}
+		return h.secret, nil
+	}
+
+	key, ok := h.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown key id")
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	if !key.IsActive(time.Now()) {
+		return nil, errors.New("key has expired")
+	}
+
+	switch publicKey := key.PublicKey.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return publicKey, nil
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+}
@@ -0,0 +1,321 @@
+package jwthelper
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret"
+
+func newRSAKey(t *testing.T, id string) *Key {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Key{ID: id, Method: jwt.SigningMethodRS256, PrivateKey: privateKey, PublicKey: &privateKey.PublicKey}
+}
+
+func newEdDSAKey(t *testing.T, id string) *Key {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Key{ID: id, Method: jwt.SigningMethodEdDSA, PrivateKey: privateKey, PublicKey: publicKey}
+}
+
+// verifyOnly returns a copy of the key as it is configured during rotation:
+// public part only.
+func verifyOnly(key *Key, verifyUntil time.Time) *Key {
+	return &Key{ID: key.ID, Method: key.Method, PublicKey: key.PublicKey, VerifyUntil: verifyUntil}
+}
+
+func newHelper(t *testing.T, secret string, secretVerifyUntil time.Time, signingKeyID string, keys ...*Key) *JWTHelper {
+	t.Helper()
+
+	h, err := NewJwtHelperWithKeys(secret, secretVerifyUntil, keys, signingKeyID)
+	if err != nil {
+		t.Fatalf("NewJwtHelperWithKeys: %v", err)
+	}
+	return h
+}
+
+func signToken(t *testing.T, h *JWTHelper) string {
+	t.Helper()
+
+	token, err := h.GenerateJWT("user-1", "user@example.com", "session-1", "access", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// signRaw signs claims with an arbitrary method, key and kid, as an attacker
+// or a misconfigured issuer would.
+func signRaw(t *testing.T, method jwt.SigningMethod, key interface{}, kid string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, &Claims{
+		UserID: "user-1",
+		Type:   "access",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey := newRSAKey(t, "2025-01")
+	newKey := newEdDSAKey(t, "2025-06")
+
+	before := newHelper(t, "", time.Time{}, oldKey.ID, oldKey)
+	after := newHelper(t, "", time.Time{}, newKey.ID, verifyOnly(oldKey, time.Now().Add(time.Hour)), newKey)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantKid string
+	}{
+		{"token signed before the rotation", signToken(t, before), oldKey.ID},
+		{"token signed after the rotation", signToken(t, after), newKey.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, _, err := jwt.NewParser().ParseUnverified(tt.token, &Claims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kid := parsed.Header["kid"]; kid != tt.wantKid {
+				t.Errorf("kid = %v, want %s", kid, tt.wantKid)
+			}
+
+			claims, err := after.ParseJWT(tt.token)
+			if err != nil {
+				t.Fatalf("ParseJWT: %v", err)
+			}
+			if claims.UserID != "user-1" {
+				t.Errorf("UserID = %q, want user-1", claims.UserID)
+			}
+		})
+	}
+}
+
+func TestParseJWTRejects(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa")
+	edKey := newEdDSAKey(t, "ed")
+	expired := newRSAKey(t, "expired")
+	stranger := newRSAKey(t, "rsa")
+
+	h := newHelper(t, "", time.Time{}, rsaKey.ID, rsaKey, edKey, verifyOnly(expired, time.Now().Add(-time.Minute)))
+
+	rsaPublicPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: mustMarshalPublicKey(t, rsaKey.PublicKey),
+	})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"unknown kid", signRaw(t, jwt.SigningMethodRS256, rsaKey.PrivateKey, "missing")},
+		{"kid of another key", signRaw(t, jwt.SigningMethodEdDSA, edKey.PrivateKey, rsaKey.ID)},
+		{"signed by a foreign key with a known kid", signRaw(t, jwt.SigningMethodRS256, stranger.PrivateKey, rsaKey.ID)},
+		{"HS256 keyed with the RSA public key", signRaw(t, jwt.SigningMethodHS256, rsaPublicPEM, rsaKey.ID)},
+		{"HS256 without kid and no secret", signRaw(t, jwt.SigningMethodHS256, []byte(testSecret), "")},
+		{"RS256 without kid", signRaw(t, jwt.SigningMethodRS256, rsaKey.PrivateKey, "")},
+		{"expired key", signRaw(t, jwt.SigningMethodRS256, expired.PrivateKey, expired.ID)},
+		{"alg none", signRaw(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, rsaKey.ID)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if claims, err := h.ParseJWT(tt.token); err == nil {
+				t.Errorf("ParseJWT accepted the token: %+v", claims)
+			}
+		})
+	}
+}
+
+func TestSecretVerifyUntil(t *testing.T) {
+	key := newEdDSAKey(t, "ed")
+	legacy, err := NewJwtHelper(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := signToken(t, legacy)
+
+	t.Run("accepted before the cutoff", func(t *testing.T) {
+		h := newHelper(t, testSecret, time.Now().Add(time.Hour), key.ID, key)
+		if _, err := h.ParseJWT(token); err != nil {
+			t.Errorf("ParseJWT: %v", err)
+		}
+	})
+
+	t.Run("rejected once the cutoff passes", func(t *testing.T) {
+		h := newHelper(t, testSecret, time.Now().Add(time.Hour), key.ID, key)
+		h.secretVerifyUntil = time.Now().Add(-time.Second)
+
+		if _, err := h.ParseJWT(token); err == nil {
+			t.Error("ParseJWT accepted an HS256 token after secret_verify_until")
+		}
+	})
+
+	t.Run("secret dropped when the cutoff has passed at start", func(t *testing.T) {
+		h := newHelper(t, testSecret, time.Now().Add(-time.Hour), key.ID, key)
+		if _, err := h.ParseJWT(token); err == nil {
+			t.Error("ParseJWT accepted an HS256 token after secret_verify_until")
+		}
+	})
+
+	t.Run("secret ignored without a cutoff", func(t *testing.T) {
+		h := newHelper(t, testSecret, time.Time{}, key.ID, key)
+		if _, err := h.ParseJWT(token); err == nil {
+			t.Error("ParseJWT accepted an HS256 token without secret_verify_until")
+		}
+	})
+
+	t.Run("new tokens are signed with the key", func(t *testing.T) {
+		h := newHelper(t, testSecret, time.Now().Add(time.Hour), key.ID, key)
+		parsed, _, err := jwt.NewParser().ParseUnverified(signToken(t, h), &Claims{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Method.Alg() != AlgorithmEdDSA || parsed.Header["kid"] != key.ID {
+			t.Errorf("token signed with %s, kid %v", parsed.Method.Alg(), parsed.Header["kid"])
+		}
+	})
+}
+
+func TestNewJwtHelperWithKeysErrors(t *testing.T) {
+	key := newRSAKey(t, "rsa")
+
+	tests := []struct {
+		name         string
+		keys         []*Key
+		signingKeyID string
+	}{
+		{"key without id", []*Key{key, {Method: jwt.SigningMethodRS256, PublicKey: key.PublicKey}}, key.ID},
+		{"duplicate key id", []*Key{key, newEdDSAKey(t, key.ID)}, key.ID},
+		{"unknown signing key", []*Key{key}, "missing"},
+		{"signing key without private key", []*Key{verifyOnly(key, time.Time{})}, key.ID},
+		{"expired signing key", []*Key{{
+			ID:          key.ID,
+			Method:      key.Method,
+			PrivateKey:  key.PrivateKey,
+			PublicKey:   key.PublicKey,
+			VerifyUntil: time.Now().Add(-time.Minute),
+		}}, key.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewJwtHelperWithKeys("", time.Time{}, tt.keys, tt.signingKeyID); err == nil {
+				t.Error("NewJwtHelperWithKeys succeeded")
+			}
+		})
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := newRSAKey(t, "rsa")
+	edKey := newEdDSAKey(t, "ed")
+
+	write := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	rsaPrivate := write("rsa.pem", "PRIVATE KEY", mustMarshalPrivateKey(t, rsaKey.PrivateKey))
+	rsaPublic := write("rsa.pub", "PUBLIC KEY", mustMarshalPublicKey(t, rsaKey.PublicKey))
+	edPrivate := write("ed.pem", "PRIVATE KEY", mustMarshalPrivateKey(t, edKey.PrivateKey))
+	edPublic := write("ed.pub", "PUBLIC KEY", mustMarshalPublicKey(t, edKey.PublicKey))
+
+	tests := []struct {
+		name        string
+		cfg         config.JWTKey
+		wantErr     bool
+		wantPrivate bool
+	}{
+		{"RS256 private key", config.JWTKey{ID: "a", Algorithm: AlgorithmRS256, PrivateKeyFile: rsaPrivate}, false, true},
+		{"RS256 public key", config.JWTKey{ID: "a", Algorithm: AlgorithmRS256, PublicKeyFile: rsaPublic}, false, false},
+		{"EdDSA private key", config.JWTKey{ID: "a", Algorithm: AlgorithmEdDSA, PrivateKeyFile: edPrivate}, false, true},
+		{"EdDSA public key", config.JWTKey{ID: "a", Algorithm: AlgorithmEdDSA, PublicKeyFile: edPublic}, false, false},
+		{"RSA key for EdDSA", config.JWTKey{ID: "a", Algorithm: AlgorithmEdDSA, PrivateKeyFile: rsaPrivate}, true, false},
+		{"RSA public key for EdDSA", config.JWTKey{ID: "a", Algorithm: AlgorithmEdDSA, PublicKeyFile: rsaPublic}, true, false},
+		{"Ed25519 key for RS256", config.JWTKey{ID: "a", Algorithm: AlgorithmRS256, PrivateKeyFile: edPrivate}, true, false},
+		{"unsupported algorithm", config.JWTKey{ID: "a", Algorithm: "HS256", PrivateKeyFile: rsaPrivate}, true, false},
+		{"no key file", config.JWTKey{ID: "a", Algorithm: AlgorithmRS256}, true, false},
+		{"missing key file", config.JWTKey{ID: "a", Algorithm: AlgorithmRS256, PublicKeyFile: filepath.Join(dir, "missing")}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := LoadKey(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("LoadKey succeeded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadKey: %v", err)
+			}
+			if key.PublicKey == nil {
+				t.Error("key has no public part")
+			}
+			if (key.PrivateKey != nil) != tt.wantPrivate {
+				t.Errorf("has private key = %v, want %v", key.PrivateKey != nil, tt.wantPrivate)
+			}
+		})
+	}
+}
+
+func mustMarshalPrivateKey(t *testing.T, key interface{}) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+func mustMarshalPublicKey(t *testing.T, key interface{}) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
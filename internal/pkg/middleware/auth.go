@@ -6,10 +6,11 @@ import (
 	"strings"
 
 	"github.com/RuLap/sportmates-api/internal/pkg/jwthelper"
+	"github.com/RuLap/sportmates-api/internal/pkg/redis"
 	"github.com/darahayes/go-boom"
 )
 
-func AuthMiddleware(jwtHelper *jwthelper.JWTHelper) func(http.Handler) http.Handler {
+func AuthMiddleware(jwtHelper *jwthelper.JWTHelper, redis *redis.Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -24,16 +25,48 @@ func AuthMiddleware(jwtHelper *jwthelper.JWTHelper) func(http.Handler) http.Hand
 				return
 			}
 
-			claims, err := jwtHelper.ParseJWT(tokenString)
+			claims, err := jwtHelper.ParseAccessToken(tokenString)
 			if err != nil {
 				boom.Unathorized(w, "Invalid token")
 				return
 			}
 
+			revoked, err := redis.IsTokenRevoked(r.Context(), claims.ID, claims.UserID, claims.IssuedAtTime())
+			if err != nil {
+				boom.Internal(w, "Failed to validate token")
+				return
+			}
+			if revoked {
+				boom.Unathorized(w, "Token has been revoked")
+				return
+			}
+
 			ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
 			ctx = context.WithValue(ctx, "user_email", claims.Email)
+			ctx = context.WithValue(ctx, "session_id", claims.SessionID)
+			ctx = context.WithValue(ctx, "user_roles", claims.Roles)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// WebSocketAuthMiddleware accepts the access token from the access_token query
+// parameter as well, since browsers cannot set headers on WebSocket handshakes.
+func WebSocketAuthMiddleware(jwtHelper *jwthelper.JWTHelper, redis *redis.Service) func(http.Handler) http.Handler {
+	auth := AuthMiddleware(jwtHelper, redis)
+
+	return func(next http.Handler) http.Handler {
+		authenticated := auth(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				if token := r.URL.Query().Get("access_token"); token != "" {
+					r.Header.Set("Authorization", "Bearer "+token)
+				}
+			}
+
+			authenticated.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/config"
+	"github.com/RuLap/sportmates-api/internal/pkg/jwthelper"
+	"github.com/RuLap/sportmates-api/internal/pkg/redis"
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestAuth(t *testing.T) (*jwthelper.JWTHelper, *redis.Service, http.Handler) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client, err := redis.NewClient(config.RedisConfig{Address: mr.Addr()}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("connect to miniredis: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	jwtHelper, err := jwthelper.NewJwtHelper("test-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redisService := redis.NewService(client)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return jwtHelper, redisService, AuthMiddleware(jwtHelper, redisService)(ok)
+}
+
+func authStatus(handler http.Handler, token string) int {
+	r := httptest.NewRequest("GET", "/users/me", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	jwtHelper, redisService, handler := newTestAuth(t)
+
+	pair, err := jwtHelper.GenerateTokenPair("user-1", "user@example.com", "session-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	denied, err := jwtHelper.GenerateTokenPair("user-2", "other@example.com", "session-2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := redisService.DenyToken(context.Background(), denied.AccessTokenID, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"access token", pair.AccessToken, http.StatusOK},
+		{"refresh token", pair.RefreshToken, http.StatusUnauthorized},
+		{"denied access token", denied.AccessToken, http.StatusUnauthorized},
+		{"malformed token", "not-a-token", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := authStatus(handler, tt.token); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/darahayes/go-boom"
+)
+
+// RequireRole lets the request through if the user has any of the roles. It
+// must run after AuthMiddleware, which puts the roles from the token into the
+// context.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Value("user_id").(string); !ok {
+				boom.Unathorized(w, "требуется аутентификация")
+				return
+			}
+
+			if !HasRole(r.Context(), roles...) {
+				boom.Forbidden(w, "недостаточно прав")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func HasRole(ctx context.Context, roles ...string) bool {
+	userRoles, _ := ctx.Value("user_roles").([]string)
+
+	for _, role := range roles {
+		if slices.Contains(userRoles, role) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -12,6 +12,13 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+const (
+	eventsQueue = "events"
+	// Account jobs get a queue of their own: consumers of the events queue
+	// acknowledge every message they are not interested in.
+	accountJobsQueue = "account_jobs"
+)
+
 type Client struct {
 	conn    *amqp.Connection
 	channel *amqp.Channel
@@ -30,18 +37,20 @@ func NewClient(cfg *config.RabbitMQConfig, log *slog.Logger) (*Client, error) {
 		return nil, fmt.Errorf("failed to open channel: %w", err)
 	}
 
-	_, err = channel.QueueDeclare(
-		"events",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		channel.Close()
-		conn.Close()
-		return nil, fmt.Errorf("failed to declare queue: %w", err)
+	for _, queue := range []string{eventsQueue, accountJobsQueue} {
+		_, err = channel.QueueDeclare(
+			queue,
+			true,
+			false,
+			false,
+			false,
+			nil,
+		)
+		if err != nil {
+			channel.Close()
+			conn.Close()
+			return nil, fmt.Errorf("failed to declare queue %s: %w", queue, err)
+		}
 	}
 
 	return &Client{
@@ -52,6 +61,10 @@ func NewClient(cfg *config.RabbitMQConfig, log *slog.Logger) (*Client, error) {
 }
 
 func (c *Client) PublishEvent(event events.Event) error {
+	return c.publish(eventsQueue, event)
+}
+
+func (c *Client) publish(queue string, event events.Event) error {
 	body, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("failed to marshal event: %w", err)
@@ -63,7 +76,7 @@ func (c *Client) PublishEvent(event events.Event) error {
 	err = c.channel.PublishWithContext(
 		ctx,
 		"",
-		"events",
+		queue,
 		false,
 		false,
 		amqp.Publishing{
@@ -86,8 +99,12 @@ func (c *Client) PublishEmailEvent(event events.EmailEvent) error {
 }
 
 func (c *Client) ConsumeEvents(ctx context.Context, handler func(eventType string, body []byte) error) error {
+	return c.consume(ctx, eventsQueue, handler)
+}
+
+func (c *Client) consume(ctx context.Context, queue string, handler func(eventType string, body []byte) error) error {
 	msgs, err := c.channel.Consume(
-		"events",
+		queue,
 		"",
 		false,
 		false,
@@ -99,16 +116,16 @@ func (c *Client) ConsumeEvents(ctx context.Context, handler func(eventType strin
 		return fmt.Errorf("failed to consume messages: %w", err)
 	}
 
-	c.log.Info("started consuming events")
+	c.log.Info("started consuming events", "queue", queue)
 
 	for {
 		select {
 		case <-ctx.Done():
-			c.log.Info("stopping events consumer")
+			c.log.Info("stopping events consumer", "queue", queue)
 			return nil
 		case msg, ok := <-msgs:
 			if !ok {
-				c.log.Warn("events channel closed")
+				c.log.Warn("events channel closed", "queue", queue)
 				return nil
 			}
 
@@ -137,6 +154,22 @@ func (c *Client) ConsumeEmailEvents(ctx context.Context, handler func(events.Ema
 		return handler(event)
 	})
 }
+
+func (c *Client) PublishAccountJob(job events.AccountJobEvent) error {
+	return c.publish(accountJobsQueue, job)
+}
+
+func (c *Client) ConsumeAccountJobs(ctx context.Context, handler func(events.AccountJobEvent) error) error {
+	return c.consume(ctx, accountJobsQueue, func(eventType string, body []byte) error {
+		var job events.AccountJobEvent
+		if err := json.Unmarshal(body, &job); err != nil {
+			return fmt.Errorf("failed to unmarshal account job: %w", err)
+		}
+
+		return handler(job)
+	})
+}
+
 func (c *Client) Close() error {
 	if c.channel != nil {
 		if err := c.channel.Close(); err != nil {
@@ -14,6 +14,10 @@ func NewService(mqClient *Client) *Service {
 	return &Service{mqClient: mqClient}
 }
 
+func (s *Service) Publish(event events.Event) error {
+	return s.mqClient.PublishEvent(event)
+}
+
 func (s *Service) PublishEmail(event events.EmailEvent) error {
 	return s.mqClient.PublishEvent(event)
 }
@@ -21,3 +25,11 @@ func (s *Service) PublishEmail(event events.EmailEvent) error {
 func (s *Service) ConsumeEmailEvents(ctx context.Context, handler func(events.EmailEvent) error) error {
 	return s.mqClient.ConsumeEmailEvents(ctx, handler)
 }
+
+func (s *Service) PublishAccountJob(job events.AccountJobEvent) error {
+	return s.mqClient.PublishAccountJob(job)
+}
+
+func (s *Service) ConsumeAccountJobs(ctx context.Context, handler func(events.AccountJobEvent) error) error {
+	return s.mqClient.ConsumeAccountJobs(ctx, handler)
+}
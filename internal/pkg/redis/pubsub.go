@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Message struct {
+	Channel string
+	Payload string
+}
+
+type Subscription struct {
+	pubsub *redis.PubSub
+}
+
+// Receive blocks until the next message arrives. The underlying connection is
+// re-established and resubscribed automatically after network errors.
+func (s *Subscription) Receive(ctx context.Context) (*Message, error) {
+	msg, err := s.pubsub.ReceiveMessage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Channel: msg.Channel,
+		Payload: msg.Payload,
+	}, nil
+}
+
+func (s *Subscription) Close() error {
+	return s.pubsub.Close()
+}
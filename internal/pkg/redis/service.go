@@ -5,8 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
+// ErrNil is returned when a requested key does not exist.
+var ErrNil = redis.Nil
+
 type Service struct {
 	client *Client
 }
@@ -52,21 +57,275 @@ func (s *Service) GetJSON(ctx context.Context, key string, dest interface{}) err
 	return json.Unmarshal([]byte(data), dest)
 }
 
-func (s *Service) StoreRefreshToken(ctx context.Context, userID, token string) error {
-	key := fmt.Sprintf("refresh_token:%s", userID)
-	return s.Set(ctx, key, token, 7*24*time.Hour)
+const sessionTTL = 7 * 24 * time.Hour
+
+// StoreSession saves the session and registers it in the user's session set.
+// Every write extends the session lifetime, so active devices stay signed in.
+func (s *Service) StoreSession(ctx context.Context, userID, sessionID string, session interface{}) error {
+	jsonData, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	userKey := fmt.Sprintf("user_sessions:%s", userID)
+
+	pipe := s.client.client.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("session:%s", sessionID), jsonData, sessionTTL)
+	pipe.SAdd(ctx, userKey, sessionID)
+	pipe.Expire(ctx, userKey, sessionTTL)
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *Service) GetSession(ctx context.Context, sessionID string, dest interface{}) error {
+	return s.GetJSON(ctx, fmt.Sprintf("session:%s", sessionID), dest)
 }
 
-func (s *Service) GetRefreshToken(ctx context.Context, userID string) (string, error) {
-	key := fmt.Sprintf("refresh_token:%s", userID)
-	return s.Get(ctx, key)
+// GetSessionIDs may return IDs of sessions that have already expired; callers
+// are expected to skip them and clean up with DeleteSession.
+func (s *Service) GetSessionIDs(ctx context.Context, userID string) ([]string, error) {
+	return s.client.client.SMembers(ctx, fmt.Sprintf("user_sessions:%s", userID)).Result()
 }
 
-func (s *Service) DeleteRefreshToken(ctx context.Context, userID string) error {
-	key := fmt.Sprintf("refresh_token:%s", userID)
+func (s *Service) DeleteSession(ctx context.Context, userID, sessionID string) error {
+	pipe := s.client.client.TxPipeline()
+	pipe.Del(ctx, fmt.Sprintf("session:%s", sessionID))
+	pipe.SRem(ctx, fmt.Sprintf("user_sessions:%s", userID), sessionID)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *Service) DeleteSessions(ctx context.Context, userID string) error {
+	userKey := fmt.Sprintf("user_sessions:%s", userID)
+
+	sessionIDs, err := s.GetSessionIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	pipe := s.client.client.TxPipeline()
+	for _, sessionID := range sessionIDs {
+		pipe.Del(ctx, fmt.Sprintf("session:%s", sessionID))
+	}
+	pipe.Del(ctx, userKey)
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// MarkRefreshTokenUsed records the refresh token as spent and reports whether
+// this was its first use. SETNX makes concurrent refreshes with the same token
+// race-free: only one of them can win.
+func (s *Service) MarkRefreshTokenUsed(ctx context.Context, tokenID, sessionID string, expiration time.Duration) (bool, error) {
+	if expiration < time.Second {
+		expiration = time.Second
+	}
+
+	key := fmt.Sprintf("refresh_used:%s", tokenID)
+	return s.client.client.SetNX(ctx, key, sessionID, expiration).Result()
+}
+
+// ReleaseRefreshToken undoes MarkRefreshTokenUsed when the refresh failed
+// for a reason other than reuse, so the client can retry with the token.
+func (s *Service) ReleaseRefreshToken(ctx context.Context, tokenID string) error {
+	key := fmt.Sprintf("refresh_used:%s", tokenID)
 	return s.Delete(ctx, key)
 }
 
+func (s *Service) DenyToken(ctx context.Context, tokenID string, expiration time.Duration) error {
+	if expiration <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("token_denylist:%s", tokenID)
+	return s.Set(ctx, key, 1, expiration)
+}
+
+// RevokeTokensIssuedBefore invalidates every token of the user issued before
+// the given moment. The watermark lives as long as the longest-lived token. It
+// is kept in milliseconds, so a sign-in right after the revocation is not
+// caught by it, while tokens of the same millisecond are still revoked.
+func (s *Service) RevokeTokensIssuedBefore(ctx context.Context, userID string, issuedBefore time.Time) error {
+	key := fmt.Sprintf("tokens_revoked_before:%s", userID)
+	return s.Set(ctx, key, issuedBefore.UnixMilli(), sessionTTL)
+}
+
+// IsTokenRevoked checks both the denylist and the per-user watermark in a
+// single round trip.
+func (s *Service) IsTokenRevoked(ctx context.Context, tokenID, userID string, issuedAt time.Time) (bool, error) {
+	pipe := s.client.client.Pipeline()
+	denied := pipe.Exists(ctx, fmt.Sprintf("token_denylist:%s", tokenID))
+	watermark := pipe.Get(ctx, fmt.Sprintf("tokens_revoked_before:%s", userID))
+
+	_, err := pipe.Exec(ctx)
+	if err != nil && err != redis.Nil {
+		return false, err
+	}
+
+	if denied.Val() > 0 {
+		return true, nil
+	}
+
+	revokedBefore, err := watermark.Int64()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return issuedAt.UnixMilli() <= revokedBefore, nil
+}
+
+func (s *Service) StoreLoginChallenge(ctx context.Context, token string, value interface{}, expiration time.Duration) error {
+	key := fmt.Sprintf("login_challenge:%s", token)
+	return s.SetJSON(ctx, key, value, expiration)
+}
+
+func (s *Service) GetLoginChallenge(ctx context.Context, token string, dest interface{}) error {
+	key := fmt.Sprintf("login_challenge:%s", token)
+	return s.GetJSON(ctx, key, dest)
+}
+
+// CountLoginChallengeAttempt returns how many codes have been tried for the
+// challenge, including this one.
+func (s *Service) CountLoginChallengeAttempt(ctx context.Context, token string, expiration time.Duration) (int64, error) {
+	key := fmt.Sprintf("login_challenge_attempts:%s", token)
+
+	pipe := s.client.client.TxPipeline()
+	attempts := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, expiration)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return attempts.Val(), nil
+}
+
+func (s *Service) DeleteLoginChallenge(ctx context.Context, token string) error {
+	return s.client.client.Del(ctx,
+		fmt.Sprintf("login_challenge:%s", token),
+		fmt.Sprintf("login_challenge_attempts:%s", token),
+	).Err()
+}
+
+// MarkTOTPStepUsed reports whether the code for this time step is used for the
+// first time, so an intercepted code cannot be replayed within its window.
+func (s *Service) MarkTOTPStepUsed(ctx context.Context, userID string, step int64) (bool, error) {
+	key := fmt.Sprintf("totp_used:%s:%d", userID, step)
+	return s.client.client.SetNX(ctx, key, 1, 5*time.Minute).Result()
+}
+
+// GetLoginLock returns how long logins for the subject stay blocked, or zero
+// if they are not blocked.
+func (s *Service) GetLoginLock(ctx context.Context, subject string) (time.Duration, error) {
+	ttl, err := s.client.client.PTTL(ctx, fmt.Sprintf("login_lock:%s", subject)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (s *Service) LockLogin(ctx context.Context, subject string, duration time.Duration) error {
+	return s.Set(ctx, fmt.Sprintf("login_lock:%s", subject), 1, duration)
+}
+
+// RecordLoginFailure returns the number of failures for the subject within
+// the window, including this one. Every failure extends the window.
+func (s *Service) RecordLoginFailure(ctx context.Context, subject string, window time.Duration) (int64, error) {
+	key := fmt.Sprintf("login_failures:%s", subject)
+
+	pipe := s.client.client.TxPipeline()
+	failures := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, window)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return failures.Val(), nil
+}
+
+func (s *Service) ResetLoginFailures(ctx context.Context, subject string) error {
+	return s.client.client.Del(ctx,
+		fmt.Sprintf("login_failures:%s", subject),
+		fmt.Sprintf("login_lock:%s", subject),
+	).Err()
+}
+
+// StorePhoneCode replaces the pending code for the phone and resets its
+// attempt counter.
+func (s *Service) StorePhoneCode(ctx context.Context, phone string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	pipe := s.client.client.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("phone_code:%s", phone), data, expiration)
+	pipe.Del(ctx, fmt.Sprintf("phone_code_attempts:%s", phone))
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *Service) GetPhoneCode(ctx context.Context, phone string, dest interface{}) error {
+	key := fmt.Sprintf("phone_code:%s", phone)
+	return s.GetJSON(ctx, key, dest)
+}
+
+// CountPhoneCodeAttempt returns how many codes have been tried for the
+// phone, including this one.
+func (s *Service) CountPhoneCodeAttempt(ctx context.Context, phone string, expiration time.Duration) (int64, error) {
+	key := fmt.Sprintf("phone_code_attempts:%s", phone)
+
+	pipe := s.client.client.TxPipeline()
+	attempts := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, expiration)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return attempts.Val(), nil
+}
+
+func (s *Service) DeletePhoneCode(ctx context.Context, phone string) error {
+	return s.client.client.Del(ctx,
+		fmt.Sprintf("phone_code:%s", phone),
+		fmt.Sprintf("phone_code_attempts:%s", phone),
+	).Err()
+}
+
+// StartPhoneCodeCooldown reports whether a new code may be sent to the phone
+// and, if so, blocks the next one for the cooldown.
+func (s *Service) StartPhoneCodeCooldown(ctx context.Context, phone string, cooldown time.Duration) (bool, error) {
+	key := fmt.Sprintf("phone_code_cooldown:%s", phone)
+	return s.client.client.SetNX(ctx, key, 1, cooldown).Result()
+}
+
+// CountPhoneCodeSend returns how many codes were sent to the phone within
+// the window, including this one. The window starts with the first code.
+func (s *Service) CountPhoneCodeSend(ctx context.Context, phone string, window time.Duration) (int64, error) {
+	key := fmt.Sprintf("phone_code_sends:%s", phone)
+
+	pipe := s.client.client.TxPipeline()
+	sends := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, window)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return sends.Val(), nil
+}
+
 func (s *Service) StoreEmailConfirmation(ctx context.Context, userID, email, token string) error {
 	userKey := fmt.Sprintf("email_confirm:user:%s", userID)
 	tokenKey := fmt.Sprintf("email_confirm:token:%s", token)
@@ -84,18 +343,199 @@ func (s *Service) GetEmailConfirmationUserID(ctx context.Context, token string)
 	return s.Get(ctx, key)
 }
 
+// StoreEmailChange issues a confirmation token like StoreEmailConfirmation
+// and keeps the new address until the token is confirmed. Only the latest
+// link is valid: the previous token is removed.
+func (s *Service) StoreEmailChange(ctx context.Context, userID, email, token string) error {
+	userKey := fmt.Sprintf("email_confirm:user:%s", userID)
+	tokenKey := fmt.Sprintf("email_confirm:token:%s", token)
+	changeKey := fmt.Sprintf("email_confirm:change:%s", token)
+
+	previous, err := s.Get(ctx, userKey)
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	pipe := s.client.client.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, fmt.Sprintf("email_confirm:token:%s", previous))
+		pipe.Del(ctx, fmt.Sprintf("email_confirm:change:%s", previous))
+	}
+	pipe.Set(ctx, userKey, token, 24*time.Hour)
+	pipe.Set(ctx, tokenKey, userID, 24*time.Hour)
+	pipe.Set(ctx, changeKey, email, 24*time.Hour)
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *Service) GetEmailChange(ctx context.Context, token string) (string, error) {
+	key := fmt.Sprintf("email_confirm:change:%s", token)
+	return s.Get(ctx, key)
+}
+
+// DeleteEmailChange drops the pending email change of the user, if any. A
+// confirmation of the address the account signed up with is kept.
+func (s *Service) DeleteEmailChange(ctx context.Context, userID string) error {
+	token, err := s.Get(ctx, fmt.Sprintf("email_confirm:user:%s", userID))
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	pending, err := s.Exists(ctx, fmt.Sprintf("email_confirm:change:%s", token))
+	if err != nil || !pending {
+		return err
+	}
+
+	return s.DeleteEmailConfirmation(ctx, userID, token)
+}
+
 func (s *Service) DeleteEmailConfirmation(ctx context.Context, userID, token string) error {
 	userKey := fmt.Sprintf("email_confirm:user:%s", userID)
 	tokenKey := fmt.Sprintf("email_confirm:token:%s", token)
+	changeKey := fmt.Sprintf("email_confirm:change:%s", token)
 
 	pipe := s.client.client.TxPipeline()
 	pipe.Del(ctx, userKey)
 	pipe.Del(ctx, tokenKey)
+	pipe.Del(ctx, changeKey)
 
 	_, err := pipe.Exec(ctx)
 	return err
 }
 
+func (s *Service) StorePasswordReset(ctx context.Context, userID, token string) error {
+	userKey := fmt.Sprintf("password_reset:user:%s", userID)
+	tokenKey := fmt.Sprintf("password_reset:token:%s", token)
+
+	previous, err := s.Get(ctx, userKey)
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	pipe := s.client.client.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, fmt.Sprintf("password_reset:token:%s", previous))
+	}
+	pipe.Set(ctx, userKey, token, time.Hour)
+	pipe.Set(ctx, tokenKey, userID, time.Hour)
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// ConsumePasswordReset returns the user the token was issued to and removes
+// it, so a token can be used only once even under concurrent requests.
+func (s *Service) ConsumePasswordReset(ctx context.Context, token string) (string, error) {
+	tokenKey := fmt.Sprintf("password_reset:token:%s", token)
+
+	userID, err := s.client.client.GetDel(ctx, tokenKey).Result()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.Delete(ctx, fmt.Sprintf("password_reset:user:%s", userID)); err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// StoreMagicLink keeps a single pending sign-in link per user: issuing a new
+// link invalidates the previous one.
+func (s *Service) StoreMagicLink(ctx context.Context, userID, token string, value interface{}, expiration time.Duration) error {
+	userKey := fmt.Sprintf("magic_link:user:%s", userID)
+	tokenKey := fmt.Sprintf("magic_link:token:%s", token)
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	previous, err := s.Get(ctx, userKey)
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	pipe := s.client.client.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, fmt.Sprintf("magic_link:token:%s", previous))
+	}
+	pipe.Set(ctx, userKey, token, expiration)
+	pipe.Set(ctx, tokenKey, data, expiration)
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// ConsumeMagicLink reads and removes the link in one step, so it can be used
+// only once even under concurrent requests.
+func (s *Service) ConsumeMagicLink(ctx context.Context, token string, dest interface{}) error {
+	key := fmt.Sprintf("magic_link:token:%s", token)
+
+	data, err := s.client.client.GetDel(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(data), dest)
+}
+
+func (s *Service) StoreOAuthState(ctx context.Context, state string, value interface{}) error {
+	key := fmt.Sprintf("oauth_state:%s", state)
+	return s.SetJSON(ctx, key, value, 10*time.Minute)
+}
+
+func (s *Service) ConsumeOAuthState(ctx context.Context, state string, dest interface{}) error {
+	key := fmt.Sprintf("oauth_state:%s", state)
+
+	data, err := s.client.client.GetDel(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(data), dest)
+}
+
+func (s *Service) StoreEmailRevert(ctx context.Context, token string, value interface{}, expiration time.Duration) error {
+	key := fmt.Sprintf("email_revert:%s", token)
+	return s.SetJSON(ctx, key, value, expiration)
+}
+
+func (s *Service) ConsumeEmailRevert(ctx context.Context, token string, dest interface{}) error {
+	key := fmt.Sprintf("email_revert:%s", token)
+
+	data, err := s.client.client.GetDel(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(data), dest)
+}
+
+// StoreAccountJob keeps the latest job of each kind per user, so the client
+// can poll its status and fetch the result.
+func (s *Service) StoreAccountJob(ctx context.Context, userID, kind string, job interface{}, expiration time.Duration) error {
+	key := fmt.Sprintf("account_job:%s:%s", userID, kind)
+	return s.SetJSON(ctx, key, job, expiration)
+}
+
+func (s *Service) GetAccountJob(ctx context.Context, userID, kind string, dest interface{}) error {
+	key := fmt.Sprintf("account_job:%s:%s", userID, kind)
+	return s.GetJSON(ctx, key, dest)
+}
+
+func (s *Service) Publish(ctx context.Context, channel string, message interface{}) error {
+	return s.client.client.Publish(ctx, channel, message).Err()
+}
+
+func (s *Service) PSubscribe(ctx context.Context, patterns ...string) *Subscription {
+	return &Subscription{pubsub: s.client.client.PSubscribe(ctx, patterns...)}
+}
+
 func (s *Service) HealthCheck(ctx context.Context) error {
 	return s.client.HealthCheck(ctx)
 }
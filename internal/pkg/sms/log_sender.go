@@ -0,0 +1,21 @@
+package sms
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogSender writes messages to the log instead of sending them. It is meant
+// for development, where codes can be read from the log.
+type LogSender struct {
+	log *slog.Logger
+}
+
+func NewLogSender(log *slog.Logger) *LogSender {
+	return &LogSender{log: log}
+}
+
+func (s *LogSender) Send(ctx context.Context, phone, text string) error {
+	s.log.Info("sms", "phone", phone, "text", text)
+	return nil
+}
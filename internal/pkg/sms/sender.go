@@ -0,0 +1,38 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/config"
+)
+
+const LogProvider = "log"
+
+var ErrNoProvider = errors.New("sms provider is not configured")
+
+// Sender delivers a text message to a phone number in E.164 format.
+type Sender interface {
+	Send(ctx context.Context, phone, text string) error
+}
+
+// New returns the sender for the configured provider. Real providers are
+// added here next to the log sender. The log sender writes sign-in codes to
+// the log, so it has to be chosen explicitly and only runs in the local
+// environment.
+func New(cfg *config.SMSConfig, env string, log *slog.Logger) (Sender, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, ErrNoProvider
+	case LogProvider:
+		if env != config.LocalEnv {
+			return nil, fmt.Errorf("sms provider %q is only allowed in the %s environment", LogProvider, config.LocalEnv)
+		}
+		log.Warn("sms are not delivered, messages are written to the log")
+		return NewLogSender(log), nil
+	default:
+		return nil, fmt.Errorf("unknown sms provider: %s", cfg.Provider)
+	}
+}
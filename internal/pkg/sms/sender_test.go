@@ -0,0 +1,47 @@
+package sms
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/RuLap/sportmates-api/internal/pkg/config"
+)
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name     string
+		provider string
+		env      string
+		wantErr  bool
+	}{
+		{"log sender locally", LogProvider, config.LocalEnv, false},
+		{"log sender in production", LogProvider, "production", true},
+		{"log sender without env", LogProvider, "", true},
+		{"no provider locally", "", config.LocalEnv, true},
+		{"no provider in production", "", "production", true},
+		{"unknown provider", "carrier-pigeon", config.LocalEnv, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, err := New(&config.SMSConfig{Provider: tt.provider}, tt.env, log)
+			if tt.wantErr {
+				if err == nil || sender != nil {
+					t.Fatalf("New() = %v, %v, want an error and no sender", sender, err)
+				}
+				return
+			}
+			if err != nil || sender == nil {
+				t.Fatalf("New() = %v, %v, want a sender", sender, err)
+			}
+		})
+	}
+
+	if _, err := New(&config.SMSConfig{}, "production", log); !errors.Is(err, ErrNoProvider) {
+		t.Errorf("New() without provider: error = %v, want %v", err, ErrNoProvider)
+	}
+}
@@ -0,0 +1,105 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed from RFC 6238 Appendix B, "12345678901234567890".
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC lists 8-digit codes; with 6 digits the code is their last 6 digits.
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestGenerateCodeRFC6238(t *testing.T) {
+	for _, tt := range rfcVectors {
+		got, err := GenerateCode(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.code {
+			t.Errorf("GenerateCode(%d) = %s, want %s", tt.unix, got, tt.code)
+		}
+	}
+}
+
+func TestValidateRFC6238(t *testing.T) {
+	for _, tt := range rfcVectors {
+		now := time.Unix(tt.unix, 0)
+		step, ok := Validate(rfcSecret, tt.code, now, 0)
+		if !ok {
+			t.Errorf("Validate(%d) rejected %s", tt.unix, tt.code)
+			continue
+		}
+		if step != Step(now) {
+			t.Errorf("Validate(%d) step = %d, want %d", tt.unix, step, Step(now))
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	issued := time.Unix(1234567890, 0)
+	code, err := GenerateCode(rfcSecret, issued)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		skew int
+		ok   bool
+	}{
+		{"same step", issued, 1, true},
+		{"one step later", issued.Add(Period), 1, true},
+		{"one step earlier", issued.Add(-Period), 1, true},
+		{"two steps later", issued.Add(2 * Period), 1, false},
+		{"two steps earlier", issued.Add(-2 * Period), 1, false},
+		{"one step later without skew", issued.Add(Period), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, code, tt.now, tt.skew)
+			if ok != tt.ok {
+				t.Fatalf("Validate ok = %v, want %v", ok, tt.ok)
+			}
+			// The matched step is the one the code was issued for, so a
+			// replay is detected however far the clock has moved.
+			if ok && step != Step(issued) {
+				t.Errorf("Validate step = %d, want %d", step, Step(issued))
+			}
+		})
+	}
+}
+
+func TestValidateRejectsMalformedCodes(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	for _, code := range []string{"", "28708", "2870820", "abcdef"} {
+		if _, ok := Validate(rfcSecret, code, now, 1); ok {
+			t.Errorf("Validate accepted %q", code)
+		}
+	}
+
+	if _, ok := Validate("not base32!", "287082", now, 1); ok {
+		t.Error("Validate accepted a code for an invalid secret")
+	}
+}
+
+func TestValidateIgnoresSecretFormatting(t *testing.T) {
+	secret := "gezd gnbv gy3t qojq gezd gnbv gy3t qojq"
+	if _, ok := Validate(secret, "287082", time.Unix(59, 0), 0); !ok {
+		t.Error("Validate rejected a lowercase secret with spaces")
+	}
+}
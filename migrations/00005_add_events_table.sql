@@ -1,6 +1,19 @@
 -- +goose Up
 -- +goose StatementBegin
-CREATE TABLE events (
+-- 00003 already creates events and event_participants, so the users table it
+-- was meant to add is created here instead.
+CREATE TABLE users (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email TEXT NOT NULL,
+    email_confirmed BOOLEAN NOT NULL DEFAULT FALSE,
+    password TEXT NULL,
+    provider TEXT NOT NULL DEFAULT 'local' CHECK (provider IN ('local', 'google')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (email, provider)
+);
+
+CREATE TABLE IF NOT EXISTS events (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     title TEXT NOT NULL,
     "description" TEXT NULL,
@@ -14,7 +27,7 @@ CREATE TABLE events (
     created_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 
-CREATE TABLE event_participants (
+CREATE TABLE IF NOT EXISTS event_participants (
     event_id UUID NOT NULL,
     user_id UUID NOT NULL,
     PRIMARY KEY (event_id, user_id)
@@ -23,6 +36,6 @@ CREATE TABLE event_participants (
 
 -- +goose Down
 -- +goose StatementBegin
-DROP TABLE event_participants;
-DROP TABLE events;
+-- events and event_participants are dropped by the Down of 00003.
+DROP TABLE users;
 -- +goose StatementEnd
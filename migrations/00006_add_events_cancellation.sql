@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events
+    ADD COLUMN canceled_at TIMESTAMPTZ NULL,
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX idx_events_creator_id ON events (creator_id);
+CREATE INDEX idx_event_participants_user_id ON event_participants (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_event_participants_user_id;
+DROP INDEX IF EXISTS idx_events_creator_id;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS canceled_at;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events
+    ADD COLUMN max_participants INT NULL CHECK (max_participants > 0);
+
+ALTER TABLE event_participants
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'confirmed' CHECK (status IN ('confirmed', 'waitlisted')),
+    ADD COLUMN joined_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX idx_event_participants_waitlist ON event_participants (event_id, status, joined_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_event_participants_waitlist;
+
+ALTER TABLE event_participants
+    DROP COLUMN IF EXISTS joined_at,
+    DROP COLUMN IF EXISTS status;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS max_participants;
+-- +goose StatementEnd
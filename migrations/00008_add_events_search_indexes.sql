@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_events_start_date ON events (start_date, id) WHERE canceled_at IS NULL;
+CREATE INDEX idx_events_city_start_date ON events (city_id, start_date, id) WHERE canceled_at IS NULL;
+CREATE INDEX idx_events_sport_start_date ON events (sport_id, start_date, id) WHERE canceled_at IS NULL;
+CREATE INDEX idx_events_search_text ON events
+    USING GIN ((title || ' ' || coalesce("description", '')) gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_events_search_text;
+DROP INDEX IF EXISTS idx_events_sport_start_date;
+DROP INDEX IF EXISTS idx_events_city_start_date;
+DROP INDEX IF EXISTS idx_events_start_date;
+-- +goose StatementEnd
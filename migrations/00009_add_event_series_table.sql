@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE event_series (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    creator_id UUID NOT NULL,
+    frequency TEXT NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly')),
+    "interval" INT NOT NULL DEFAULT 1 CHECK ("interval" > 0),
+    weekdays TEXT[] NOT NULL DEFAULT '{}',
+    "start_date" TIMESTAMPTZ NOT NULL,
+    until TIMESTAMPTZ NULL,
+    "count" INT NULL CHECK ("count" > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE events
+    ADD COLUMN series_id UUID NULL REFERENCES event_series(id) ON DELETE SET NULL,
+    ADD COLUMN series_detached BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_events_series_id ON events (series_id, start_date) WHERE series_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_events_series_id;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS series_detached,
+    DROP COLUMN IF EXISTS series_id;
+
+DROP TABLE IF EXISTS event_series;
+-- +goose StatementEnd
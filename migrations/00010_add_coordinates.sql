@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events
+    ADD COLUMN latitude DOUBLE PRECISION NULL CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN longitude DOUBLE PRECISION NULL CHECK (longitude BETWEEN -180 AND 180),
+    ADD CONSTRAINT events_coordinates_pair CHECK ((latitude IS NULL) = (longitude IS NULL));
+
+ALTER TABLE cities
+    ADD COLUMN latitude DOUBLE PRECISION NULL CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN longitude DOUBLE PRECISION NULL CHECK (longitude BETWEEN -180 AND 180);
+
+CREATE INDEX idx_events_coordinates ON events (latitude, longitude)
+    WHERE latitude IS NOT NULL AND canceled_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_events_coordinates;
+
+ALTER TABLE cities
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
+
+ALTER TABLE events
+    DROP CONSTRAINT IF EXISTS events_coordinates_pair,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
+-- +goose StatementEnd
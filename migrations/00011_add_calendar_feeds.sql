@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events
+    ADD COLUMN "sequence" INT NOT NULL DEFAULT 0;
+
+CREATE TABLE calendar_tokens (
+    user_id UUID PRIMARY KEY,
+    token TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS calendar_tokens;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS "sequence";
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE event_participants
+    ADD COLUMN attended BOOLEAN NULL,
+    ADD COLUMN checked_in_at TIMESTAMPTZ NULL;
+
+ALTER TABLE events
+    ADD COLUMN min_reliability DOUBLE PRECISION NULL CHECK (min_reliability BETWEEN 0 AND 1),
+    ADD COLUMN check_in_code TEXT NULL,
+    ADD COLUMN check_in_token TEXT NULL,
+    ADD COLUMN check_in_expires_at TIMESTAMPTZ NULL;
+
+CREATE INDEX idx_event_participants_attended ON event_participants (user_id) WHERE attended IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_event_participants_attended;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS check_in_expires_at,
+    DROP COLUMN IF EXISTS check_in_token,
+    DROP COLUMN IF EXISTS check_in_code,
+    DROP COLUMN IF EXISTS min_reliability;
+
+ALTER TABLE event_participants
+    DROP COLUMN IF EXISTS checked_in_at,
+    DROP COLUMN IF EXISTS attended;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN provider_subject TEXT NULL;
+
+CREATE UNIQUE INDEX idx_users_provider_subject ON users (provider, provider_subject) WHERE provider_subject IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_provider_subject;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS provider_subject;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE roles (
+    "name" TEXT PRIMARY KEY,
+    description TEXT NOT NULL
+);
+
+INSERT INTO roles ("name", description) VALUES
+('admin', 'Полный доступ к администрированию'),
+('moderator', 'Модерация пользователей, событий и справочников');
+
+-- The first admin has to be granted directly in the database:
+-- INSERT INTO user_roles (user_id, role) VALUES ('<user id>', 'admin');
+CREATE TABLE user_roles (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL REFERENCES roles("name") ON DELETE CASCADE,
+    granted_by UUID NULL REFERENCES users(id) ON DELETE SET NULL,
+    granted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, role)
+);
+
+ALTER TABLE users
+    ADD COLUMN banned_at TIMESTAMPTZ NULL,
+    ADD COLUMN ban_reason TEXT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS ban_reason,
+    DROP COLUMN IF EXISTS banned_at;
+
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS roles;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Seeded cities were inserted with explicit ids, so the sequence has to catch
+-- up before new cities can be added.
+SELECT setval('cities_id_seq', (SELECT COALESCE(MAX(id), 1) FROM cities));
+-- +goose StatementEnd
+
+-- +goose Down
+-- The sequence is left where it is: ids handed out by it stay taken.
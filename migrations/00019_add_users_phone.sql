@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ALTER COLUMN email DROP NOT NULL,
+    ADD COLUMN phone TEXT NULL,
+    ADD COLUMN phone_confirmed BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE users DROP CONSTRAINT users_provider_check;
+ALTER TABLE users ADD CONSTRAINT users_provider_check CHECK (provider IN ('local', 'google', 'phone'));
+
+-- Accounts signed up by phone have no email.
+ALTER TABLE users ADD CONSTRAINT users_email_or_phone_check CHECK (email IS NOT NULL OR phone IS NOT NULL);
+
+CREATE UNIQUE INDEX idx_users_phone ON users (phone) WHERE phone IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Phone accounts cannot be represented without the phone column. Refuse to
+-- roll back rather than drop them; remove or migrate them by hand first.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE provider = 'phone' OR email IS NULL) THEN
+        RAISE EXCEPTION 'users signed up by phone exist, remove them before rolling back 00019';
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_users_phone;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_or_phone_check;
+ALTER TABLE users DROP CONSTRAINT users_provider_check;
+ALTER TABLE users ADD CONSTRAINT users_provider_check CHECK (provider IN ('local', 'google'));
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS phone_confirmed,
+    DROP COLUMN IF EXISTS phone,
+    ALTER COLUMN email SET NOT NULL;
+-- +goose StatementEnd
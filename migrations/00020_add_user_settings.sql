@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A row is created on the first change; until then the defaults below apply.
+CREATE TABLE user_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    "language" TEXT NOT NULL DEFAULT 'ru' CHECK ("language" IN ('ru', 'en')),
+    timezone TEXT NOT NULL DEFAULT 'Europe/Moscow',
+    profile_visibility TEXT NOT NULL DEFAULT 'everyone' CHECK (profile_visibility IN ('everyone', 'participants', 'nobody')),
+    show_birth_date BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_event_updates BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_event_reminders BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_chat_messages BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_settings;
+-- +goose StatementEnd